@@ -16,6 +16,7 @@ import (
 	"anarchy-core/internal/repository/postgres"
 	"anarchy-core/internal/service"
 	"anarchy-core/internal/util"
+	"anarchy-core/migration"
 
 	"github.com/labstack/echo/v4"
 )
@@ -31,45 +32,139 @@ func main() {
 		os.Exit(1)
 	}
 
-	// 3. Initialize Database Connection
-	db, err := database.InitPostgresDB(cfg.DatabaseURL, logger)
-	if err != nil {
-		logger.Error("Failed to initialize database: %v", err)
-		os.Exit(1)
-	}
-	defer func() {
-		if err := db.Close(); err != nil {
-			logger.Error("Failed to close database connection: %v", err)
+	// 3. Initialize Storage and Repositories
+	var repos *repositories
+	switch cfg.Storage {
+	case config.StorageMemory:
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			logger.Error("Migrations are only available with STORAGE=%s", config.StoragePostgres)
+			os.Exit(1)
+		}
+		logger.Info("Using in-memory storage, all data will be lost on shutdown")
+		repos = newMemoryRepositories()
+	default:
+		db, err := database.InitPostgresDB(cfg.DatabaseURL, logger)
+		if err != nil {
+			logger.Error("Failed to initialize database: %v", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := db.Close(); err != nil {
+				logger.Error("Failed to close database connection: %v", err)
+			}
+			logger.Info("Database connection closed.")
+		}()
+
+		migrator, err := database.NewMigrator(db, migration.FS, logger)
+		if err != nil {
+			logger.Error("Failed to load migrations: %v", err)
+			os.Exit(1)
 		}
-		logger.Info("Database connection closed.")
-	}()
 
-	// 4. Initialize Repositories
-	userRepo := postgres.NewUserRepositoryPostgres(db)
-	playerMovementRepo := postgres.NewPlayerMovementRepositoryPostgres(db)
+		// `app migrate up|down|status` manages the schema and exits
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			if err := runMigrate(os.Args[2:], migrator, logger); err != nil {
+				logger.Error("Migration command failed: %v", err)
+				os.Exit(1)
+			}
+			return
+		}
 
-	// 5. Initialize JWT Manager
-	jwtManager := auth.NewJWTManager(cfg.JWTSecretKey)
+		// Refuse to serve on an outdated schema
+		pending, err := migrator.Pending()
+		if err != nil {
+			logger.Error("Failed to check migration status: %v", err)
+			os.Exit(1)
+		}
+		if len(pending) > 0 {
+			logger.Error("Database schema is behind by %d migration(s), run `app migrate up` first", len(pending))
+			os.Exit(1)
+		}
 
-	// 6. Initialize Services
-	authService := service.NewAuthService(userRepo, jwtManager, logger)
-	playerService := service.NewPlayerService(playerMovementRepo, logger)
-	websocketService := service.NewWebSocketService(logger)
+		repos = newPostgresRepositories(db)
+	}
 
-	// Start WebSocket service in a goroutine
+	// 4. Initialize JWT Manager
+	jwtManager := auth.NewJWTManager(cfg.JWTSecretKey, cfg.AccessTokenTTL)
+
+	// 5. Initialize Services
+	roleService := service.NewRoleService(repos.users, repos.roles, cfg.AdminUsers, logger)
+	authService := service.NewAuthService(repos.users, repos.refreshTokens, roleService, jwtManager, cfg.RefreshTokenTTL, logger)
+	playerService := service.NewPlayerService(repos.playerMovement, logger)
+	websocketService := service.NewWebSocketService(cfg.ViewDistance, cfg.SessionGracePeriod, service.SessionPolicy(cfg.SessionPolicy), logger)
+
+	terrainService := service.NewTerrainService(repos.world, cfg.TerrainCellSize, cfg.ChunkSize, cfg.ChunkRadius, cfg.SpawnPoint, logger)
+	if err := terrainService.Load(); err != nil {
+		logger.Error("Failed to load terrain: %v", err)
+		os.Exit(1)
+	}
+	aiSystem := service.NewAISystem(repos.entities, repos.entityLists, terrainService, logger)
+	if err := aiSystem.Load(); err != nil {
+		logger.Error("Failed to load entities: %v", err)
+		os.Exit(1)
+	}
+	spawner := service.NewSpawnerService(repos.entities, repos.entityLists, terrainService, logger)
+	if err := spawner.Load(time.Now()); err != nil {
+		logger.Error("Failed to load spawn rules: %v", err)
+		os.Exit(1)
+	}
+	combatService := service.NewCombatService(repos.playerStats, websocketService, terrainService, aiSystem, spawner, service.CombatConfig{
+		MaxHealth:      cfg.PlayerMaxHealth,
+		Damage:         cfg.PlayerDamage,
+		AttackRange:    cfg.PlayerAttackRange,
+		AttackCooldown: cfg.PlayerAttackCooldown,
+		RespawnDelay:   cfg.PlayerRespawnDelay,
+	}, logger)
+	inventoryService := service.NewInventoryService(repos.inventory, repos.items, repos.itemLists, websocketService, cfg.InventorySlots, cfg.InventoryStackSize, logger)
+	pickupService := service.NewPickupService(inventoryService, aiSystem, spawner, combatService, terrainService, websocketService, repos.itemLists, repos.entityLists, cfg.PickupRange, logger)
+	containerService := service.NewContainerService(inventoryService, aiSystem, combatService, websocketService, cfg.ContainerRange, logger)
+	catalogService := service.NewCatalogService(repos.objectLists, repos.objects, repos.itemLists, repos.entityLists, logger)
+	movementValidator := service.NewMovementValidator(cfg.MaxPlayerSpeed, cfg.SuspicionKickThreshold)
+	gameLoopService := service.NewGameLoopService(playerService, websocketService, terrainService, movementValidator, aiSystem, spawner, combatService, pickupService, containerService, cfg.TickRate, logger)
+
+	contentService := service.NewContentService(repos.objectLists, repos.objects, repos.itemLists, repos.entityLists, repos.auditLog, catalogService, gameLoopService, logger)
+
+	// Servers sharing the database announce content changes to each other
+	if cfg.Storage == config.StoragePostgres {
+		listener, err := database.Listen(cfg.DatabaseURL, postgres.ContentChangedChannel, logger)
+		if err != nil {
+			logger.Error("Failed to listen for content changes: %v", err)
+			os.Exit(1)
+		}
+		defer listener.Close()
+		go listener.Run(contentService.Reload)
+	}
+
+	// Start WebSocket service and game loop in goroutines
 	go websocketService.Run()
+	go gameLoopService.Run()
 
-	// 7. Initialize Handlers
+	// 6. Initialize Handlers
 	authHandler := handler.NewAuthHandler(authService, logger)
-	playerMovementHandler := handler.NewPlayerMovementHandler(playerService, websocketService, jwtManager, logger)
-
-	// 8. Initialize Echo Web Server
+	adminHandler := handler.NewAdminHandler(movementValidator, roleService, logger)
+	messageRegistry := handler.NewMessageRegistry()
+	playerMovementHandler := handler.NewPlayerMovementHandler(playerService, websocketService, gameLoopService, terrainService, combatService, jwtManager, messageRegistry, logger)
+	playerMovementHandler.RegisterMessages(messageRegistry)
+	worldHandler := handler.NewWorldHandler(terrainService, websocketService, logger)
+	worldHandler.RegisterMessages(messageRegistry)
+	combatHandler := handler.NewCombatHandler(combatService, logger)
+	combatHandler.RegisterMessages(messageRegistry)
+	inventoryHandler := handler.NewInventoryHandler(inventoryService, pickupService, logger)
+	inventoryHandler.RegisterMessages(messageRegistry)
+	containerHandler := handler.NewContainerHandler(containerService, logger)
+	containerHandler.RegisterMessages(messageRegistry)
+	moderationHandler := handler.NewModerationHandler(websocketService, logger)
+	moderationHandler.RegisterMessages(messageRegistry)
+	catalogHandler := handler.NewCatalogHandler(catalogService, logger)
+	contentHandler := handler.NewContentHandler(contentService, logger)
+
+	// 7. Initialize Echo Web Server
 	e := echo.New()
 
-	// 9. Setup Routes
-	api.SetupRouter(e, authHandler, playerMovementHandler, jwtManager, logger)
+	// 8. Setup Routes
+	api.SetupRouter(e, authHandler, playerMovementHandler, adminHandler, inventoryHandler, catalogHandler, contentHandler, jwtManager, logger)
 
-	// 10. Start Server in a goroutine
+	// 9. Start Server in a goroutine
 	go func() {
 		logger.Info("Starting server on port %s", cfg.AppPort)
 		if err := e.Start(":" + cfg.AppPort); err != nil && err != http.ErrServerClosed {
@@ -78,7 +173,7 @@ func main() {
 		}
 	}()
 
-	// 11. Graceful Shutdown
+	// 10. Graceful Shutdown
 	quit := make(chan os.Signal, 1)
 	// Listen for Ctrl+C (SIGINT) and graceful shutdown (SIGTERM)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -94,4 +189,6 @@ func main() {
 	} else {
 		logger.Info("Server gracefully stopped.")
 	}
+
+	gameLoopService.Stop()
 }
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"anarchy-core/internal/database"
+	"anarchy-core/internal/util"
+)
+
+const migrateUsage = "usage: app migrate up|down|status"
+
+// runMigrate executes the `migrate` subcommand.
+func runMigrate(args []string, migrator *database.Migrator, logger *util.Logger) error {
+	if len(args) != 1 {
+		return fmt.Errorf(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		if err != nil {
+			return err
+		}
+		logger.Info("Applied %d migration(s)", applied)
+	case "down":
+		rolledBack, err := migrator.Down()
+		if err != nil {
+			return err
+		}
+		if !rolledBack {
+			logger.Info("No migrations to roll back")
+		}
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf(migrateUsage)
+	}
+	return nil
+}
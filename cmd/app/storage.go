@@ -0,0 +1,68 @@
+package main
+
+import (
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/repository/memory"
+	"anarchy-core/internal/repository/postgres"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// repositories groups the repository implementations of the selected storage backend.
+type repositories struct {
+	users          domain.UserRepository
+	roles          domain.RoleRepository
+	refreshTokens  domain.RefreshTokenRepository
+	playerMovement domain.PlayerMovementRepository
+	playerStats    domain.PlayerStatsRepository
+	entities       domain.EntityRepository
+	entityLists    domain.EntityListRepository
+	objects        domain.ObjectRepository
+	objectLists    domain.ObjectListRepository
+	items          domain.ItemRepository
+	itemLists      domain.ItemListRepository
+	inventory      domain.InventoryRepository
+	world          domain.WorldRepository
+	auditLog       domain.AuditLogRepository
+}
+
+// newPostgresRepositories creates repositories backed by PostgreSQL.
+func newPostgresRepositories(db *sqlx.DB) *repositories {
+	return &repositories{
+		users:          postgres.NewUserRepositoryPostgres(db),
+		roles:          postgres.NewRoleRepositoryPostgres(db),
+		refreshTokens:  postgres.NewRefreshTokenRepositoryPostgres(db),
+		playerMovement: postgres.NewPlayerMovementRepositoryPostgres(db),
+		playerStats:    postgres.NewPlayerStatsRepositoryPostgres(db),
+		entities:       postgres.NewEntityRepositoryPostgres(db),
+		entityLists:    postgres.NewEntityListRepositoryPostgres(db),
+		objects:        postgres.NewObjectRepositoryPostgres(db),
+		objectLists:    postgres.NewObjectListRepositoryPostgres(db),
+		items:          postgres.NewItemRepositoryPostgres(db),
+		itemLists:      postgres.NewItemListRepositoryPostgres(db),
+		inventory:      postgres.NewInventoryRepositoryPostgres(db),
+		world:          postgres.NewWorldRepositoryPostgres(db),
+		auditLog:       postgres.NewAuditLogRepositoryPostgres(db),
+	}
+}
+
+// newMemoryRepositories creates repositories that keep all data in process memory.
+func newMemoryRepositories() *repositories {
+	store := memory.NewStore()
+	return &repositories{
+		users:          memory.NewUserRepositoryMemory(store),
+		roles:          memory.NewRoleRepositoryMemory(store),
+		refreshTokens:  memory.NewRefreshTokenRepositoryMemory(store),
+		playerMovement: memory.NewPlayerMovementRepositoryMemory(store),
+		playerStats:    memory.NewPlayerStatsRepositoryMemory(store),
+		entities:       memory.NewEntityRepositoryMemory(store),
+		entityLists:    memory.NewEntityListRepositoryMemory(store),
+		objects:        memory.NewObjectRepositoryMemory(store),
+		objectLists:    memory.NewObjectListRepositoryMemory(store),
+		items:          memory.NewItemRepositoryMemory(store),
+		itemLists:      memory.NewItemListRepositoryMemory(store),
+		inventory:      memory.NewInventoryRepositoryMemory(store),
+		world:          memory.NewWorldRepositoryMemory(store),
+		auditLog:       memory.NewAuditLogRepositoryMemory(store),
+	}
+}
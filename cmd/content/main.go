@@ -0,0 +1,151 @@
+// Command content moves object, item and entity definitions between the database
+// and a directory of content files, so game content can be versioned in git.
+//
+//	content export -dir DIR
+//	content import -dir DIR [-prune] [-apply] [-user NAME]
+//
+// import validates the files, compares them with the database and prints the
+// changes it would make. Only with -apply are the changes made, all in one
+// transaction and recorded in the audit log; running servers reload the content.
+// Definitions missing from the files are deleted only with -prune.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"anarchy-core/internal/content"
+	"anarchy-core/internal/database"
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/repository/postgres"
+	"anarchy-core/internal/util"
+	"anarchy-core/migration"
+
+	"github.com/joho/godotenv"
+)
+
+const usage = "usage: content export|import [flags]"
+
+// maxAuditUsername is the length of the username column of the audit log.
+const maxAuditUsername = 20
+
+func main() {
+	logger := util.NewLogger()
+
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "export":
+		err = runExport(os.Args[2:], logger)
+	case "import":
+		err = runImport(os.Args[2:], logger)
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		logger.Error("content %s failed: %v", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+// runExport writes the definitions stored in the database to a content directory.
+func runExport(args []string, logger *util.Logger) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	dir := fs.String("dir", "", "content directory to write")
+	fs.Parse(args)
+	if *dir == "" {
+		return fmt.Errorf("-dir is required")
+	}
+
+	return withContentRepository(logger, func(repo domain.ContentRepository) error {
+		bundle, err := repo.GetContent()
+		if err != nil {
+			return err
+		}
+		if err := content.WriteDir(*dir, bundle); err != nil {
+			return err
+		}
+		logger.Info("Exported %d objects, %d items and %d entities to %s",
+			len(bundle.ObjectLists), len(bundle.ItemLists), len(bundle.EntityLists), *dir)
+		return nil
+	})
+}
+
+// runImport plans, and with -apply makes, the changes that bring the database in line with a content directory.
+func runImport(args []string, logger *util.Logger) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	dir := fs.String("dir", "", "content directory to read")
+	prune := fs.Bool("prune", false, "delete definitions that are not in the content directory")
+	apply := fs.Bool("apply", false, "make the planned changes instead of only printing them")
+	user := fs.String("user", "content-import", "name recorded in the audit log")
+	fs.Parse(args)
+	if *dir == "" {
+		return fmt.Errorf("-dir is required")
+	}
+	if *user == "" || len(*user) > maxAuditUsername {
+		return fmt.Errorf("-user must be 1 to %d bytes long", maxAuditUsername)
+	}
+
+	desired, err := content.ReadDir(*dir)
+	if err != nil {
+		return err
+	}
+	if err := content.Validate(desired); err != nil {
+		return fmt.Errorf("invalid content in %s:\n%w", *dir, err)
+	}
+
+	return withContentRepository(logger, func(repo domain.ContentRepository) error {
+		changes, err := content.Import(repo, desired, *prune, *apply, &domain.AuditEntry{Username: *user})
+		for _, change := range changes {
+			fmt.Println(content.Describe(change))
+		}
+		switch {
+		case err != nil:
+			return err
+		case len(changes) == 0:
+			logger.Info("Database content already matches %s", *dir)
+		case !*apply:
+			logger.Info("Dry run: %d change(s) planned, run with -apply to make them", len(changes))
+		default:
+			logger.Info("Applied %d change(s) from %s", len(changes), *dir)
+		}
+		return nil
+	})
+}
+
+// withContentRepository connects to DATABASE_URL, checks that the schema is up to date
+// and runs fn with a content repository.
+func withContentRepository(logger *util.Logger, fn func(repo domain.ContentRepository) error) error {
+	// Try to load .env file, ignore if not found
+	godotenv.Load()
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		return fmt.Errorf("DATABASE_URL environment variable is not set")
+	}
+
+	db, err := database.InitPostgresDB(databaseURL, logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db, migration.FS, logger)
+	if err != nil {
+		return err
+	}
+	pending, err := migrator.Pending()
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("database schema is behind by %d migration(s), run `app migrate up` first", len(pending))
+	}
+
+	return fn(postgres.NewContentRepositoryPostgres(db))
+}
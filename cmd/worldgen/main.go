@@ -0,0 +1,178 @@
+// Command worldgen generates terrain heightmaps and moves them between the
+// world table and map files.
+//
+//	worldgen generate [-seed N] [-width W] [-height H] [-octaves N] [-scale S] [-sea-level L] [-out FILE]
+//	worldgen export -out FILE
+//	worldgen import [-merge] -in FILE
+//
+// Without -out, generate writes the map to the database given by DATABASE_URL.
+// Map files ending in .gz are gzip-compressed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"anarchy-core/internal/database"
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/repository/postgres"
+	"anarchy-core/internal/util"
+	"anarchy-core/internal/worldgen"
+	"anarchy-core/migration"
+
+	"github.com/joho/godotenv"
+)
+
+const usage = "usage: worldgen generate|export|import [flags]"
+
+func main() {
+	logger := util.NewLogger()
+
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "generate":
+		err = runGenerate(os.Args[2:], logger)
+	case "export":
+		err = runExport(os.Args[2:], logger)
+	case "import":
+		err = runImport(os.Args[2:], logger)
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		logger.Error("worldgen %s failed: %v", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+// runGenerate generates a map and writes it to a file or the database.
+func runGenerate(args []string, logger *util.Logger) error {
+	params := worldgen.DefaultParams(256, 256)
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	fs.Int64Var(&params.Seed, "seed", 1, "noise seed; the same seed always produces the same map")
+	fs.IntVar(&params.OriginX, "origin-x", 0, "X coordinate of the first grid point")
+	fs.IntVar(&params.OriginY, "origin-y", 0, "Y coordinate of the first grid point")
+	fs.IntVar(&params.Width, "width", params.Width, "number of grid points along X")
+	fs.IntVar(&params.Height, "height", params.Height, "number of grid points along Y")
+	fs.IntVar(&params.Octaves, "octaves", params.Octaves, "number of noise layers")
+	fs.Float64Var(&params.Scale, "scale", params.Scale, "size of the largest terrain features in grid points")
+	fs.Float64Var(&params.Persistence, "persistence", params.Persistence, "amplitude factor between octaves")
+	fs.Float64Var(&params.Lacunarity, "lacunarity", params.Lacunarity, "frequency factor between octaves")
+	fs.Float64Var(&params.Amplitude, "amplitude", params.Amplitude, "maximum height above or below zero")
+	fs.Float64Var(&params.SeaLevel, "sea-level", params.SeaLevel, "heights below this level are flattened to it")
+	out := fs.String("out", "", "write the map to this file instead of the database")
+	merge := fs.Bool("merge", false, "keep existing world points outside the generated area")
+	fs.Parse(args)
+
+	points, err := worldgen.Generate(params)
+	if err != nil {
+		return err
+	}
+	logger.Info("Generated %dx%d map with seed %d", params.Width, params.Height, params.Seed)
+
+	if *out != "" {
+		if err := worldgen.WriteMapFile(*out, worldgen.NewMapFile(points, &params)); err != nil {
+			return err
+		}
+		logger.Info("Wrote %d points to %s", len(points), *out)
+		return nil
+	}
+	return withWorldRepository(logger, func(repo domain.WorldRepository) error {
+		return savePoints(repo, points, *merge, logger)
+	})
+}
+
+// runExport writes the terrain stored in the database to a map file.
+func runExport(args []string, logger *util.Logger) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	out := fs.String("out", "", "map file to write")
+	fs.Parse(args)
+	if *out == "" {
+		return fmt.Errorf("-out is required")
+	}
+
+	return withWorldRepository(logger, func(repo domain.WorldRepository) error {
+		points, err := repo.GetAllWorldPoints()
+		if err != nil {
+			return err
+		}
+		if err := worldgen.WriteMapFile(*out, worldgen.NewMapFile(points, nil)); err != nil {
+			return err
+		}
+		logger.Info("Exported %d points to %s", len(points), *out)
+		return nil
+	})
+}
+
+// runImport loads a map file into the database.
+func runImport(args []string, logger *util.Logger) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	in := fs.String("in", "", "map file to read")
+	merge := fs.Bool("merge", false, "keep existing world points outside the imported area")
+	fs.Parse(args)
+	if *in == "" {
+		return fmt.Errorf("-in is required")
+	}
+
+	file, err := worldgen.ReadMapFile(*in)
+	if err != nil {
+		return err
+	}
+	return withWorldRepository(logger, func(repo domain.WorldRepository) error {
+		return savePoints(repo, file.Points(), *merge, logger)
+	})
+}
+
+// savePoints replaces the terrain with points, or upserts them if merge is set.
+func savePoints(repo domain.WorldRepository, points []domain.World, merge bool, logger *util.Logger) error {
+	if merge {
+		if err := repo.SaveWorldPoints(points); err != nil {
+			return err
+		}
+		logger.Info("Merged %d points into the world", len(points))
+		return nil
+	}
+	if err := repo.ReplaceWorldPoints(points); err != nil {
+		return err
+	}
+	logger.Info("Replaced the world with %d points", len(points))
+	return nil
+}
+
+// withWorldRepository connects to DATABASE_URL, checks that the schema is up to date
+// and runs fn with a world repository.
+func withWorldRepository(logger *util.Logger, fn func(repo domain.WorldRepository) error) error {
+	// Try to load .env file, ignore if not found
+	godotenv.Load()
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		return fmt.Errorf("DATABASE_URL environment variable is not set")
+	}
+
+	db, err := database.InitPostgresDB(databaseURL, logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db, migration.FS, logger)
+	if err != nil {
+		return err
+	}
+	pending, err := migrator.Pending()
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("database schema is behind by %d migration(s), run `app migrate up` first", len(pending))
+	}
+
+	return fn(postgres.NewWorldRepositoryPostgres(db))
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"anarchy-core/internal/service"
+	"anarchy-core/internal/util"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AdminHandler handles HTTP requests of server administrators and moderators.
+type AdminHandler struct {
+	movementValidator *service.MovementValidator
+	roleService       *service.RoleService
+	logger            *util.Logger
+}
+
+// NewAdminHandler creates a new AdminHandler.
+func NewAdminHandler(movementValidator *service.MovementValidator, roleService *service.RoleService, logger *util.Logger) *AdminHandler {
+	return &AdminHandler{
+		movementValidator: movementValidator,
+		roleService:       roleService,
+		logger:            logger,
+	}
+}
+
+// GetSuspicions lists the anti-cheat records of players whose moves were rejected, most suspicious first.
+func (h *AdminHandler) GetSuspicions(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"players": h.movementValidator.Suspicions(time.Now())})
+}
+
+// GetUserRoles lists the roles of a user.
+func (h *AdminHandler) GetUserRoles(c echo.Context) error {
+	username := c.Param("username")
+	roles, err := h.roleService.UserRoles(username)
+	if err != nil {
+		return h.roleError(err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"username": username, "roles": roles})
+}
+
+// GrantRole gives a user the role in the request path.
+func (h *AdminHandler) GrantRole(c echo.Context) error {
+	username := c.Param("username")
+	roles, err := h.roleService.GrantRole(actor(c), username, c.Param("role"))
+	if err != nil {
+		return h.roleError(err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"username": username, "roles": roles})
+}
+
+// RevokeRole takes the role in the request path from a user.
+func (h *AdminHandler) RevokeRole(c echo.Context) error {
+	username := c.Param("username")
+	roles, err := h.roleService.RevokeRole(actor(c), username, c.Param("role"))
+	if err != nil {
+		return h.roleError(err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"username": username, "roles": roles})
+}
+
+// roleError maps a role management error to an HTTP error.
+func (h *AdminHandler) roleError(err error) error {
+	switch {
+	case errors.Is(err, util.ErrInvalidRole):
+		return echo.NewHTTPError(http.StatusBadRequest, "Unknown role")
+	case errors.Is(err, util.ErrUserNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "User not found")
+	default:
+		h.logger.Error("Role management failed: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to manage roles")
+	}
+}
@@ -42,7 +42,7 @@ func (h *AuthHandler) RegisterUser(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	token, err := h.authService.RegisterUser(req.Username, req.Password)
+	tokens, err := h.authService.RegisterUser(req.Username, req.Password)
 	if err != nil {
 		if errors.Is(err, util.ErrUserAlreadyExists) {
 			return echo.NewHTTPError(http.StatusConflict, "User with this username already exists")
@@ -51,7 +51,7 @@ func (h *AuthHandler) RegisterUser(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to register user")
 	}
 
-	return c.JSON(http.StatusCreated, echo.Map{"message": "User registered successfully", "token": token})
+	return c.JSON(http.StatusCreated, newTokenResponse("User registered successfully", tokens))
 }
 
 // LoginRequest represents the request body for user login.
@@ -72,7 +72,7 @@ func (h *AuthHandler) LoginUser(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	token, err := h.authService.LoginUser(req.Username, req.Password)
+	tokens, err := h.authService.LoginUser(req.Username, req.Password)
 	if err != nil {
 		if errors.Is(err, util.ErrInvalidCredentials) {
 			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid username or password")
@@ -81,5 +81,68 @@ func (h *AuthHandler) LoginUser(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to login user")
 	}
 
-	return c.JSON(http.StatusOK, echo.Map{"message": "Login successful", "token": token})
+	return c.JSON(http.StatusOK, newTokenResponse("Login successful", tokens))
+}
+
+// RefreshRequest represents the request body for a token refresh or a logout.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// RefreshTokens handles the exchange of a refresh token for a new token pair.
+func (h *AuthHandler) RefreshTokens(c echo.Context) error {
+	req := new(RefreshRequest)
+	if err := c.Bind(req); err != nil {
+		h.logger.Error("RefreshTokens: Failed to bind request: %v", err)
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	tokens, err := h.authService.RefreshTokens(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, util.ErrInvalidToken) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired refresh token")
+		}
+		h.logger.Error("RefreshTokens: Failed to refresh tokens: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to refresh tokens")
+	}
+
+	return c.JSON(http.StatusOK, newTokenResponse("Tokens refreshed", tokens))
+}
+
+// Logout handles the revocation of a refresh token.
+func (h *AuthHandler) Logout(c echo.Context) error {
+	req := new(RefreshRequest)
+	if err := c.Bind(req); err != nil {
+		h.logger.Error("Logout: Failed to bind request: %v", err)
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := h.authService.Logout(req.RefreshToken); err != nil {
+		if errors.Is(err, util.ErrInvalidToken) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid refresh token")
+		}
+		h.logger.Error("Logout: Failed to revoke refresh token: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to logout")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// newTokenResponse builds the response body carrying a token pair. "token" repeats the
+// access token for clients written before refresh tokens existed.
+func newTokenResponse(message string, tokens *service.TokenPair) echo.Map {
+	return echo.Map{
+		"message":       message,
+		"token":         tokens.AccessToken,
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"token_type":    "Bearer",
+		"expires_in":    int(tokens.ExpiresIn.Seconds()),
+	}
 }
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"anarchy-core/internal/service"
+	"anarchy-core/internal/util"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Page sizes of catalog requests.
+const (
+	defaultCatalogLimit = 50
+	maxCatalogLimit     = 200
+)
+
+// CatalogHandler serves the read-only catalog of object, item and entity definitions.
+type CatalogHandler struct {
+	catalogService *service.CatalogService
+	logger         *util.Logger
+}
+
+// NewCatalogHandler creates a new CatalogHandler.
+func NewCatalogHandler(catalogService *service.CatalogService, logger *util.Logger) *CatalogHandler {
+	return &CatalogHandler{
+		catalogService: catalogService,
+		logger:         logger,
+	}
+}
+
+// GetObjects returns a page of object definitions.
+func (h *CatalogHandler) GetObjects(c echo.Context) error {
+	query, err := catalogQuery(c)
+	if err != nil {
+		return err
+	}
+	page, err := h.catalogService.Objects(query)
+	if err != nil {
+		h.logger.Error("GetObjects: Failed to load catalog: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load catalog")
+	}
+	return h.respond(c, page)
+}
+
+// GetItems returns a page of item definitions.
+func (h *CatalogHandler) GetItems(c echo.Context) error {
+	query, err := catalogQuery(c)
+	if err != nil {
+		return err
+	}
+	page, err := h.catalogService.Items(query)
+	if err != nil {
+		h.logger.Error("GetItems: Failed to load catalog: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load catalog")
+	}
+	return h.respond(c, page)
+}
+
+// GetEntities returns a page of entity templates.
+func (h *CatalogHandler) GetEntities(c echo.Context) error {
+	query, err := catalogQuery(c)
+	if err != nil {
+		return err
+	}
+	page, err := h.catalogService.Entities(query)
+	if err != nil {
+		h.logger.Error("GetEntities: Failed to load catalog: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load catalog")
+	}
+	return h.respond(c, page)
+}
+
+// respond writes page with an ETag of its contents, or 304 Not Modified
+// if the client already has that version.
+func (h *CatalogHandler) respond(c echo.Context, page interface{}) error {
+	body, err := json.Marshal(page)
+	if err != nil {
+		h.logger.Error("Failed to encode catalog page: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to encode catalog")
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	header := c.Response().Header()
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", "no-cache")
+	if etagMatches(c.Request().Header.Get("If-None-Match"), etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.JSONBlob(http.StatusOK, body)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// catalogQuery reads the limit, offset, rarity, min_rarity and max_rarity query parameters.
+func catalogQuery(c echo.Context) (service.CatalogQuery, error) {
+	var query service.CatalogQuery
+	var err error
+	if query.Limit, err = intParam(c, "limit", defaultCatalogLimit, 1, maxCatalogLimit); err != nil {
+		return query, err
+	}
+	if query.Offset, err = intParam(c, "offset", 0, 0, math.MaxInt32); err != nil {
+		return query, err
+	}
+	if query.MinRarity, err = optionalIntParam(c, "min_rarity"); err != nil {
+		return query, err
+	}
+	if query.MaxRarity, err = optionalIntParam(c, "max_rarity"); err != nil {
+		return query, err
+	}
+	rarity, err := optionalIntParam(c, "rarity")
+	if err != nil {
+		return query, err
+	}
+	if rarity != nil {
+		query.MinRarity, query.MaxRarity = rarity, rarity
+	}
+	return query, nil
+}
+
+// intParam reads an integer query parameter, falling back to def if it is not set.
+func intParam(c echo.Context, name string, def, min, max int) (int, error) {
+	v := c.QueryParam(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < min || n > max {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s must be an integer between %d and %d", name, min, max))
+	}
+	return n, nil
+}
+
+// optionalIntParam reads an integer query parameter, returning nil if it is not set.
+func optionalIntParam(c echo.Context, name string) (*int, error) {
+	v := c.QueryParam(name)
+	if v == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s must be an integer", name))
+	}
+	return &n, nil
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"anarchy-core/internal/protocol"
+	"anarchy-core/internal/service"
+	"anarchy-core/internal/util"
+)
+
+// CombatHandler accepts attacks from WebSocket clients.
+type CombatHandler struct {
+	combatService *service.CombatService
+	logger        *util.Logger
+}
+
+// NewCombatHandler creates a new CombatHandler.
+func NewCombatHandler(combatService *service.CombatService, logger *util.Logger) *CombatHandler {
+	return &CombatHandler{
+		combatService: combatService,
+		logger:        logger,
+	}
+}
+
+// AttackPayload is the payload of an "attack" message.
+type AttackPayload struct {
+	TargetType string `json:"target_type"` // "entity" или "player"
+	TargetID   string `json:"target_id"`
+}
+
+// RegisterMessages registers the combat message handlers.
+func (h *CombatHandler) RegisterMessages(registry *MessageRegistry) {
+	registry.Register("attack", h.handleAttack)
+}
+
+// handleAttack queues an attack; the game loop resolves it on its next tick.
+func (h *CombatHandler) handleAttack(client *service.Client, msg *protocol.Envelope) error {
+	var payload AttackPayload
+	if err := msg.DecodePayload(&payload); err != nil {
+		return err
+	}
+	if payload.TargetType != service.CombatantEntity && payload.TargetType != service.CombatantPlayer {
+		return protocol.NewError(protocol.ErrCodeBadRequest, "target_type must be %q or %q", service.CombatantEntity, service.CombatantPlayer)
+	}
+	if payload.TargetID == "" {
+		return protocol.NewError(protocol.ErrCodeBadRequest, "target_id is required")
+	}
+
+	h.combatService.QueueAttack(service.AttackInput{
+		PlayerID:   client.UserID,
+		TargetType: payload.TargetType,
+		TargetID:   payload.TargetID,
+	})
+	return nil
+}
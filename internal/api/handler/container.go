@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"anarchy-core/internal/protocol"
+	"anarchy-core/internal/service"
+	"anarchy-core/internal/util"
+)
+
+// ContainerHandler accepts container actions from WebSocket clients.
+type ContainerHandler struct {
+	containerService *service.ContainerService
+	logger           *util.Logger
+}
+
+// NewContainerHandler creates a new ContainerHandler.
+func NewContainerHandler(containerService *service.ContainerService, logger *util.Logger) *ContainerHandler {
+	return &ContainerHandler{
+		containerService: containerService,
+		logger:           logger,
+	}
+}
+
+// OpenContainerPayload is the payload of an "open_container" message.
+type OpenContainerPayload struct {
+	EntityID int `json:"entity_id"`
+}
+
+// ContainerTransferPayload is the payload of "container_take" and "container_put" messages.
+// Without to_slot the items go wherever they fit; a zero quantity moves the whole slot.
+type ContainerTransferPayload struct {
+	EntityID int  `json:"entity_id"`
+	Slot     int  `json:"slot"`
+	ToSlot   *int `json:"to_slot"`
+	Quantity int  `json:"quantity"`
+}
+
+// RegisterMessages registers the container message handlers.
+func (h *ContainerHandler) RegisterMessages(registry *MessageRegistry) {
+	registry.Register(service.ContainerActionOpen, h.handleOpen)
+	registry.Register(service.ContainerActionClose, h.handleClose)
+	registry.Register(service.ContainerActionTake, h.handleTransfer)
+	registry.Register(service.ContainerActionPut, h.handleTransfer)
+}
+
+// handleOpen queues opening a container; the game loop resolves it on its next tick.
+func (h *ContainerHandler) handleOpen(client *service.Client, msg *protocol.Envelope) error {
+	var payload OpenContainerPayload
+	if err := msg.DecodePayload(&payload); err != nil {
+		return err
+	}
+	if payload.EntityID <= 0 {
+		return protocol.NewError(protocol.ErrCodeBadRequest, "entity_id is required")
+	}
+
+	h.containerService.QueueAction(service.ContainerAction{
+		PlayerID: client.UserID,
+		Action:   service.ContainerActionOpen,
+		EntityID: payload.EntityID,
+	})
+	return nil
+}
+
+// handleClose queues closing the player's open container.
+func (h *ContainerHandler) handleClose(client *service.Client, msg *protocol.Envelope) error {
+	h.containerService.QueueAction(service.ContainerAction{
+		PlayerID: client.UserID,
+		Action:   service.ContainerActionClose,
+	})
+	return nil
+}
+
+// handleTransfer queues moving items between the player's inventory and its open container.
+func (h *ContainerHandler) handleTransfer(client *service.Client, msg *protocol.Envelope) error {
+	var payload ContainerTransferPayload
+	if err := msg.DecodePayload(&payload); err != nil {
+		return err
+	}
+	if payload.EntityID <= 0 {
+		return protocol.NewError(protocol.ErrCodeBadRequest, "entity_id is required")
+	}
+	if payload.Quantity < 0 {
+		return protocol.NewError(protocol.ErrCodeBadRequest, "quantity must not be negative")
+	}
+	toSlot := -1
+	if payload.ToSlot != nil {
+		if *payload.ToSlot < 0 {
+			return protocol.NewError(protocol.ErrCodeBadRequest, "to_slot must not be negative")
+		}
+		toSlot = *payload.ToSlot
+	}
+
+	h.containerService.QueueAction(service.ContainerAction{
+		PlayerID: client.UserID,
+		Action:   msg.Type,
+		EntityID: payload.EntityID,
+		Slot:     payload.Slot,
+		ToSlot:   toSlot,
+		Quantity: payload.Quantity,
+	})
+	return nil
+}
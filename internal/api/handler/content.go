@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/service"
+	"anarchy-core/internal/util"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Page sizes of audit log requests.
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// ContentHandler lets administrators manage object, item and entity definitions.
+type ContentHandler struct {
+	contentService *service.ContentService
+	logger         *util.Logger
+}
+
+// NewContentHandler creates a new ContentHandler.
+func NewContentHandler(contentService *service.ContentService, logger *util.Logger) *ContentHandler {
+	return &ContentHandler{
+		contentService: contentService,
+		logger:         logger,
+	}
+}
+
+// GetObjectLists lists all object definitions.
+func (h *ContentHandler) GetObjectLists(c echo.Context) error {
+	objectLists, err := h.contentService.ObjectLists()
+	if err != nil {
+		return h.contentError("GetObjectLists", err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"objects": objectLists})
+}
+
+// CreateObjectList creates an object definition.
+func (h *ContentHandler) CreateObjectList(c echo.Context) error {
+	var objectList domain.ObjectList
+	if err := c.Bind(&objectList); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+	created, err := h.contentService.CreateObjectList(actor(c), objectList)
+	if err != nil {
+		return h.contentError("CreateObjectList", err)
+	}
+	return c.JSON(http.StatusCreated, created)
+}
+
+// UpdateObjectList replaces the object definition with the ID in the path.
+func (h *ContentHandler) UpdateObjectList(c echo.Context) error {
+	var objectList domain.ObjectList
+	if err := c.Bind(&objectList); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
+	objectList.ID = id
+	updated, err := h.contentService.UpdateObjectList(actor(c), objectList)
+	if err != nil {
+		return h.contentError("UpdateObjectList", err)
+	}
+	return c.JSON(http.StatusOK, updated)
+}
+
+// DeleteObjectList deletes the object definition with the ID in the path.
+func (h *ContentHandler) DeleteObjectList(c echo.Context) error {
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
+	if err := h.contentService.DeleteObjectList(actor(c), id); err != nil {
+		return h.contentError("DeleteObjectList", err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetItemLists lists all item definitions.
+func (h *ContentHandler) GetItemLists(c echo.Context) error {
+	definitions, err := h.contentService.ItemLists()
+	if err != nil {
+		return h.contentError("GetItemLists", err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"items": definitions})
+}
+
+// CreateItemList creates an item definition.
+func (h *ContentHandler) CreateItemList(c echo.Context) error {
+	var definition domain.ItemDefinition
+	if err := c.Bind(&definition); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+	created, err := h.contentService.CreateItemList(actor(c), definition)
+	if err != nil {
+		return h.contentError("CreateItemList", err)
+	}
+	return c.JSON(http.StatusCreated, created)
+}
+
+// UpdateItemList replaces the item definition with the ID in the path.
+func (h *ContentHandler) UpdateItemList(c echo.Context) error {
+	var definition domain.ItemDefinition
+	if err := c.Bind(&definition); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
+	definition.ID = id
+	updated, err := h.contentService.UpdateItemList(actor(c), definition)
+	if err != nil {
+		return h.contentError("UpdateItemList", err)
+	}
+	return c.JSON(http.StatusOK, updated)
+}
+
+// DeleteItemList deletes the item definition with the ID in the path.
+func (h *ContentHandler) DeleteItemList(c echo.Context) error {
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
+	if err := h.contentService.DeleteItemList(actor(c), id); err != nil {
+		return h.contentError("DeleteItemList", err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetEntityLists lists all entity templates.
+func (h *ContentHandler) GetEntityLists(c echo.Context) error {
+	entityLists, err := h.contentService.EntityLists()
+	if err != nil {
+		return h.contentError("GetEntityLists", err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"entities": entityLists})
+}
+
+// CreateEntityList creates an entity template.
+func (h *ContentHandler) CreateEntityList(c echo.Context) error {
+	var entityList domain.EntityList
+	if err := c.Bind(&entityList); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+	created, err := h.contentService.CreateEntityList(actor(c), entityList)
+	if err != nil {
+		return h.contentError("CreateEntityList", err)
+	}
+	return c.JSON(http.StatusCreated, created)
+}
+
+// UpdateEntityList replaces the entity template with the ID in the path.
+func (h *ContentHandler) UpdateEntityList(c echo.Context) error {
+	var entityList domain.EntityList
+	if err := c.Bind(&entityList); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
+	entityList.ID = id
+	updated, err := h.contentService.UpdateEntityList(actor(c), entityList)
+	if err != nil {
+		return h.contentError("UpdateEntityList", err)
+	}
+	return c.JSON(http.StatusOK, updated)
+}
+
+// DeleteEntityList deletes the entity template with the ID in the path and its entities.
+func (h *ContentHandler) DeleteEntityList(c echo.Context) error {
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
+	if err := h.contentService.DeleteEntityList(actor(c), id); err != nil {
+		return h.contentError("DeleteEntityList", err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetAuditLog returns a page of the content audit log, newest first.
+func (h *ContentHandler) GetAuditLog(c echo.Context) error {
+	limit, err := intParam(c, "limit", defaultAuditLimit, 1, maxAuditLimit)
+	if err != nil {
+		return err
+	}
+	offset, err := intParam(c, "offset", 0, 0, math.MaxInt32)
+	if err != nil {
+		return err
+	}
+	entries, err := h.contentService.AuditLog(limit, offset)
+	if err != nil {
+		return h.contentError("GetAuditLog", err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"entries": entries, "limit": limit, "offset": offset})
+}
+
+// contentError maps content errors to HTTP errors.
+func (h *ContentHandler) contentError(op string, err error) error {
+	switch {
+	case errors.Is(err, util.ErrInvalidDefinition):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, util.ErrDefinitionInUse):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, util.ErrObjectListNotFound),
+		errors.Is(err, util.ErrItemListNotFound),
+		errors.Is(err, util.ErrEntityListNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	h.logger.Error("%s: %v", op, err)
+	return echo.NewHTTPError(http.StatusInternalServerError, "Failed to manage content")
+}
+
+// actor returns the authenticated user making a change.
+func actor(c echo.Context) service.Actor {
+	userID, _ := c.Get("userID").(string)
+	username, _ := c.Get("username").(string)
+	return service.Actor{UserID: userID, Username: username}
+}
+
+// idParam reads the positive integer ID in the request path.
+func idParam(c echo.Context) (int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "id must be a positive integer")
+	}
+	return id, nil
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"anarchy-core/internal/protocol"
+	"anarchy-core/internal/service"
+	"anarchy-core/internal/util"
+
+	"github.com/labstack/echo/v4"
+)
+
+// InventoryHandler serves player inventories over HTTP and WebSocket.
+type InventoryHandler struct {
+	inventoryService *service.InventoryService
+	pickupService    *service.PickupService
+	logger           *util.Logger
+}
+
+// NewInventoryHandler creates a new InventoryHandler.
+func NewInventoryHandler(inventoryService *service.InventoryService, pickupService *service.PickupService, logger *util.Logger) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryService: inventoryService,
+		pickupService:    pickupService,
+		logger:           logger,
+	}
+}
+
+// InventoryMovePayload is the payload of an "inventory_move" message.
+// A zero quantity moves the whole slot.
+type InventoryMovePayload struct {
+	From     int `json:"from"`
+	To       int `json:"to"`
+	Quantity int `json:"quantity"`
+}
+
+// InventoryRemovePayload is the payload of an "inventory_remove" message.
+// A zero quantity removes the whole slot.
+type InventoryRemovePayload struct {
+	Slot     int `json:"slot"`
+	Quantity int `json:"quantity"`
+}
+
+// PickupPayload is the payload of a "pickup" message.
+type PickupPayload struct {
+	EntityID int `json:"entity_id"`
+}
+
+// DropPayload is the payload of a "drop" message.
+type DropPayload struct {
+	Slot int `json:"slot"`
+}
+
+// GetInventory returns the inventory of the authenticated player.
+func (h *InventoryHandler) GetInventory(c echo.Context) error {
+	userID := c.Get("userID").(string)
+	view, err := h.inventoryService.Inventory(userID)
+	if err != nil {
+		h.logger.Error("GetInventory: Failed to load inventory of %s: %v", userID, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load inventory")
+	}
+	return c.JSON(http.StatusOK, view)
+}
+
+// RegisterMessages registers the inventory message handlers.
+func (h *InventoryHandler) RegisterMessages(registry *MessageRegistry) {
+	registry.Register("inventory_request", h.handleInventoryRequest)
+	registry.Register("inventory_move", h.handleInventoryMove)
+	registry.Register("inventory_remove", h.handleInventoryRemove)
+	registry.Register("pickup", h.handlePickup)
+	registry.Register("drop", h.handleDrop)
+}
+
+// handleInventoryRequest pushes the player's inventory to its client.
+func (h *InventoryHandler) handleInventoryRequest(client *service.Client, msg *protocol.Envelope) error {
+	h.inventoryService.PushInventory(client.UserID)
+	return nil
+}
+
+// handleInventoryMove moves, splits, merges or swaps inventory slots.
+func (h *InventoryHandler) handleInventoryMove(client *service.Client, msg *protocol.Envelope) error {
+	var payload InventoryMovePayload
+	if err := msg.DecodePayload(&payload); err != nil {
+		return err
+	}
+	return inventoryError(h.inventoryService.MoveItem(client.UserID, payload.From, payload.To, payload.Quantity))
+}
+
+// handleInventoryRemove destroys items in an inventory slot.
+func (h *InventoryHandler) handleInventoryRemove(client *service.Client, msg *protocol.Envelope) error {
+	var payload InventoryRemovePayload
+	if err := msg.DecodePayload(&payload); err != nil {
+		return err
+	}
+	return inventoryError(h.inventoryService.RemoveItem(client.UserID, payload.Slot, payload.Quantity))
+}
+
+// handlePickup queues picking up a world entity; the game loop resolves it on its next tick.
+func (h *InventoryHandler) handlePickup(client *service.Client, msg *protocol.Envelope) error {
+	var payload PickupPayload
+	if err := msg.DecodePayload(&payload); err != nil {
+		return err
+	}
+	if payload.EntityID <= 0 {
+		return protocol.NewError(protocol.ErrCodeBadRequest, "entity_id is required")
+	}
+
+	h.pickupService.QueueAction(service.ItemAction{
+		PlayerID: client.UserID,
+		Action:   service.ItemActionPickup,
+		EntityID: payload.EntityID,
+	})
+	return nil
+}
+
+// handleDrop queues dropping one item of an inventory slot at the player's feet.
+func (h *InventoryHandler) handleDrop(client *service.Client, msg *protocol.Envelope) error {
+	var payload DropPayload
+	if err := msg.DecodePayload(&payload); err != nil {
+		return err
+	}
+
+	h.pickupService.QueueAction(service.ItemAction{
+		PlayerID: client.UserID,
+		Action:   service.ItemActionDrop,
+		Slot:     payload.Slot,
+	})
+	return nil
+}
+
+// inventoryError reports rule violations back to the client as bad requests.
+func inventoryError(err error) error {
+	switch {
+	case errors.Is(err, util.ErrInventoryFull),
+		errors.Is(err, util.ErrInventoryEntryNotFound),
+		errors.Is(err, util.ErrInventorySlotTaken),
+		errors.Is(err, util.ErrInvalidInventoryMove),
+		errors.Is(err, util.ErrInvalidQuantity):
+		return protocol.NewError(protocol.ErrCodeBadRequest, "%v", err)
+	}
+	return err
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"anarchy-core/internal/protocol"
+	"anarchy-core/internal/service"
+)
+
+// MessageHandlerFunc handles a single WebSocket message from a client.
+// Returning a *protocol.Error sends that error back to the client as is;
+// any other error is reported as an internal error.
+type MessageHandlerFunc func(client *service.Client, msg *protocol.Envelope) error
+
+// MessageRegistry dispatches WebSocket messages to handlers by message type.
+type MessageRegistry struct {
+	mu       sync.RWMutex
+	handlers map[string]MessageHandlerFunc
+}
+
+// NewMessageRegistry creates an empty MessageRegistry.
+func NewMessageRegistry() *MessageRegistry {
+	return &MessageRegistry{handlers: make(map[string]MessageHandlerFunc)}
+}
+
+// Register adds a handler for a message type. Registering a type twice is a programming error.
+func (r *MessageRegistry) Register(msgType string, fn MessageHandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.handlers[msgType]; exists {
+		panic(fmt.Sprintf("handler: message type %q registered twice", msgType))
+	}
+	r.handlers[msgType] = fn
+}
+
+// RequireRole wraps fn so that it only runs for sessions holding at least one of roles;
+// other clients receive a forbidden error.
+func RequireRole(fn MessageHandlerFunc, roles ...string) MessageHandlerFunc {
+	return func(client *service.Client, msg *protocol.Envelope) error {
+		if !client.HasRole(roles...) {
+			return protocol.NewError(protocol.ErrCodeForbidden, "message %q requires role %s", msg.Type, strings.Join(roles, " or "))
+		}
+		return fn(client, msg)
+	}
+}
+
+// Dispatch routes a message to the handler registered for its type.
+func (r *MessageRegistry) Dispatch(client *service.Client, msg *protocol.Envelope) error {
+	r.mu.RLock()
+	fn, ok := r.handlers[msg.Type]
+	r.mu.RUnlock()
+
+	if !ok {
+		return protocol.NewError(protocol.ErrCodeUnknownType, "unknown message type %q", msg.Type)
+	}
+	return fn(client, msg)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/protocol"
+	"anarchy-core/internal/service"
+	"anarchy-core/internal/util"
+)
+
+// ModerationHandler accepts moderation commands from WebSocket clients of moderators and administrators.
+type ModerationHandler struct {
+	websocketService *service.WebSocketService
+	logger           *util.Logger
+}
+
+// NewModerationHandler creates a new ModerationHandler.
+func NewModerationHandler(websocketService *service.WebSocketService, logger *util.Logger) *ModerationHandler {
+	return &ModerationHandler{
+		websocketService: websocketService,
+		logger:           logger,
+	}
+}
+
+// KickPayload is the payload of a "kick" message.
+type KickPayload struct {
+	PlayerID string `json:"player_id"`
+}
+
+// PlayerKickedMessage confirms to a moderator that a player was kicked.
+type PlayerKickedMessage struct {
+	Type     string `json:"type"` // "player_kicked"
+	PlayerID string `json:"player_id"`
+}
+
+// RegisterMessages registers the moderation message handlers, restricted to moderators and administrators.
+func (h *ModerationHandler) RegisterMessages(registry *MessageRegistry) {
+	registry.Register("kick", RequireRole(h.handleKick, domain.RoleModerator, domain.RoleAdmin))
+}
+
+// handleKick ends the session of another player.
+func (h *ModerationHandler) handleKick(client *service.Client, msg *protocol.Envelope) error {
+	var payload KickPayload
+	if err := msg.DecodePayload(&payload); err != nil {
+		return err
+	}
+	if payload.PlayerID == "" {
+		return protocol.NewError(protocol.ErrCodeBadRequest, "player_id is required")
+	}
+	if payload.PlayerID == client.UserID {
+		return protocol.NewError(protocol.ErrCodeBadRequest, "cannot kick yourself")
+	}
+	if !h.websocketService.KickPlayer(payload.PlayerID, protocol.CloseKicked) {
+		return protocol.NewError(protocol.ErrCodeBadRequest, "player %s is not connected", payload.PlayerID)
+	}
+
+	h.logger.Info("Player %s kicked by %s (ID: %s)", payload.PlayerID, client.Username, client.UserID)
+	h.websocketService.SendToClient(client, PlayerKickedMessage{Type: "player_kicked", PlayerID: payload.PlayerID})
+	return nil
+}
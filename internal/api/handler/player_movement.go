@@ -1,11 +1,13 @@
 package handler
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
 	"anarchy-core/internal/auth"
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/protocol"
 	"anarchy-core/internal/service"
 	"anarchy-core/internal/util"
 
@@ -13,11 +15,18 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// maxMessageSize is the largest message accepted from a client, enough for a full chunk_request.
+const maxMessageSize = 4096
+
 // PlayerMovementHandler handles WebSocket connections and player movement.
 type PlayerMovementHandler struct {
 	playerService    *service.PlayerService
 	websocketService *service.WebSocketService
+	gameLoopService  *service.GameLoopService
+	terrainService   *service.TerrainService
+	combatService    *service.CombatService
 	jwtManager       *auth.JWTManager
+	registry         *MessageRegistry
 	logger           *util.Logger
 	upgrader         websocket.Upgrader
 }
@@ -26,17 +35,26 @@ type PlayerMovementHandler struct {
 func NewPlayerMovementHandler(
 	playerService *service.PlayerService,
 	websocketService *service.WebSocketService,
+	gameLoopService *service.GameLoopService,
+	terrainService *service.TerrainService,
+	combatService *service.CombatService,
 	jwtManager *auth.JWTManager,
+	registry *MessageRegistry,
 	logger *util.Logger,
 ) *PlayerMovementHandler {
 	return &PlayerMovementHandler{
 		playerService:    playerService,
 		websocketService: websocketService,
+		gameLoopService:  gameLoopService,
+		terrainService:   terrainService,
+		combatService:    combatService,
 		jwtManager:       jwtManager,
+		registry:         registry,
 		logger:           logger,
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
+			Subprotocols:    protocol.Subprotocols(),
 			CheckOrigin: func(r *http.Request) bool {
 				// Allow all origins for development. In production, restrict this.
 				return true
@@ -45,12 +63,39 @@ func NewPlayerMovementHandler(
 	}
 }
 
-// PlayerMovementMessage represents a message from client about player movement.
-type PlayerMovementMessage struct {
-	Type string  `json:"type"` // "move"
-	X    float64 `json:"x"`
-	Y    float64 `json:"y"`
-	Z    float64 `json:"z"`
+// MovePayload is the payload of a "move" message.
+type MovePayload struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// SnapshotAckPayload is the payload of a "snapshot_ack" message.
+type SnapshotAckPayload struct {
+	ID uint32 `json:"id"`
+}
+
+// RegisterMessages registers the movement and snapshot message handlers.
+func (h *PlayerMovementHandler) RegisterMessages(registry *MessageRegistry) {
+	registry.Register("move", h.handleMove)
+	registry.Register("snapshot_ack", h.handleSnapshotAck)
+	registry.Register("snapshot_resync", h.handleSnapshotResync)
+}
+
+// handleMove queues a movement input; the game loop applies and broadcasts it on its next tick.
+func (h *PlayerMovementHandler) handleMove(client *service.Client, msg *protocol.Envelope) error {
+	var payload MovePayload
+	if err := msg.DecodePayload(&payload); err != nil {
+		return err
+	}
+	h.gameLoopService.QueueInput(service.PlayerInput{
+		PlayerID: client.UserID,
+		Username: client.Username,
+		X:        payload.X,
+		Y:        payload.Y,
+		Z:        payload.Z,
+	})
+	return nil
 }
 
 // HandleWebSocketConnection handles the WebSocket upgrade and message loop.
@@ -60,7 +105,7 @@ func (h *PlayerMovementHandler) HandleWebSocketConnection(c echo.Context) error
 	if tokenString == "" {
 		// Fallback to Authorization header if query param is empty
 		authHeader := c.Request().Header.Get("Authorization")
-		if len(authHeader) > 7 && authHeader[:7] != "Bearer " {
+		if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
 			tokenString = authHeader[7:]
 		}
 	}
@@ -82,103 +127,173 @@ func (h *PlayerMovementHandler) HandleWebSocketConnection(c echo.Context) error
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to upgrade to WebSocket")
 	}
 
-	client := &service.Client{
-		UserID:   claims.UserID,
-		Username: claims.Username,
-		Conn:     conn,
-		Send:     make(chan []byte, 256), // Буферизованный канал для отправки
-	}
+	codec := protocol.CodecFor(conn.Subprotocol())
 
-	h.websocketService.RegisterClient(client)
-	h.logger.Info("WebSocket client connected: %s (ID: %s)", client.Username, client.UserID)
+	// A client that lost its connection may pick up its session where it left off
+	var client *service.Client
+	resumed := false
+	if token := c.QueryParam("resume"); token != "" {
+		client, resumed = h.websocketService.ResumeClient(token, claims.UserID, conn, codec)
+		if !resumed {
+			h.logger.Info("WebSocket: session for %s could not be resumed, starting a new one", claims.Username)
+		}
+	}
 
-	// Send initial state to the newly connected client
-	allLocations, err := h.playerService.GetAllPlayerLocations()
-	if err != nil {
-		h.logger.Error("Failed to get all player locations for initial state: %v", err)
+	if resumed {
+		h.logger.Info("WebSocket client reconnected: %s (ID: %s, protocol: %s)", client.Username, client.UserID, codec.Name())
 	} else {
-		h.websocketService.SendAllPlayerLocations(client, allLocations)
+		client, err = service.NewClient(claims.UserID, claims.Username, claims.Roles, conn, codec)
+		if err != nil {
+			h.logger.Error("WebSocket: failed to create session for %s: %v", claims.Username, err)
+			conn.Close()
+			return nil
+		}
+
+		if err := h.websocketService.RegisterClient(client); err != nil {
+			h.logger.Info("WebSocket: refused connection for %s: %v", claims.Username, err)
+			closeMessage := websocket.FormatCloseMessage(protocol.CloseSessionRejected, protocol.CloseReason(protocol.CloseSessionRejected))
+			conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(time.Second))
+			conn.Close()
+			return nil
+		}
+		h.websocketService.SendSessionInfo(client)
+		h.combatService.SendStats(client)
+		h.logger.Info("WebSocket client connected: %s (ID: %s, protocol: %s)", client.Username, client.UserID, codec.Name())
+		h.gameLoopService.PlacePlayer(client.UserID, client.Username, time.Now())
+
+		// Send initial state to the newly connected client
+		allLocations, err := h.playerService.GetAllPlayerLocations()
+		if err != nil {
+			h.logger.Error("Failed to get all player locations for initial state: %v", err)
+		} else {
+			h.websocketService.SendAllPlayerLocations(client, allLocations)
+			h.pushInitialChunks(client, allLocations)
+		}
 	}
 
+	// The pumps own this connection and its send channel; after a resume the
+	// session moves on to a new pair while these goroutines wind down.
+	send := client.Send
 	// Goroutine for reading messages from the client
-	go h.readPump(client)
+	go h.readPump(client, conn, codec)
 	// Goroutine for writing messages to the client
-	go h.writePump(client)
+	go h.writePump(client, conn, codec, send)
 
 	return nil // Connection is handled by goroutines
 }
 
+// pushInitialChunks streams the terrain around the player's location.
+func (h *PlayerMovementHandler) pushInitialChunks(client *service.Client, locations []domain.Location) {
+	for _, loc := range locations {
+		if loc.PlayerID == client.UserID {
+			h.websocketService.PushChunks(client.UserID, h.terrainService.ChunksNear(loc.X, loc.Z))
+			return
+		}
+	}
+}
+
 // readPump pumps messages from the websocket connection to the broadcast channel.
-func (h *PlayerMovementHandler) readPump(client *service.Client) {
+func (h *PlayerMovementHandler) readPump(client *service.Client, conn *websocket.Conn, codec protocol.Codec) {
+	var readErr error
 	defer func() {
-		h.websocketService.UnregisterClient(client)
-		client.Conn.Close()
+		// Anything but a clean close may be a network drop the client will recover from
+		resumable := !websocket.IsCloseError(readErr, websocket.CloseNormalClosure)
+		h.websocketService.DisconnectClient(client, conn, resumable)
+		conn.Close()
 		h.logger.Info("WebSocket client disconnected (readPump): %s", client.Username)
 	}()
 
-	client.Conn.SetReadLimit(512)
-	client.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
-	client.Conn.SetPongHandler(func(string) error {
-		client.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	conn.SetReadLimit(maxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 		return nil
 	})
 
 	for {
-		_, message, err := client.Conn.ReadMessage()
+		_, message, err := conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				h.logger.Error("WebSocket read error for client %s: %v", client.Username, err)
 			}
+			readErr = err
 			break
 		}
 
-		var moveMsg PlayerMovementMessage
-		if err := json.Unmarshal(message, &moveMsg); err != nil {
-			h.logger.Error("Failed to unmarshal player movement message from client %s: %v", client.Username, err)
+		msg, err := protocol.DecodeEnvelope(codec, message)
+		if err != nil {
+			h.sendError(client, 0, protocol.NewError(protocol.ErrCodeBadRequest, "malformed message envelope"))
+			continue
+		}
+		if msg.Version != protocol.Version {
+			h.sendError(client, msg.Seq, protocol.NewError(protocol.ErrCodeUnsupportedVersion, "unsupported protocol version %d, expected %d", msg.Version, protocol.Version))
 			continue
 		}
 
-		if moveMsg.Type == "move" {
-			loc, err := h.playerService.UpdatePlayerLocation(client.UserID, moveMsg.X, moveMsg.Y, moveMsg.Z)
-			if err != nil {
-				h.logger.Error("Failed to update player location for client %s: %v", client.Username, err)
-				// Optionally send an error message back to the client
-				// client.Send <- []byte(`{"type": "error", "message": "Failed to update location"}`)
-				continue
-			}
-			// Notify all other players about the movement
-			h.websocketService.NotifyPlayerLocationChange(client.UserID, client.Username, loc)
-		} else {
-			h.logger.Info("Received unknown message type '%s' from client %s", moveMsg.Type, client.Username)
+		if err := h.registry.Dispatch(client, msg); err != nil {
+			h.sendError(client, msg.Seq, err)
 		}
 	}
 }
 
+// sendError reports a failed client message with an error frame referencing its seq.
+func (h *PlayerMovementHandler) sendError(client *service.Client, seq uint64, err error) {
+	var protoErr *protocol.Error
+	if !errors.As(err, &protoErr) {
+		h.logger.Error("Failed to handle message #%d from client %s: %v", seq, client.Username, err)
+		protoErr = protocol.NewError(protocol.ErrCodeInternal, "internal server error")
+	}
+	h.websocketService.SendToClient(client, protocol.NewErrorFrame(seq, protoErr))
+}
+
+// handleSnapshotAck makes the acknowledged snapshot the baseline for future deltas.
+func (h *PlayerMovementHandler) handleSnapshotAck(client *service.Client, msg *protocol.Envelope) error {
+	var payload SnapshotAckPayload
+	if err := msg.DecodePayload(&payload); err != nil {
+		return err
+	}
+	if !h.websocketService.AckSnapshot(client, payload.ID) {
+		return protocol.NewError(protocol.ErrCodeBadRequest, "unknown or outdated snapshot %d", payload.ID)
+	}
+	return nil
+}
+
+// handleSnapshotResync sends a full snapshot to a client that lost track of its baseline.
+func (h *PlayerMovementHandler) handleSnapshotResync(client *service.Client, msg *protocol.Envelope) error {
+	h.websocketService.ResyncSnapshot(client)
+	return nil
+}
+
 // writePump pumps messages from the WebSocketService's send channel to the websocket connection.
-func (h *PlayerMovementHandler) writePump(client *service.Client) {
+func (h *PlayerMovementHandler) writePump(client *service.Client, conn *websocket.Conn, codec protocol.Codec, send <-chan []byte) {
 	ticker := time.NewTicker(50 * time.Second)
 	defer func() {
 		ticker.Stop()
-		client.Conn.Close()
+		conn.Close()
 		h.logger.Info("WebSocket client disconnected (writePump): %s", client.Username)
 	}()
 
+	messageType := websocket.TextMessage
+	if codec.Binary() {
+		messageType = websocket.BinaryMessage
+	}
+
 	for {
 		select {
-		case message, ok := <-client.Send:
+		case message, ok := <-send:
 			if !ok {
-				// The WebSocketService closed the channel.
-				client.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+				// The WebSocketService closed the channel; tell the client why if the session was taken over.
+				conn.WriteMessage(websocket.CloseMessage, client.CloseMessage())
 				return
 			}
-			err := client.Conn.WriteMessage(websocket.TextMessage, message)
+			err := conn.WriteMessage(messageType, message)
 			if err != nil {
 				h.logger.Error("Failed to write message to client %s: %v", client.Username, err)
 				return
 			}
 		case <-ticker.C:
 			// Send a ping message to keep the connection alive
-			if err := client.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				h.logger.Error("Failed to send ping to client %s: %v", client.Username, err)
 				return
 			}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"anarchy-core/internal/protocol"
+	"anarchy-core/internal/service"
+	"anarchy-core/internal/util"
+)
+
+// WorldHandler serves the world terrain to WebSocket clients.
+type WorldHandler struct {
+	terrainService   *service.TerrainService
+	websocketService *service.WebSocketService
+	logger           *util.Logger
+}
+
+// NewWorldHandler creates a new WorldHandler.
+func NewWorldHandler(terrainService *service.TerrainService, websocketService *service.WebSocketService, logger *util.Logger) *WorldHandler {
+	return &WorldHandler{
+		terrainService:   terrainService,
+		websocketService: websocketService,
+		logger:           logger,
+	}
+}
+
+// ChunkRequest names a chunk and the version the client has cached, 0 if none.
+type ChunkRequest struct {
+	X       int    `json:"x"`
+	Z       int    `json:"z"`
+	Version uint32 `json:"version"`
+}
+
+// ChunkRequestPayload is the payload of a "chunk_request" message.
+type ChunkRequestPayload struct {
+	Chunks []ChunkRequest `json:"chunks"`
+}
+
+// RegisterMessages registers the world message handlers.
+func (h *WorldHandler) RegisterMessages(registry *MessageRegistry) {
+	registry.Register("chunk_request", h.handleChunkRequest)
+}
+
+// handleChunkRequest answers each requested chunk with its contents, or with
+// not_modified if the client's cached version is current.
+func (h *WorldHandler) handleChunkRequest(client *service.Client, msg *protocol.Envelope) error {
+	var payload ChunkRequestPayload
+	if err := msg.DecodePayload(&payload); err != nil {
+		return err
+	}
+	if len(payload.Chunks) == 0 || len(payload.Chunks) > service.MaxChunkRequest {
+		return protocol.NewError(protocol.ErrCodeBadRequest, "chunk_request must name between 1 and %d chunks", service.MaxChunkRequest)
+	}
+
+	for _, req := range payload.Chunks {
+		chunk := h.terrainService.Chunk(service.ChunkKey{X: req.X, Z: req.Z})
+		h.websocketService.SendChunk(client, chunk, req.Version)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"anarchy-core/internal/auth"
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireAuth only lets through requests with a valid token in the Authorization
+// header, storing the user's ID, name and roles in the context.
+func RequireAuth(jwtManager *auth.JWTManager, logger *util.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authHeader := c.Request().Header.Get("Authorization")
+			if authHeader == "" || len(authHeader) < 7 || authHeader[:7] != "Bearer " {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Missing or invalid Authorization header")
+			}
+			tokenString := authHeader[7:]
+
+			claims, err := jwtManager.ValidateToken(tokenString)
+			if err != nil {
+				logger.Error("JWT validation failed for protected route: %v", err)
+				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired token")
+			}
+			// Store user info in context for later use
+			c.Set("userID", claims.UserID)
+			c.Set("username", claims.Username)
+			c.Set("roles", claims.Roles)
+			return next(c)
+		}
+	}
+}
+
+// RequireRole only lets through authenticated users holding at least one of roles.
+// It must run after RequireAuth.
+func RequireRole(logger *util.Logger, roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			held, _ := c.Get("roles").([]string)
+			if !domain.HasAnyRole(held, roles...) {
+				username, _ := c.Get("username").(string)
+				logger.Error("Access to %s denied for user %q without role %s", c.Path(), username, strings.Join(roles, " or "))
+				return echo.NewHTTPError(http.StatusForbidden, "Insufficient role")
+			}
+			return next(c)
+		}
+	}
+}
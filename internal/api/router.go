@@ -5,6 +5,7 @@ import (
 
 	"anarchy-core/internal/api/handler"
 	"anarchy-core/internal/auth"
+	"anarchy-core/internal/domain"
 	"anarchy-core/internal/util"
 
 	"github.com/go-playground/validator/v10"
@@ -27,6 +28,10 @@ func SetupRouter(
 	e *echo.Echo,
 	authHandler *handler.AuthHandler,
 	playerMovementHandler *handler.PlayerMovementHandler,
+	adminHandler *handler.AdminHandler,
+	inventoryHandler *handler.InventoryHandler,
+	catalogHandler *handler.CatalogHandler,
+	contentHandler *handler.ContentHandler,
 	jwtManager *auth.JWTManager,
 	logger *util.Logger,
 ) {
@@ -38,7 +43,7 @@ func SetupRouter(
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"*"}, // In production, specify concrete domains
-		AllowMethods: []string{http.MethodGet, http.MethodPost},
+		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
 		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
 	}))
 
@@ -51,6 +56,8 @@ func SetupRouter(
 	authGroup := e.Group("/auth")
 	authGroup.POST("/register", authHandler.RegisterUser)
 	authGroup.POST("/login", authHandler.LoginUser)
+	authGroup.POST("/refresh", authHandler.RefreshTokens)
+	authGroup.POST("/logout", authHandler.Logout)
 
 	// WebSocket route (authenticated via query param or header)
 	// The authentication logic is handled inside the WebSocket handler itself
@@ -59,31 +66,47 @@ func SetupRouter(
 	// Example of a protected HTTP route (requires JWT token in Authorization header)
 	// This shows how to protect regular HTTP endpoints if you add more later.
 	// For this project, player movement is via WebSocket, so this is just an example.
-	protectedGroup := e.Group("/api")
-	protectedGroup.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
-		return func(c echo.Context) error {
-			authHeader := c.Request().Header.Get("Authorization")
-			if authHeader == "" || len(authHeader) < 7 || authHeader[:7] != "Bearer " {
-				return echo.NewHTTPError(http.StatusUnauthorized, "Missing or invalid Authorization header")
-			}
-			tokenString := authHeader[7:]
-
-			claims, err := jwtManager.ValidateToken(tokenString)
-			if err != nil {
-				logger.Error("JWT validation failed for protected route: %v", err)
-				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired token")
-			}
-			// Store user info in context for later use
-			c.Set("userID", claims.UserID)
-			c.Set("username", claims.Username)
-			return next(c)
-		}
-	})
+	protectedGroup := e.Group("/api", RequireAuth(jwtManager, logger))
 
 	// Example protected route (not strictly needed for this project's core logic)
 	protectedGroup.GET("/profile", func(c echo.Context) error {
 		userID := c.Get("userID").(string)
 		username := c.Get("username").(string)
-		return c.JSON(http.StatusOK, echo.Map{"message": "Welcome to your profile!", "userID": userID, "username": username})
+		roles, _ := c.Get("roles").([]string)
+		return c.JSON(http.StatusOK, echo.Map{"message": "Welcome to your profile!", "userID": userID, "username": username, "roles": roles})
 	})
+
+	protectedGroup.GET("/inventory", inventoryHandler.GetInventory)
+
+	// Read-only catalog of game content definitions
+	catalogGroup := protectedGroup.Group("/catalog")
+	catalogGroup.GET("/objects", catalogHandler.GetObjects)
+	catalogGroup.GET("/items", catalogHandler.GetItems)
+	catalogGroup.GET("/entities", catalogHandler.GetEntities)
+
+	// Moderation routes, open to moderators and administrators
+	adminGroup := protectedGroup.Group("/admin")
+	adminGroup.GET("/suspicion", adminHandler.GetSuspicions, RequireRole(logger, domain.RoleModerator, domain.RoleAdmin))
+
+	// Role management, restricted to administrators
+	usersGroup := adminGroup.Group("/users", RequireRole(logger, domain.RoleAdmin))
+	usersGroup.GET("/:username/roles", adminHandler.GetUserRoles)
+	usersGroup.PUT("/:username/roles/:role", adminHandler.GrantRole)
+	usersGroup.DELETE("/:username/roles/:role", adminHandler.RevokeRole)
+
+	// Content management, restricted to administrators; changes are audited and reloaded by running servers
+	contentGroup := adminGroup.Group("/content", RequireRole(logger, domain.RoleAdmin))
+	contentGroup.GET("/objects", contentHandler.GetObjectLists)
+	contentGroup.POST("/objects", contentHandler.CreateObjectList)
+	contentGroup.PUT("/objects/:id", contentHandler.UpdateObjectList)
+	contentGroup.DELETE("/objects/:id", contentHandler.DeleteObjectList)
+	contentGroup.GET("/items", contentHandler.GetItemLists)
+	contentGroup.POST("/items", contentHandler.CreateItemList)
+	contentGroup.PUT("/items/:id", contentHandler.UpdateItemList)
+	contentGroup.DELETE("/items/:id", contentHandler.DeleteItemList)
+	contentGroup.GET("/entities", contentHandler.GetEntityLists)
+	contentGroup.POST("/entities", contentHandler.CreateEntityList)
+	contentGroup.PUT("/entities/:id", contentHandler.UpdateEntityList)
+	contentGroup.DELETE("/entities/:id", contentHandler.DeleteEntityList)
+	adminGroup.GET("/audit", contentHandler.GetAuditLog, RequireRole(logger, domain.RoleAdmin))
 }
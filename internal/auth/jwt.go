@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"anarchy-core/internal/domain"
 	"anarchy-core/internal/util"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -12,28 +13,41 @@ import (
 
 // Claims defines the JWT claims structure.
 type Claims struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
+	UserID   string   `json:"user_id"`
+	Username string   `json:"username"`
+	Roles    []string `json:"roles,omitempty"` // Роли пользователя на момент выдачи токена
 	jwt.RegisteredClaims
 }
 
+// HasRole reports whether the token grants at least one of roles.
+func (c *Claims) HasRole(roles ...string) bool {
+	return domain.HasAnyRole(c.Roles, roles...)
+}
+
 // JWTManager handles JWT token creation and validation.
 type JWTManager struct {
 	secretKey []byte
+	accessTTL time.Duration // Время жизни выдаваемых токенов
+}
+
+// NewJWTManager creates a new JWTManager issuing access tokens valid for accessTTL.
+func NewJWTManager(secretKey string, accessTTL time.Duration) *JWTManager {
+	return &JWTManager{secretKey: []byte(secretKey), accessTTL: accessTTL}
 }
 
-// NewJWTManager creates a new JWTManager.
-func NewJWTManager(secretKey string) *JWTManager {
-	return &JWTManager{secretKey: []byte(secretKey)}
+// AccessTTL returns how long the access tokens issued by j are valid.
+func (j *JWTManager) AccessTTL() time.Duration {
+	return j.accessTTL
 }
 
-// GenerateToken generates a new JWT token for a given user.
-func (j *JWTManager) GenerateToken(userID, username string) (string, error) {
+// GenerateToken generates a new short-lived access token for a given user and its roles.
+func (j *JWTManager) GenerateToken(userID, username string, roles []string) (string, error) {
 	claims := Claims{
 		UserID:   userID,
 		Username: username,
+		Roles:    roles,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // Token valid for 24 hours
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.accessTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// refreshTokenBytes is the number of random bytes in a refresh token.
+const refreshTokenBytes = 32
+
+// NewRefreshToken generates an opaque refresh token and the hash under which it is stored.
+func NewRefreshToken() (token, hash string, err error) {
+	var b [refreshTokenBytes]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(b[:])
+	return token, HashRefreshToken(token), nil
+}
+
+// HashRefreshToken returns the hex-encoded SHA-256 of a refresh token. Refresh tokens
+// carry enough entropy that a fast unsalted hash is sufficient to protect them at rest.
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -2,16 +2,55 @@ package config
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv" // Для загрузки переменных из .env файла
 )
 
+// Supported storage backends.
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+// Policies for a login to an account that already has an active session.
+const (
+	SessionPolicyKick   = "kick"   // the new connection replaces the old one
+	SessionPolicyReject = "reject" // the new connection is refused
+)
+
 // Config struct holds all application configurations.
 type Config struct {
-	AppPort      string // Порт, на котором будет работать приложение
-	DatabaseURL  string // URL для подключения к PostgreSQL
-	JWTSecretKey string // Секретный ключ для подписи JWT токенов
+	AppPort                string        // Порт, на котором будет работать приложение
+	Storage                string        // Хранилище данных: postgres или memory
+	DatabaseURL            string        // URL для подключения к PostgreSQL
+	JWTSecretKey           string        // Секретный ключ для подписи JWT токенов
+	AccessTokenTTL         time.Duration // Время жизни access-токена
+	RefreshTokenTTL        time.Duration // Время жизни refresh-токена, продлевается при каждом обновлении
+	TickRate               int           // Частота тиков игрового цикла (в герцах)
+	ViewDistance           float64       // Радиус зоны интереса клиента по X/Z
+	SessionGracePeriod     time.Duration // Сколько сессия ждёт переподключения после обрыва соединения
+	SessionPolicy          string        // Что делать при повторном входе: kick или reject
+	MaxPlayerSpeed         float64       // Максимальная скорость игрока (единиц в секунду)
+	SuspicionKickThreshold float64       // Порог подозрительности для автоматического отключения, 0 — выключено
+	TerrainCellSize        float64       // Расстояние между точками карты высот в единицах мира
+	ChunkSize              int           // Количество точек карты высот по стороне чанка
+	ChunkRadius            int           // Радиус в чанках, которые отправляются вокруг игрока
+	AdminUsers             []string      // Пользователи, получающие роль admin при каждом входе
+	PlayerMaxHealth        float64       // Максимальное здоровье игрока
+	PlayerDamage           float64       // Урон одной атаки игрока
+	PlayerAttackRange      float64       // Дальность атаки игрока по X/Z
+	PlayerAttackCooldown   time.Duration // Минимальный интервал между атаками игрока
+	PlayerRespawnDelay     time.Duration // Время от смерти игрока до возрождения
+	SpawnPoint             *[2]float64   // Точка появления игроков по X/Z, nil — центр карты высот
+	InventorySlots         int           // Количество слотов инвентаря
+	InventoryStackSize     int           // Максимальный размер стопки предметов в слоте
+	PickupRange            float64       // Дальность, с которой игрок может подобрать предмет
+	ContainerRange         float64       // Дальность, с которой игрок может открыть контейнер
 }
 
 // LoadConfig loads configuration from environment variables.
@@ -21,21 +60,164 @@ func LoadConfig() (*Config, error) {
 	godotenv.Load()
 
 	cfg := &Config{
-		AppPort:      os.Getenv("APP_PORT"),
-		DatabaseURL:  os.Getenv("DATABASE_URL"),
-		JWTSecretKey: os.Getenv("JWT_SECRET_KEY"),
+		AppPort:       os.Getenv("APP_PORT"),
+		Storage:       os.Getenv("STORAGE"),
+		DatabaseURL:   os.Getenv("DATABASE_URL"),
+		JWTSecretKey:  os.Getenv("JWT_SECRET_KEY"),
+		SessionPolicy: os.Getenv("SESSION_POLICY"),
 	}
 
 	// Validate required configurations
 	if cfg.AppPort == "" {
 		cfg.AppPort = "8080" // Default port if not set
 	}
-	if cfg.DatabaseURL == "" {
+	switch cfg.Storage {
+	case "":
+		cfg.Storage = StoragePostgres // Default storage if not set
+	case StoragePostgres, StorageMemory:
+	default:
+		return nil, fmt.Errorf("STORAGE must be %q or %q, got %q", StoragePostgres, StorageMemory, cfg.Storage)
+	}
+	if cfg.Storage == StoragePostgres && cfg.DatabaseURL == "" {
 		return nil, fmt.Errorf("DATABASE_URL environment variable is not set")
 	}
 	if cfg.JWTSecretKey == "" {
 		return nil, fmt.Errorf("JWT_SECRET_KEY environment variable is not set")
 	}
+	switch cfg.SessionPolicy {
+	case "":
+		cfg.SessionPolicy = SessionPolicyKick
+	case SessionPolicyKick, SessionPolicyReject:
+	default:
+		return nil, fmt.Errorf("SESSION_POLICY must be %q or %q, got %q", SessionPolicyKick, SessionPolicyReject, cfg.SessionPolicy)
+	}
+
+	var err error
+	if cfg.AccessTokenTTL, err = durationEnv("ACCESS_TOKEN_TTL", 15*time.Minute, time.Minute, 24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.RefreshTokenTTL, err = durationEnv("REFRESH_TOKEN_TTL", 30*24*time.Hour, time.Hour, 365*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.TickRate, err = intEnv("TICK_RATE", 20, 1, 1000); err != nil {
+		return nil, err
+	}
+	if cfg.ViewDistance, err = floatEnv("VIEW_DISTANCE", 100, 1, 100000); err != nil {
+		return nil, err
+	}
+	if cfg.SessionGracePeriod, err = durationEnv("SESSION_GRACE_PERIOD", 10*time.Second, 0, time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.MaxPlayerSpeed, err = floatEnv("MAX_PLAYER_SPEED", 10, 0.1, 10000); err != nil {
+		return nil, err
+	}
+	if cfg.SuspicionKickThreshold, err = floatEnv("SUSPICION_KICK_THRESHOLD", 20, 0, 1000); err != nil {
+		return nil, err
+	}
+	if cfg.TerrainCellSize, err = floatEnv("TERRAIN_CELL_SIZE", 1, 0.01, 10000); err != nil {
+		return nil, err
+	}
+	if cfg.ChunkSize, err = intEnv("CHUNK_SIZE", 32, 4, 256); err != nil {
+		return nil, err
+	}
+	if cfg.ChunkRadius, err = intEnv("CHUNK_RADIUS", 2, 0, 16); err != nil {
+		return nil, err
+	}
+	if cfg.PlayerMaxHealth, err = floatEnv("PLAYER_MAX_HEALTH", 100, 1, 1e6); err != nil {
+		return nil, err
+	}
+	if cfg.PlayerDamage, err = floatEnv("PLAYER_DAMAGE", 10, 0, 1e6); err != nil {
+		return nil, err
+	}
+	if cfg.PlayerAttackRange, err = floatEnv("PLAYER_ATTACK_RANGE", 3, 0.1, 1000); err != nil {
+		return nil, err
+	}
+	if cfg.PlayerAttackCooldown, err = durationEnv("PLAYER_ATTACK_COOLDOWN", 500*time.Millisecond, 0, time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.PlayerRespawnDelay, err = durationEnv("PLAYER_RESPAWN_DELAY", 5*time.Second, 0, time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.InventorySlots, err = intEnv("INVENTORY_SLOTS", 24, 1, 1000); err != nil {
+		return nil, err
+	}
+	if cfg.InventoryStackSize, err = intEnv("INVENTORY_STACK_SIZE", 64, 1, 100000); err != nil {
+		return nil, err
+	}
+	if cfg.PickupRange, err = floatEnv("PICKUP_RANGE", 3, 0.1, 1000); err != nil {
+		return nil, err
+	}
+	if cfg.ContainerRange, err = floatEnv("CONTAINER_RANGE", 3, 0.1, 1000); err != nil {
+		return nil, err
+	}
+	if cfg.SpawnPoint, err = pointEnv("SPAWN_POINT"); err != nil {
+		return nil, err
+	}
+	for _, name := range strings.Split(os.Getenv("ADMIN_USERS"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			cfg.AdminUsers = append(cfg.AdminUsers, name)
+		}
+	}
 
 	return cfg, nil
 }
+
+// intEnv reads an integer environment variable, falling back to def if it is not set.
+func intEnv(key string, def, min, max int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("%s must be an integer between %d and %d, got %q", key, min, max, v)
+	}
+	return n, nil
+}
+
+// floatEnv reads a floating point environment variable, falling back to def if it is not set.
+func floatEnv(key string, def, min, max float64) (float64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(f) || f < min || f > max {
+		return 0, fmt.Errorf("%s must be a number between %g and %g, got %q", key, min, max, v)
+	}
+	return f, nil
+}
+
+// durationEnv reads a duration environment variable such as "10s", falling back to def if it is not set.
+func durationEnv(key string, def, min, max time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < min || d > max {
+		return 0, fmt.Errorf("%s must be a duration between %s and %s, got %q", key, min, max, v)
+	}
+	return d, nil
+}
+
+// pointEnv reads a point on the X/Z plane given as "x,z", returning nil if it is not set.
+func pointEnv(key string) (*[2]float64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil, nil
+	}
+	parts := strings.Split(v, ",")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("%s must be a point \"x,z\", got %q", key, v)
+	}
+	var point [2]float64
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("%s must be a point \"x,z\", got %q", key, v)
+		}
+		point[i] = f
+	}
+	return &point, nil
+}
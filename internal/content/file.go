@@ -0,0 +1,129 @@
+package content
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"anarchy-core/internal/domain"
+)
+
+// Content file format identifiers.
+const (
+	FileFormat  = "anarchy-content"
+	FileVersion = 1
+)
+
+// Names of the files of a content directory, one per definition table.
+const (
+	ObjectListsFile = "object_lists.json"
+	ItemListsFile   = "item_lists.json"
+	EntityListsFile = "entity_lists.json"
+)
+
+// file is the exchange format of one definition table. Definitions are ordered by
+// ID and written one field per line, so changes show up clearly in version control.
+type file[T any] struct {
+	Format      string `json:"format"`
+	Version     int    `json:"version"`
+	Definitions []T    `json:"definitions"`
+}
+
+// itemRecord is an item definition as stored in a content file. The object the
+// definition is bound to is a storage detail, so only its ObjectList is kept.
+type itemRecord struct {
+	ID           int  `json:"id"`
+	ObjectListID int  `json:"object_list_id"`
+	Rarity       int  `json:"rarity"`
+	IsStackable  bool `json:"is_stackable"`
+}
+
+// newItemRecord converts an item definition to its file form.
+func newItemRecord(definition domain.ItemDefinition) itemRecord {
+	return itemRecord{
+		ID:           definition.ID,
+		ObjectListID: definition.ObjectListID,
+		Rarity:       definition.Rarity,
+		IsStackable:  definition.IsStackable,
+	}
+}
+
+// WriteDir writes bundle to dir, creating it if needed and replacing the content files in it.
+func WriteDir(dir string, bundle *domain.ContentBundle) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create content directory: %w", err)
+	}
+	items := make([]itemRecord, 0, len(bundle.ItemLists))
+	for _, definition := range bundle.ItemLists {
+		items = append(items, newItemRecord(definition))
+	}
+	if err := writeFile(filepath.Join(dir, ObjectListsFile), bundle.ObjectLists); err != nil {
+		return err
+	}
+	if err := writeFile(filepath.Join(dir, ItemListsFile), items); err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(dir, EntityListsFile), bundle.EntityLists)
+}
+
+// ReadDir reads the bundle written by WriteDir from dir. Unknown fields are rejected,
+// so a misspelt attribute is not silently dropped.
+func ReadDir(dir string) (*domain.ContentBundle, error) {
+	bundle := &domain.ContentBundle{}
+	var err error
+	if bundle.ObjectLists, err = readFile[domain.ObjectList](filepath.Join(dir, ObjectListsFile)); err != nil {
+		return nil, err
+	}
+	items, err := readFile[itemRecord](filepath.Join(dir, ItemListsFile))
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		bundle.ItemLists = append(bundle.ItemLists, domain.ItemDefinition{
+			ItemList:     domain.ItemList{ID: item.ID, Rarity: item.Rarity, IsStackable: item.IsStackable},
+			ObjectListID: item.ObjectListID,
+		})
+	}
+	if bundle.EntityLists, err = readFile[domain.EntityList](filepath.Join(dir, EntityListsFile)); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// writeFile writes definitions to path as an indented content file.
+func writeFile[T any](path string, definitions []T) error {
+	if definitions == nil {
+		definitions = []T{}
+	}
+	data, err := json.MarshalIndent(file[T]{Format: FileFormat, Version: FileVersion, Definitions: definitions}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// readFile reads and checks a content file written by writeFile.
+func readFile[T any](path string) ([]T, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content file: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var f file[T]
+	if err := decoder.Decode(&f); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	if f.Format != FileFormat {
+		return nil, fmt.Errorf("%s is not a content file: format %q", filepath.Base(path), f.Format)
+	}
+	if f.Version != FileVersion {
+		return nil, fmt.Errorf("%s has unsupported version %d, expected %d", filepath.Base(path), f.Version, FileVersion)
+	}
+	return f.Definitions, nil
+}
@@ -0,0 +1,206 @@
+// Package content moves the object, item and entity definitions of the game between
+// the database and directories of content files, so content can be versioned in git.
+// An import is planned as a list of changes against the definitions in the database,
+// which can be reviewed before it is applied in a single transaction.
+package content
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/service"
+)
+
+// Validate checks every definition of bundle with the rules of the admin content API,
+// and that IDs are unique and references point at ObjectLists in the bundle.
+// All problems are reported, not only the first one.
+func Validate(bundle *domain.ContentBundle) error {
+	var errs []error
+	objectLists := make(map[int]bool, len(bundle.ObjectLists))
+	for _, objectList := range bundle.ObjectLists {
+		if err := checkID(objectLists, objectList.ID); err != nil {
+			errs = append(errs, fmt.Errorf("%s: object %d: %w", ObjectListsFile, objectList.ID, err))
+		}
+		if err := service.ValidateObjectList(objectList); err != nil {
+			errs = append(errs, fmt.Errorf("%s: object %d: %w", ObjectListsFile, objectList.ID, err))
+		}
+	}
+
+	reference := func(id int) error {
+		if id != 0 && !objectLists[id] {
+			return fmt.Errorf("object_list_id %d is not in %s", id, ObjectListsFile)
+		}
+		return nil
+	}
+	itemLists := make(map[int]bool, len(bundle.ItemLists))
+	for _, definition := range bundle.ItemLists {
+		for _, err := range []error{
+			checkID(itemLists, definition.ID),
+			service.ValidateItemList(definition.ItemList),
+			reference(definition.ObjectListID),
+		} {
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: item %d: %w", ItemListsFile, definition.ID, err))
+			}
+		}
+	}
+	entityLists := make(map[int]bool, len(bundle.EntityLists))
+	for _, entityList := range bundle.EntityLists {
+		for _, err := range []error{
+			checkID(entityLists, entityList.ID),
+			service.ValidateEntityList(entityList),
+			reference(entityList.ObjectListID),
+		} {
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: entity %d: %w", EntityListsFile, entityList.ID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkID checks that id is positive and not in seen, then adds it.
+func checkID(seen map[int]bool, id int) error {
+	if id <= 0 {
+		return errors.New("id must be positive")
+	}
+	if seen[id] {
+		return errors.New("duplicate id")
+	}
+	seen[id] = true
+	return nil
+}
+
+// Plan lists the changes that turn the current definitions into the desired ones.
+// Definitions missing from desired are deleted if prune is set and kept otherwise.
+// Creations and updates come first, ObjectLists before what refers to them;
+// deletions follow in the reverse order.
+func Plan(current, desired *domain.ContentBundle, prune bool) []domain.ContentChange {
+	objects, objectDeletes := diff("object_list", current.ObjectLists, desired.ObjectLists, prune,
+		func(o domain.ObjectList) int { return o.ID },
+		func(a, b domain.ObjectList) bool { return a == b })
+	items, itemDeletes := diff("item_list", current.ItemLists, desired.ItemLists, prune,
+		func(i domain.ItemDefinition) int { return i.ID },
+		func(a, b domain.ItemDefinition) bool { return newItemRecord(a) == newItemRecord(b) })
+	entities, entityDeletes := diff("entity_list", current.EntityLists, desired.EntityLists, prune,
+		func(e domain.EntityList) int { return e.ID },
+		func(a, b domain.EntityList) bool { return a == b })
+
+	var changes []domain.ContentChange
+	for _, group := range [][]domain.ContentChange{objects, items, entities, entityDeletes, itemDeletes, objectDeletes} {
+		changes = append(changes, group...)
+	}
+	return changes
+}
+
+// Import plans the changes that bring repo in line with desired and returns them.
+// With apply they are made in one transaction recorded under audit; without it the
+// import is a dry run and repo is left untouched.
+func Import(repo domain.ContentRepository, desired *domain.ContentBundle, prune, apply bool, audit *domain.AuditEntry) ([]domain.ContentChange, error) {
+	current, err := repo.GetContent()
+	if err != nil {
+		return nil, err
+	}
+	changes := Plan(current, desired, prune)
+	if !apply || len(changes) == 0 {
+		return changes, nil
+	}
+	return changes, repo.ApplyContentChanges(changes, audit)
+}
+
+// diff compares two tables of definitions ordered by ID, returning the creations
+// and updates, and separately the deletions, that turn current into desired.
+func diff[T any](table string, current, desired []T, prune bool, id func(T) int, equal func(a, b T) bool) (upserts, deletes []domain.ContentChange) {
+	existing := make(map[int]*T, len(current))
+	for i := range current {
+		existing[id(current[i])] = &current[i]
+	}
+	wanted := make(map[int]bool, len(desired))
+	for i := range desired {
+		after := &desired[i]
+		wanted[id(*after)] = true
+		before, ok := existing[id(*after)]
+		switch {
+		case !ok:
+			upserts = append(upserts, domain.ContentChange{Action: domain.AuditActionCreate, Table: table, ID: id(*after), After: after})
+		case !equal(*before, *after):
+			upserts = append(upserts, domain.ContentChange{Action: domain.AuditActionUpdate, Table: table, ID: id(*after), Before: before, After: after})
+		}
+	}
+	if prune {
+		for i := range current {
+			if before := &current[i]; !wanted[id(*before)] {
+				deletes = append(deletes, domain.ContentChange{Action: domain.AuditActionDelete, Table: table, ID: id(*before), Before: before})
+			}
+		}
+	}
+	sort.Slice(upserts, func(i, j int) bool { return upserts[i].ID < upserts[j].ID })
+	// Deletions run from the highest ID down, mirroring creation order
+	sort.Slice(deletes, func(i, j int) bool { return deletes[i].ID > deletes[j].ID })
+	return upserts, deletes
+}
+
+// Describe formats a change for review: the new definition of a creation, the
+// changed attributes of an update and the removed definition of a deletion.
+func Describe(change domain.ContentChange) string {
+	before, after := fields(change.Before), fields(change.After)
+	var b strings.Builder
+	switch change.Action {
+	case domain.AuditActionCreate:
+		fmt.Fprintf(&b, "+ create %s %d", change.Table, change.ID)
+		writeFields(&b, after, "+")
+	case domain.AuditActionDelete:
+		fmt.Fprintf(&b, "- delete %s %d", change.Table, change.ID)
+		writeFields(&b, before, "-")
+	default:
+		fmt.Fprintf(&b, "~ update %s %d", change.Table, change.ID)
+		for _, name := range sortedKeys(after) {
+			if before[name] != after[name] {
+				fmt.Fprintf(&b, "\n    %s: %s -> %s", name, before[name], after[name])
+			}
+		}
+	}
+	return b.String()
+}
+
+// fields encodes each attribute of a definition as JSON, keyed by its JSON name.
+// Item definitions are described by their file form.
+func fields(record interface{}) map[string]string {
+	if record == nil {
+		return nil
+	}
+	if definition, ok := record.(*domain.ItemDefinition); ok {
+		record = newItemRecord(*definition)
+	}
+	data, _ := json.Marshal(record)
+	var raw map[string]json.RawMessage
+	json.Unmarshal(data, &raw)
+	result := make(map[string]string, len(raw))
+	for name, value := range raw {
+		result[name] = string(value)
+	}
+	return result
+}
+
+// writeFields writes every attribute but the ID on its own line, marked with prefix.
+func writeFields(b *strings.Builder, fields map[string]string, prefix string) {
+	for _, name := range sortedKeys(fields) {
+		if name != "id" {
+			fmt.Fprintf(b, "\n  %s %s: %s", prefix, name, fields[name])
+		}
+	}
+}
+
+// sortedKeys returns the keys of m in lexical order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
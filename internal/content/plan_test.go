@@ -0,0 +1,356 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/repository/memory"
+	"anarchy-core/internal/util"
+)
+
+// bundle returns a small valid content bundle as read from content files.
+func bundle() *domain.ContentBundle {
+	return &domain.ContentBundle{
+		ObjectLists: []domain.ObjectList{
+			{ID: 1, Name: "Sword", Image: "sword.png", Description: "A sword"},
+			{ID: 2, Name: "Chest", Image: "chest.png", Description: "A chest"},
+		},
+		ItemLists: []domain.ItemDefinition{
+			{ItemList: domain.ItemList{ID: 1, Rarity: 1}, ObjectListID: 1},
+			{ItemList: domain.ItemList{ID: 2, Rarity: 2, IsStackable: true}},
+		},
+		EntityLists: []domain.EntityList{
+			{ID: 1, ObjectListID: 2, MaxHealth: 10, Model: "chest", IsOpen: true},
+		},
+	}
+}
+
+// stored returns bundle as read from the database, with the items bound to objects.
+func stored() *domain.ContentBundle {
+	b := bundle()
+	b.ItemLists[0].ObjectID = 10
+	return b
+}
+
+// summary lists changes as "action table id".
+func summary(changes []domain.ContentChange) []string {
+	var lines []string
+	for _, change := range changes {
+		lines = append(lines, fmt.Sprintf("%s %s %d", change.Action, change.Table, change.ID))
+	}
+	return lines
+}
+
+func TestPlan(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		edit  func(b *domain.ContentBundle) // Правка желаемого содержимого
+		prune bool
+		want  []string
+	}{
+		{
+			name: "unchanged content",
+			edit: func(b *domain.ContentBundle) {},
+		},
+		{
+			name: "object of an item is a storage detail",
+			edit: func(b *domain.ContentBundle) { b.ItemLists[0].ObjectID = 0 },
+		},
+		{
+			name: "updates",
+			edit: func(b *domain.ContentBundle) {
+				b.ObjectLists[0].Name = "Long sword"
+				b.ItemLists[0].ObjectListID = 2
+				b.EntityLists[0].MaxHealth = 20
+			},
+			want: []string{"update object_list 1", "update item_list 1", "update entity_list 1"},
+		},
+		{
+			name: "creations come before what refers to them",
+			edit: func(b *domain.ContentBundle) {
+				b.EntityLists = append(b.EntityLists, domain.EntityList{ID: 2, ObjectListID: 3, MaxHealth: 5})
+				b.ItemLists = append(b.ItemLists, domain.ItemDefinition{ItemList: domain.ItemList{ID: 3}, ObjectListID: 3})
+				b.ObjectLists = append(b.ObjectLists, domain.ObjectList{ID: 3, Name: "Shield"})
+			},
+			want: []string{"create object_list 3", "create item_list 3", "create entity_list 2"},
+		},
+		{
+			name: "missing definitions are kept without prune",
+			edit: func(b *domain.ContentBundle) { b.ItemLists, b.EntityLists = b.ItemLists[:1], nil },
+		},
+		{
+			name:  "prune deletes missing definitions",
+			edit:  func(b *domain.ContentBundle) { b.ItemLists = b.ItemLists[:1] },
+			prune: true,
+			want:  []string{"delete item_list 2"},
+		},
+		{
+			name:  "prune deletes what refers to an object first",
+			edit:  func(b *domain.ContentBundle) { *b = domain.ContentBundle{} },
+			prune: true,
+			want: []string{
+				"delete entity_list 1", "delete item_list 2", "delete item_list 1",
+				"delete object_list 2", "delete object_list 1",
+			},
+		},
+		{
+			name: "updates and deletions together",
+			edit: func(b *domain.ContentBundle) {
+				b.ObjectLists = b.ObjectLists[:1]
+				b.EntityLists[0].ObjectListID = 1
+			},
+			prune: true,
+			want:  []string{"update entity_list 1", "delete object_list 2"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			desired := bundle()
+			tc.edit(desired)
+			changes := Plan(stored(), desired, tc.prune)
+			if got := summary(changes); !slices.Equal(got, tc.want) {
+				t.Fatalf("changes = %q, want %q", got, tc.want)
+			}
+			for _, change := range changes {
+				if (change.Before == nil) != (change.Action == domain.AuditActionCreate) || (change.After == nil) != (change.Action == domain.AuditActionDelete) {
+					t.Errorf("%s %s %d: before %v, after %v", change.Action, change.Table, change.ID, change.Before, change.After)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanUpdateKeepsStoredState(t *testing.T) {
+	desired := bundle()
+	desired.ItemLists[0].Rarity = 5
+	changes := Plan(stored(), desired, false)
+	if len(changes) != 1 {
+		t.Fatalf("changes = %q, want one update", summary(changes))
+	}
+	// Before is what the database held, so a concurrent change can be detected
+	before, after := changes[0].Before.(*domain.ItemDefinition), changes[0].After.(*domain.ItemDefinition)
+	if before.ObjectID != 10 || before.Rarity != 1 || after.Rarity != 5 {
+		t.Errorf("before = %+v, after = %+v", before, after)
+	}
+	want := "~ update item_list 1\n    rarity: 1 -> 5"
+	if got := Describe(changes[0]); got != want {
+		t.Errorf("Describe = %q, want %q", got, want)
+	}
+}
+
+// newRepository returns a content repository holding bundle, and its store.
+func newRepository(t *testing.T) (*memory.ContentRepositoryMemory, *memory.Store) {
+	t.Helper()
+	store := memory.NewStore()
+	repo := memory.NewContentRepositoryMemory(store)
+	if _, err := Import(repo, bundle(), false, true, &domain.AuditEntry{Username: "seed"}); err != nil {
+		t.Fatalf("seeding content: %v", err)
+	}
+	return repo, store
+}
+
+// auditLength returns the number of entries in the audit log of store.
+func auditLength(t *testing.T, store *memory.Store) int {
+	t.Helper()
+	entries, err := memory.NewAuditLogRepositoryMemory(store).GetAuditEntries(1000, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(entries)
+}
+
+func TestImport(t *testing.T) {
+	edit := func(b *domain.ContentBundle) {
+		b.ObjectLists[0].Name = "Long sword"
+		b.ItemLists[1].ObjectListID = 1
+		b.EntityLists = nil
+	}
+	for _, tc := range []struct {
+		name  string
+		edit  func(b *domain.ContentBundle)
+		apply bool
+		want  []string
+	}{
+		{
+			name: "dry run leaves the content alone",
+			edit: edit,
+			want: []string{"update object_list 1", "update item_list 2", "delete entity_list 1"},
+		},
+		{
+			name:  "apply",
+			edit:  edit,
+			apply: true,
+			want:  []string{"update object_list 1", "update item_list 2", "delete entity_list 1"},
+		},
+		{
+			name:  "nothing to apply",
+			edit:  func(b *domain.ContentBundle) {},
+			apply: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, store := newRepository(t)
+			before, _ := repo.GetContent()
+			audited := auditLength(t, store)
+
+			desired := bundle()
+			tc.edit(desired)
+			changes, err := Import(repo, desired, true, tc.apply, &domain.AuditEntry{Username: "importer"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := summary(changes); !slices.Equal(got, tc.want) {
+				t.Fatalf("changes = %q, want %q", got, tc.want)
+			}
+
+			after, _ := repo.GetContent()
+			if !tc.apply {
+				if !reflect.DeepEqual(after, before) || auditLength(t, store) != audited {
+					t.Fatal("dry run changed the content")
+				}
+				return
+			}
+			if remaining := Plan(after, desired, true); len(remaining) != 0 {
+				t.Errorf("changes left after the import: %q", summary(remaining))
+			}
+			if got := auditLength(t, store) - audited; got != len(changes) {
+				t.Errorf("%d audit entries, want one per change", got)
+			}
+		})
+	}
+}
+
+func TestImportDetectsConcurrentChanges(t *testing.T) {
+	admin := &domain.AuditEntry{Username: "admin"}
+	for _, tc := range []struct {
+		name       string
+		edit       func(b *domain.ContentBundle)
+		concurrent func(store *memory.Store) error // Правка через админский API между планом и применением
+		wantErr    error
+	}{
+		{
+			name: "definition updated since the plan",
+			edit: func(b *domain.ContentBundle) { b.ObjectLists[1].Name = "Big chest" },
+			concurrent: func(store *memory.Store) error {
+				return memory.NewObjectListRepositoryMemory(store).UpdateObjectList(&domain.ObjectList{ID: 2, Name: "Crate"}, admin)
+			},
+			wantErr: util.ErrContentChanged,
+		},
+		{
+			name: "item bound to another object since the plan",
+			edit: func(b *domain.ContentBundle) { b.ItemLists[0].Rarity = 3 },
+			concurrent: func(store *memory.Store) error {
+				return memory.NewItemListRepositoryMemory(store).UpdateItemList(&domain.ItemDefinition{ItemList: domain.ItemList{ID: 1, Rarity: 1}, ObjectListID: 2}, admin)
+			},
+			wantErr: util.ErrContentChanged,
+		},
+		{
+			name: "ID taken since the plan",
+			edit: func(b *domain.ContentBundle) {
+				b.ObjectLists = append(b.ObjectLists, domain.ObjectList{ID: 3, Name: "Shield"})
+			},
+			concurrent: func(store *memory.Store) error {
+				return memory.NewObjectListRepositoryMemory(store).CreateObjectList(&domain.ObjectList{Name: "Crate"}, admin)
+			},
+			wantErr: util.ErrContentChanged,
+		},
+		{
+			name: "deleted definition updated since the plan",
+			edit: func(b *domain.ContentBundle) { b.EntityLists = nil },
+			concurrent: func(store *memory.Store) error {
+				return memory.NewEntityListRepositoryMemory(store).UpdateEntityList(&domain.EntityList{ID: 1, ObjectListID: 2, MaxHealth: 99}, admin)
+			},
+			wantErr: util.ErrContentChanged,
+		},
+		{
+			name: "updated definition deleted since the plan",
+			edit: func(b *domain.ContentBundle) { b.EntityLists[0].MaxHealth = 20 },
+			concurrent: func(store *memory.Store) error {
+				return memory.NewEntityListRepositoryMemory(store).DeleteEntityList(1, admin)
+			},
+			wantErr: util.ErrEntityListNotFound,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, store := newRepository(t)
+			desired := bundle()
+			// A change planned before the conflicting one is undone with the rest
+			desired.ItemLists[1].Rarity = 4
+			tc.edit(desired)
+			changes, err := Import(repo, desired, true, false, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tc.concurrent(store); err != nil {
+				t.Fatal(err)
+			}
+			before, _ := repo.GetContent()
+			audited := auditLength(t, store)
+
+			err = repo.ApplyContentChanges(changes, &domain.AuditEntry{Username: "importer"})
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tc.wantErr)
+			}
+			after, _ := repo.GetContent()
+			if !reflect.DeepEqual(after, before) || auditLength(t, store) != audited {
+				t.Error("failed import left changes behind")
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		edit func(b *domain.ContentBundle)
+		want []string // Фрагменты ожидаемых ошибок
+	}{
+		{name: "valid", edit: func(b *domain.ContentBundle) {}},
+		{
+			name: "duplicate ID",
+			edit: func(b *domain.ContentBundle) { b.ObjectLists[1].ID = 1 },
+			want: []string{"object 1: duplicate id", "object_list_id 2 is not in " + ObjectListsFile},
+		},
+		{
+			name: "missing reference",
+			edit: func(b *domain.ContentBundle) { b.ItemLists[1].ObjectListID = 7 },
+			want: []string{"item 2: object_list_id 7 is not in " + ObjectListsFile},
+		},
+		{
+			name: "every problem is reported",
+			edit: func(b *domain.ContentBundle) {
+				b.ObjectLists[0].Name = ""
+				b.ItemLists[0].ID = 0
+				b.EntityLists[0].MaxHealth = 0
+			},
+			want: []string{"object 1: ", "item 0: id must be positive", "entity 1: "},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := bundle()
+			tc.edit(b)
+			err := Validate(b)
+			if len(tc.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Validate succeeded")
+			}
+			if lines := strings.Split(err.Error(), "\n"); len(lines) != len(tc.want) {
+				t.Errorf("errors:\n%v\nwant %d", err, len(tc.want))
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("errors:\n%v\nwant one containing %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"anarchy-core/internal/util"
+
+	"github.com/lib/pq"
+)
+
+// Listener receives PostgreSQL notifications on a channel over its own connection,
+// reconnecting when the connection drops.
+type Listener struct {
+	listener *pq.Listener
+	logger   *util.Logger
+}
+
+// Listen subscribes to notifications on channel.
+func Listen(databaseURL, channel string, logger *util.Logger) (*Listener, error) {
+	listener := pq.NewListener(databaseURL, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Error("PostgreSQL listener on %q: %v", channel, err)
+		}
+	})
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on %q: %w", channel, err)
+	}
+	return &Listener{listener: listener, logger: logger}, nil
+}
+
+// Run calls handle with the payload of every notification until the listener is closed.
+// After a reconnect, when notifications may have been missed, handle is called with an empty payload.
+func (l *Listener) Run(handle func(payload string)) {
+	for notification := range l.listener.Notify {
+		if notification == nil {
+			handle("")
+			continue
+		}
+		handle(notification.Extra)
+	}
+}
+
+// Close stops listening.
+func (l *Listener) Close() error {
+	return l.listener.Close()
+}
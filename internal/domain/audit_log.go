@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// Actions recorded in the audit log.
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// AuditEntry records one change of game content made by an administrator.
+type AuditEntry struct {
+	ID        int64     `db:"id" json:"id"`
+	UserID    string    `db:"user_id" json:"user_id"`       // Пользователь, внёсший изменение
+	Username  string    `db:"username" json:"username"`     // Имя пользователя на момент изменения
+	Action    string    `db:"action" json:"action"`         // create, update или delete
+	TableName string    `db:"table_name" json:"table_name"` // Изменённая таблица определений
+	RecordID  int       `db:"record_id" json:"record_id"`   // ID изменённой записи
+	Before    string    `db:"before" json:"before"`         // Запись до изменения в JSON, пусто при создании
+	After     string    `db:"after" json:"after"`           // Запись после изменения в JSON, пусто при удалении
+	CreatedAt time.Time `db:"created_at" json:"created_at"` // Время изменения
+}
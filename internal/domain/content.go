@@ -0,0 +1,25 @@
+package domain
+
+// ItemDefinition is an item definition together with the ObjectList of its object,
+// which gives it its name and look.
+type ItemDefinition struct {
+	ItemList
+	ObjectListID int `db:"object_list_id" json:"object_list_id"`
+}
+
+// ContentBundle is the complete set of content definitions, ordered by ID.
+type ContentBundle struct {
+	ObjectLists []ObjectList
+	ItemLists   []ItemDefinition
+	EntityLists []EntityList
+}
+
+// ContentChange is a change of a single definition in a bulk content import.
+// Before and After are *ObjectList, *ItemDefinition or *EntityList according to Table.
+type ContentChange struct {
+	Action string      // AuditActionCreate, AuditActionUpdate или AuditActionDelete
+	Table  string      // object_list, item_list или entity_list
+	ID     int         // ID определения, при создании задаётся импортом
+	Before interface{} // Состояние, на основе которого построено изменение; nil при создании
+	After  interface{} // Новое состояние; nil при удалении
+}
@@ -1,7 +1,46 @@
 package domain
 
+import (
+	"fmt"
+	"strconv"
+)
+
 type Inventory struct {
 	ID       string `db:"id"`        // Идентификатор инвенторя
 	EntityID string `db:"entity_id"` // Идентификатор энтити
 	ItemID   string `db:"item_id"`   // Идетификатор предмета
+	Slot     int    `db:"slot"`      // Номер слота, начиная с 0
+	Quantity int    `db:"quantity"`  // Количество предметов в стопке
+}
+
+// InventoryChanges is a set of inventory modifications applied in one transaction.
+// Every slot refers to an item of its own: saved entries with an empty ItemID receive,
+// in order, the items of NewItems, which are created first and get their IDs assigned.
+// Items of FreedItems are deleted together with their object once no entry refers to them.
+type InventoryChanges struct {
+	Saved      []Inventory // Новые и изменённые записи
+	Removed    []string    // ID удалённых записей
+	NewItems   []Item      // Создаваемые предметы, по одному на каждую запись Saved с пустым ItemID
+	FreedItems []int       // ID предметов, которые больше не лежат в изменённых слотах
+}
+
+// SavedWithNewItems returns a copy of Saved in which the entries without an item
+// refer, in order, to the items of NewItems. The new items must have their IDs.
+func (c InventoryChanges) SavedWithNewItems() ([]Inventory, error) {
+	saved := make([]Inventory, len(c.Saved))
+	next := 0
+	for i, entry := range c.Saved {
+		if entry.ItemID == "" {
+			if next == len(c.NewItems) {
+				return nil, fmt.Errorf("inventory entry for slot %d has no item", entry.Slot)
+			}
+			entry.ItemID = strconv.Itoa(c.NewItems[next].ID)
+			next++
+		}
+		saved[i] = entry
+	}
+	if next != len(c.NewItems) {
+		return nil, fmt.Errorf("%d new items belong to no inventory entry", len(c.NewItems)-next)
+	}
+	return saved, nil
 }
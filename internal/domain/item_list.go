@@ -1,8 +1,14 @@
 package domain
 
+// Bounds of ItemList.Rarity, from common to legendary.
+const (
+	MinRarity = 0
+	MaxRarity = 4
+)
+
 type ItemList struct {
-	ID          int  `db:"id"`
-	ObjectID    int  `db:"object_id"`
-	Rarity      int  `db:"rarity"`
-	IsStackable bool `db:"is_stackable"`
+	ID          int  `db:"id" json:"id"`
+	ObjectID    int  `db:"object_id" json:"object_id"`
+	Rarity      int  `db:"rarity" json:"rarity"`
+	IsStackable bool `db:"is_stackable" json:"is_stackable"`
 }
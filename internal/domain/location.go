@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// Location represents the last known position of a player in the world.
+type Location struct {
+	PlayerID  string    `db:"player_id"`  // Идентификатор игрока (совпадает с ID пользователя)
+	X         float64   `db:"x"`          // Координата X
+	Y         float64   `db:"y"`          // Координата Y
+	Z         float64   `db:"z"`          // Координата Z
+	UpdatedAt time.Time `db:"updated_at"` // Время последнего обновления
+}
@@ -1,8 +1,8 @@
 package domain
 
 type ObjectList struct {
-	ID          int    `db:"id"`
-	name        string `db:"name"`
-	image       string `db:"image"`
-	description string `db:"description"`
+	ID          int    `db:"id" json:"id"`
+	Name        string `db:"name" json:"name"`               // Название объекта
+	Image       string `db:"image" json:"image"`             // Путь к изображению
+	Description string `db:"description" json:"description"` // Описание объекта
 }
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// PlayerStats holds the combat state of a player.
+type PlayerStats struct {
+	PlayerID  string    `db:"player_id"`  // Идентификатор игрока (совпадает с ID пользователя)
+	Health    float64   `db:"health"`     // Текущее здоровье, 0 — игрок мёртв
+	MaxHealth float64   `db:"max_health"` // Максимальное здоровье
+	Deaths    int       `db:"deaths"`     // Количество смертей
+	UpdatedAt time.Time `db:"updated_at"` // Время последнего обновления
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// RefreshToken is a stored refresh token. Every refresh rotates the token: the
+// presented one is marked as rotated and a successor of the same family is issued.
+type RefreshToken struct {
+	ID        string     `db:"id"`
+	UserID    string     `db:"user_id"`
+	FamilyID  string     `db:"family_id"`  // Общий для всех токенов, полученных обновлением одного входа
+	TokenHash string     `db:"token_hash"` // SHA-256 токена в hex, сам токен не хранится
+	ExpiresAt time.Time  `db:"expires_at"`
+	CreatedAt time.Time  `db:"created_at"`
+	RotatedAt *time.Time `db:"rotated_at"` // Когда токен был обменян на следующий
+	RevokedAt *time.Time `db:"revoked_at"` // Когда семейство токена было отозвано
+}
@@ -0,0 +1,169 @@
+package domain
+
+// Repository contracts of the domain layer. Services depend only on these
+// interfaces, so storage backends can be swapped without touching business logic.
+
+import "time"
+
+// UserRepository defines persistence operations for users.
+type UserRepository interface {
+	CreateUser(user *User) error
+	GetUserByUsername(username string) (*User, error)
+	GetUserByID(id string) (*User, error)
+}
+
+// RoleRepository defines persistence operations for the roles of users.
+type RoleRepository interface {
+	// GetUserRoles returns the roles of a user in lexical order.
+	GetUserRoles(userID string) ([]string, error)
+	// GrantRole gives a user a role; granting a role the user holds has no effect.
+	GrantRole(userID, role string) error
+	// RevokeRole takes a role from a user; revoking a role the user lacks has no effect.
+	RevokeRole(userID, role string) error
+}
+
+// RefreshTokenRepository defines persistence operations for refresh tokens.
+type RefreshTokenRepository interface {
+	// CreateRefreshToken stores a token that starts a new family, assigning its ID.
+	CreateRefreshToken(token *RefreshToken) error
+	// RotateRefreshToken exchanges the token with tokenHash for next, which joins its
+	// family, in one transaction, and returns the exchanged token. It fails with
+	// util.ErrInvalidToken if the token is unknown, expired or revoked. A token that was
+	// already rotated is being reused: its whole family is revoked and
+	// util.ErrRefreshTokenReused is returned.
+	RotateRefreshToken(tokenHash string, next *RefreshToken, now time.Time) (*RefreshToken, error)
+	// RevokeRefreshTokenFamily revokes every token of the family of the token with tokenHash.
+	// It fails with util.ErrInvalidToken if the token is unknown.
+	RevokeRefreshTokenFamily(tokenHash string, now time.Time) error
+}
+
+// PlayerMovementRepository defines persistence operations for player locations.
+type PlayerMovementRepository interface {
+	SavePlayerLocation(location *Location) error
+	GetPlayerLocation(playerID string) (*Location, error)
+	GetAllPlayerLocations() ([]Location, error)
+}
+
+// PlayerStatsRepository defines persistence operations for player combat stats.
+type PlayerStatsRepository interface {
+	SavePlayerStats(stats *PlayerStats) error
+	GetPlayerStats(playerID string) (*PlayerStats, error)
+}
+
+// PlayerRepository defines persistence operations for player characters.
+type PlayerRepository interface {
+	CreatePlayer(player *Player) error
+	GetPlayerByID(id int) (*Player, error)
+	GetPlayersByUserID(userID int) ([]Player, error)
+	UpdatePlayer(player *Player) error
+	DeletePlayer(id int) error
+}
+
+// ObjectListRepository defines persistence operations for object definitions.
+// Every change is recorded in the audit log in the same transaction; the repository
+// fills in the action, table, record and snapshots of audit.
+type ObjectListRepository interface {
+	GetObjectListByID(id int) (*ObjectList, error)
+	GetAllObjectLists() ([]ObjectList, error)
+	CreateObjectList(objectList *ObjectList, audit *AuditEntry) error
+	UpdateObjectList(objectList *ObjectList, audit *AuditEntry) error
+	// DeleteObjectList fails with util.ErrDefinitionInUse while objects or entity templates refer to it.
+	DeleteObjectList(id int, audit *AuditEntry) error
+}
+
+// ObjectRepository defines persistence operations for object instances.
+type ObjectRepository interface {
+	CreateObject(object *Object) error
+	GetObjectByID(id int) (*Object, error)
+	DeleteObject(id int) error
+}
+
+// ItemListRepository defines persistence operations for item definitions.
+// Changes are audited like those of ObjectListRepository.
+type ItemListRepository interface {
+	GetItemListByID(id int) (*ItemList, error)
+	GetAllItemLists() ([]ItemList, error)
+	// GetItemListByObjectListID finds the item definition whose object is of objectListID.
+	GetItemListByObjectListID(objectListID int) (*ItemList, error)
+	// CreateItemList creates an item definition together with an object of its
+	// ObjectList, in one transaction with the audit entry.
+	CreateItemList(definition *ItemDefinition, audit *AuditEntry) error
+	// UpdateItemList updates an item definition. A changed ObjectList moves it to a
+	// new object, and the previous object is deleted once nothing is made of it.
+	UpdateItemList(definition *ItemDefinition, audit *AuditEntry) error
+	// DeleteItemList fails with util.ErrDefinitionInUse while items of it exist.
+	DeleteItemList(id int, audit *AuditEntry) error
+}
+
+// ItemRepository defines persistence operations for item instances.
+type ItemRepository interface {
+	CreateItem(item *Item) error
+	GetItemByID(id int) (*Item, error)
+	DeleteItem(id int) error
+}
+
+// EntityListRepository defines persistence operations for entity definitions.
+// Changes are audited like those of ObjectListRepository.
+type EntityListRepository interface {
+	GetEntityListByID(id int) (*EntityList, error)
+	GetAllEntityLists() ([]EntityList, error)
+	CreateEntityList(entityList *EntityList, audit *AuditEntry) error
+	UpdateEntityList(entityList *EntityList, audit *AuditEntry) error
+	// DeleteEntityList deletes a template together with its entities.
+	DeleteEntityList(id int, audit *AuditEntry) error
+}
+
+// ContentRepository reads and replaces content definitions as a whole.
+type ContentRepository interface {
+	// GetContent returns all object, item and entity definitions as one consistent snapshot.
+	GetContent() (*ContentBundle, error)
+	// ApplyContentChanges applies changes in order in one transaction, recording each
+	// in the audit log like the definition repositories do. Definitions are created
+	// with the IDs of the changes. An update or deletion fails with
+	// util.ErrContentChanged if the definition no longer matches its Before state.
+	ApplyContentChanges(changes []ContentChange, audit *AuditEntry) error
+}
+
+// AuditLogRepository defines read operations for the audit log.
+type AuditLogRepository interface {
+	// GetAuditEntries returns a page of the audit log, newest first.
+	GetAuditEntries(limit, offset int) ([]AuditEntry, error)
+}
+
+// EntityRepository defines persistence operations for entity instances.
+type EntityRepository interface {
+	CreateEntity(entity *Entity) error
+	GetEntityByID(id int) (*Entity, error)
+	GetAllEntities() ([]Entity, error)
+	UpdateEntity(entity *Entity) error
+	DeleteEntity(id int) error
+	// SpawnEntity creates an object of objectListID and an entity bound to it in one transaction.
+	SpawnEntity(entity *Entity, objectListID int) error
+	// DespawnEntity deletes an entity together with its object.
+	DespawnEntity(id int) error
+}
+
+// InventoryRepository defines persistence operations for inventory entries.
+type InventoryRepository interface {
+	AddInventoryEntry(entry *Inventory) error
+	GetInventoryByEntityID(entityID string) ([]Inventory, error)
+	RemoveInventoryEntry(id string) error
+	// SaveInventoryChanges applies removals, creates new items, inserts and updates
+	// entries and deletes freed items in one transaction.
+	SaveInventoryChanges(changes InventoryChanges) error
+	// PickUpEntity deletes a world entity and applies changes in one transaction. The
+	// entity's object passes to the first of changes.NewItems, or is deleted with the
+	// entity if the pickup only topped up existing stacks.
+	// It fails with util.ErrEntityNotFound if the entity is already gone.
+	PickUpEntity(entityID int, changes InventoryChanges) error
+	// DropItem applies changes and spawns entity with a new object of objectListID in one transaction.
+	DropItem(changes InventoryChanges, entity *Entity, objectListID int) error
+}
+
+// WorldRepository defines persistence operations for terrain height points.
+type WorldRepository interface {
+	SaveWorldPoints(points []World) error
+	ReplaceWorldPoints(points []World) error
+	GetWorldPoint(x, y int) (*World, error)
+	GetAllWorldPoints() ([]World, error)
+}
@@ -0,0 +1,24 @@
+package domain
+
+// Roles a user can hold.
+const (
+	RoleAdmin     = "admin"     // Управление контентом и ролями
+	RoleModerator = "moderator" // Модерация игроков
+)
+
+// IsValidRole reports whether role is one of the known roles.
+func IsValidRole(role string) bool {
+	return role == RoleAdmin || role == RoleModerator
+}
+
+// HasAnyRole reports whether held contains at least one of wanted.
+func HasAnyRole(held []string, wanted ...string) bool {
+	for _, h := range held {
+		for _, w := range wanted {
+			if h == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package protocol
+
+// Application-defined WebSocket close codes (RFC 6455 reserves 4000-4999 for applications).
+const (
+	// CloseSessionReplaced is sent to a connection whose session was taken over by a newer login.
+	CloseSessionReplaced = 4001
+	// CloseSessionRejected is sent to a new connection refused because the account is already online.
+	CloseSessionRejected = 4002
+	// CloseKicked is sent to a connection the server ended, e.g. after detecting cheating.
+	CloseKicked = 4003
+)
+
+// CloseReason returns the reason text sent together with an application close code.
+func CloseReason(code int) string {
+	switch code {
+	case CloseSessionReplaced:
+		return "session_replaced"
+	case CloseSessionRejected:
+		return "session_rejected"
+	case CloseKicked:
+		return "kicked"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,77 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// WebSocket subprotocols understood by the server, negotiated via Sec-WebSocket-Protocol.
+const (
+	SubprotocolJSON    = "anarchy.v1.json"
+	SubprotocolMsgpack = "anarchy.v1.msgpack"
+)
+
+// Codec encodes and decodes frames of one wire format.
+type Codec interface {
+	// Name returns the WebSocket subprotocol of the codec.
+	Name() string
+	// Binary reports whether frames must be sent as binary WebSocket messages.
+	Binary() bool
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// Available codecs.
+var (
+	JSON    Codec = jsonCodec{}
+	Msgpack Codec = msgpackCodec{}
+)
+
+// Subprotocols lists the supported subprotocols in order of server preference.
+func Subprotocols() []string {
+	return []string{SubprotocolMsgpack, SubprotocolJSON}
+}
+
+// CodecFor returns the codec of a negotiated subprotocol.
+// Clients that did not negotiate a subprotocol use JSON.
+func CodecFor(subprotocol string) Codec {
+	if subprotocol == SubprotocolMsgpack {
+		return Msgpack
+	}
+	return JSON
+}
+
+// RawPayload holds an encoded payload whose decoding is deferred until the
+// message type is known. Its contents are in the format of the frame's codec.
+type RawPayload []byte
+
+// MarshalJSON returns the raw payload as is, like json.RawMessage.
+func (p RawPayload) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+	return p, nil
+}
+
+// UnmarshalJSON stores a copy of the raw payload, like json.RawMessage.
+func (p *RawPayload) UnmarshalJSON(data []byte) error {
+	if p == nil {
+		return errors.New("protocol: UnmarshalJSON on nil RawPayload")
+	}
+	*p = append((*p)[:0], data...)
+	return nil
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string                               { return SubprotocolJSON }
+func (jsonCodec) Binary() bool                               { return false }
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string                               { return SubprotocolMsgpack }
+func (msgpackCodec) Binary() bool                               { return true }
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error)      { return MarshalMsgpack(v) }
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error { return UnmarshalMsgpack(data, v) }
@@ -0,0 +1,422 @@
+package protocol_test
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	"anarchy-core/internal/api/handler"
+	"anarchy-core/internal/protocol"
+	"anarchy-core/internal/service"
+)
+
+// decodedEnvelope is an envelope with its payload decoded, so that envelopes can be
+// compared independently of how a codec lays out the raw payload bytes.
+type decodedEnvelope struct {
+	Version int
+	Type    string
+	Seq     uint64
+	Payload handler.MovePayload
+}
+
+// codecCase is a message that must survive a round trip through every codec.
+// encode and decode default to Codec.Marshal and Codec.Unmarshal into a new value of want's type.
+type codecCase struct {
+	name   string
+	want   interface{}
+	encode func(codec protocol.Codec, msg interface{}) ([]byte, error)
+	decode func(codec protocol.Codec, data []byte) (interface{}, error)
+}
+
+func float(f float64) *float64 { return &f }
+
+var codecCases = []codecCase{
+	{
+		name: "envelope",
+		want: decodedEnvelope{
+			Version: protocol.Version,
+			Type:    "move",
+			Seq:     42,
+			Payload: handler.MovePayload{X: 12.5, Y: -3.25, Z: 0.1},
+		},
+		encode: func(codec protocol.Codec, msg interface{}) ([]byte, error) {
+			env := msg.(decodedEnvelope)
+			payload, err := codec.Marshal(env.Payload)
+			if err != nil {
+				return nil, err
+			}
+			return codec.Marshal(protocol.Envelope{Version: env.Version, Type: env.Type, Seq: env.Seq, Payload: payload})
+		},
+		decode: func(codec protocol.Codec, data []byte) (interface{}, error) {
+			env, err := protocol.DecodeEnvelope(codec, data)
+			if err != nil {
+				return nil, err
+			}
+			decoded := decodedEnvelope{Version: env.Version, Type: env.Type, Seq: env.Seq}
+			if err := env.DecodePayload(&decoded.Payload); err != nil {
+				return nil, err
+			}
+			return decoded, nil
+		},
+	},
+	{
+		name: "error frame",
+		want: protocol.NewErrorFrame(7, protocol.NewError(protocol.ErrCodeUnknownType, "unknown message type %q", "fly")),
+	},
+	{
+		name: "player location update",
+		want: service.PlayerLocationUpdate{
+			Type:      "player_location_update",
+			PlayerID:  "0b6f2c9e-5d1a-4f3e-9a47-2f7c1d8e6b10",
+			Username:  "alice",
+			X:         1024.75,
+			Y:         -17.1,
+			Z:         3.3333333333333335,
+			Timestamp: 1792210065123,
+		},
+	},
+	{
+		name: "full snapshot",
+		want: service.SnapshotMessage{
+			Type: "snapshot",
+			ID:   1,
+			Tick: 1 << 40,
+			Players: []service.PlayerDelta{
+				{PlayerID: "p1", Username: "alice", X: float(1), Y: float(2.5), Z: float(-300), Timestamp: 1792210065123},
+			},
+			Entities: []service.EntityDelta{
+				{EntityID: 70000, EntityListID: 3, State: "idle", Health: float(100), X: float(0), Y: float(0.2), Z: float(-0.2)},
+			},
+		},
+	},
+	{
+		name: "delta snapshot",
+		want: service.SnapshotMessage{
+			Type:     "snapshot",
+			ID:       9,
+			Baseline: 7,
+			Tick:     12345,
+			Players: []service.PlayerDelta{
+				{PlayerID: "p1", X: float(1.5)},
+				{PlayerID: "p2", Username: "bob", X: float(0), Y: float(0), Z: float(0), Timestamp: 1},
+			},
+			Removed:         []string{"p3"},
+			Entities:        []service.EntityDelta{{EntityID: 5, State: "chase", Health: float(12.75)}},
+			RemovedEntities: []int{6, 300, 70000},
+		},
+	},
+	{
+		name: "terrain chunk",
+		want: service.ChunkMessage{
+			Type:      "chunk",
+			X:         -3,
+			Z:         129,
+			Version:   0xdeadbeef,
+			Size:      2,
+			CellSize:  1,
+			MinHeight: -12.5,
+			Scale:     0.001,
+			Heights:   []byte{0x00, 0x00, 0xff, 0x7f, 0xfe, 0xff, 0xff, 0xff},
+		},
+	},
+	{
+		name: "terrain chunk not modified",
+		want: service.ChunkMessage{Type: "chunk", X: 4, Z: -40000, Version: 17, NotModified: true},
+	},
+	{
+		name: "inventory",
+		want: service.InventoryMessage{
+			Type: "inventory",
+			InventoryView: service.InventoryView{
+				OwnerID:  "p1",
+				Capacity: 20,
+				Slots:    []service.InventorySlot{{Slot: 3, ItemID: 12, ItemListID: 2, Quantity: 64, Stackable: true}},
+			},
+		},
+	},
+}
+
+// serverMessages holds a message of every type the server sends to clients.
+var serverMessages = []struct {
+	name string
+	msg  interface{}
+}{
+	{"error", protocol.NewErrorFrame(3, protocol.NewError(protocol.ErrCodeBadRequest, "bad slot"))},
+	{"session", service.SessionMessage{Type: "session", ResumeToken: "tok", GraceSeconds: 10}},
+	{"session resumed", service.SessionResumedMessage{Type: "session_resumed", Replayed: 4, Resync: true}},
+	{"player location update", service.PlayerLocationUpdate{Type: "player_location_update", PlayerID: "p1", Username: "alice", X: 1.5, Y: -2, Z: 1e6, Timestamp: 1792210065123}},
+	{"player left view", service.PlayerLeftViewMessage{Type: "player_left_view", PlayerID: "p2"}},
+	{"position correction", service.PositionCorrectionMessage{Type: "position_correction", X: 0.1, Y: 2, Z: -3, Reason: "speed"}},
+	{"snapshot", service.SnapshotMessage{
+		Type: "snapshot", ID: 5, Baseline: 4, Tick: 1 << 40,
+		Players:  []service.PlayerDelta{{PlayerID: "p1", X: float(0)}, {PlayerID: "p2", Username: "bob", Y: float(-1.25), Timestamp: 9}},
+		Removed:  []string{},
+		Entities: []service.EntityDelta{{EntityID: 7, State: "idle", Health: float(0.5)}},
+	}},
+	{"empty snapshot", service.SnapshotMessage{Type: "snapshot", ID: 1}},
+	{"chunk", service.ChunkMessage{Type: "chunk", X: -1, Z: 2, Version: 3, Size: 2, CellSize: 1.5, MinHeight: -4, Scale: 0.01, Heights: []byte{1, 2, 3, 4, 5, 6, 7, 8}}},
+	{"chunk not modified", service.ChunkMessage{Type: "chunk", X: 1, Z: 1, Version: 3, NotModified: true}},
+	{"damage", service.DamageMessage{Type: "damage", TargetType: "player", TargetID: "p1", SourceType: "entity", SourceID: "7", Amount: 12.5, Health: 87.5}},
+	{"death", service.DeathMessage{Type: "death", TargetType: "entity", TargetID: "7", KillerType: "player", KillerID: "p1"}},
+	{"respawn", service.RespawnMessage{Type: "respawn", TargetType: "player", TargetID: "p1", X: 30, Y: 15, Z: 0, Health: 100}},
+	{"attack missed", service.AttackMissedMessage{Type: "attack_missed", TargetType: "entity", TargetID: "7", Reason: "out_of_range"}},
+	{"player stats", service.PlayerStatsMessage{Type: "player_stats", Health: 40, MaxHealth: 100, Deaths: 2}},
+	{"entity attack", service.EntityAttackMessage{Type: "entity_attack", EntityID: 7, PlayerID: "p1", Damage: 3.25}},
+	{"item picked up", service.ItemPickedUpMessage{Type: "item_picked_up", PlayerID: "p1", EntityID: 9, ItemListID: 2}},
+	{"item dropped", service.ItemDroppedMessage{Type: "item_dropped", PlayerID: "p1", EntityID: 10, ItemListID: 2, X: 1, Y: 2, Z: 3}},
+	{"item action failed", service.ItemActionFailedMessage{Type: "item_action_failed", Action: "drop", Slot: 4, Reason: "empty slot"}},
+	{"inventory", service.InventoryMessage{
+		Type: "inventory",
+		InventoryView: service.InventoryView{
+			OwnerID:  "p1",
+			Capacity: 2,
+			Slots:    []service.InventorySlot{{Slot: 0, ItemID: 1, ItemListID: 2, Quantity: 3, Stackable: true}, {Slot: 1, ItemID: 4, ItemListID: 5, Quantity: 1}},
+		},
+	}},
+	{"empty inventory", service.InventoryMessage{Type: "inventory", InventoryView: service.InventoryView{OwnerID: "p1", Capacity: 20}}},
+	{"container", service.ContainerMessage{
+		Type:     "container",
+		EntityID: 11,
+		InventoryView: service.InventoryView{
+			OwnerID:  "11",
+			Capacity: 8,
+			Slots:    []service.InventorySlot{{Slot: 7, ItemID: 6, ItemListID: 2, Quantity: 1}},
+		},
+	}},
+	{"container closed", service.ContainerClosedMessage{Type: "container_closed", EntityID: 11, Reason: "out_of_range"}},
+	{"player kicked", handler.PlayerKickedMessage{Type: "player_kicked", PlayerID: "p2"}},
+}
+
+// codecs lists every supported codec.
+var codecs = []protocol.Codec{protocol.JSON, protocol.Msgpack}
+
+// encodeCase encodes the message of tc with codec.
+func encodeCase(t *testing.T, tc codecCase, codec protocol.Codec) []byte {
+	t.Helper()
+	encode := tc.encode
+	if encode == nil {
+		encode = func(codec protocol.Codec, msg interface{}) ([]byte, error) { return codec.Marshal(msg) }
+	}
+	data, err := encode(codec, tc.want)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	return data
+}
+
+// decodeCase decodes a frame of codec into the message type of tc.
+func decodeCase(tc codecCase, codec protocol.Codec, data []byte) (interface{}, error) {
+	if tc.decode != nil {
+		return tc.decode(codec, data)
+	}
+	v := reflect.New(reflect.TypeOf(tc.want))
+	if err := codec.Unmarshal(data, v.Interface()); err != nil {
+		return nil, err
+	}
+	return v.Elem().Interface(), nil
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	for _, codec := range codecs {
+		for _, tc := range codecCases {
+			t.Run(codec.Name()+"/"+tc.name, func(t *testing.T) {
+				data := encodeCase(t, tc, codec)
+				got, err := decodeCase(tc, codec, data)
+				if err != nil {
+					t.Fatalf("decode: %v", err)
+				}
+				if !reflect.DeepEqual(got, tc.want) {
+					t.Errorf("round trip mismatch\n got: %+v\nwant: %+v", got, tc.want)
+				}
+			})
+		}
+	}
+}
+
+func TestCodecRejectsTruncatedInput(t *testing.T) {
+	for _, codec := range codecs {
+		for _, tc := range codecCases {
+			t.Run(codec.Name()+"/"+tc.name, func(t *testing.T) {
+				data := encodeCase(t, tc, codec)
+				for n := 0; n < len(data); n++ {
+					if _, err := decodeCase(tc, codec, data[:n]); err == nil {
+						t.Fatalf("decoding the first %d of %d bytes succeeded", n, len(data))
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestCodecRejectsMismatchedType(t *testing.T) {
+	for _, codec := range codecs {
+		for _, tc := range codecCases {
+			t.Run(codec.Name()+"/"+tc.name, func(t *testing.T) {
+				for _, frame := range []interface{}{"move", 42, []int{1, 2}} {
+					data, err := codec.Marshal(frame)
+					if err != nil {
+						t.Fatalf("encode %v: %v", frame, err)
+					}
+					if _, err := decodeCase(tc, codec, data); err == nil {
+						t.Errorf("decoding %v succeeded", frame)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestMsgpackRejectsUnknownTypes(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{"never used", []byte{0xc1}},
+		{"fixext 1", []byte{0xd4, 0x01, 0x00}},
+		{"ext 8", []byte{0xc7, 0x01, 0x01, 0x00}},
+		{"ext in payload", []byte{0x83, 0xa1, 'v', 0x01, 0xa4, 't', 'y', 'p', 'e', 0xa4, 'm', 'o', 'v', 'e', 0xa7, 'p', 'a', 'y', 'l', 'o', 'a', 'd', 0xd4, 0x01, 0x00}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := protocol.DecodeEnvelope(protocol.Msgpack, tc.data); err == nil {
+				t.Error("decoding succeeded")
+			}
+		})
+	}
+}
+
+// Test types exercising the promotion of embedded struct fields.
+type (
+	inner struct {
+		A int    `json:"a"`
+		B string `json:"b,omitempty"`
+		C int
+	}
+	other struct {
+		C int
+		D int `json:"d"`
+	}
+	tagged struct {
+		D int `json:"D"`
+	}
+	untagged struct {
+		D int
+	}
+	shadowed struct {
+		inner
+		C string // Скрывает inner.C
+	}
+	conflicting struct {
+		inner
+		other // C конфликтует с inner.C и не кодируется
+	}
+	tagWins struct {
+		tagged
+		untagged // D без тега уступает tagged.D
+	}
+	Pointed struct {
+		A int    `json:"a"`
+		B string `json:"b,omitempty"`
+	}
+	embeddedPointer struct {
+		*Pointed
+		E int `json:"e"`
+	}
+	namedEmbedded struct {
+		inner `json:"inner"`
+	}
+)
+
+// embeddingMessages cover the embedding rules of encoding/json.
+var embeddingMessages = []struct {
+	name string
+	msg  interface{}
+}{
+	{"shadowed", shadowed{inner: inner{A: 1, B: "x", C: 2}, C: "outer"}},
+	{"conflicting", conflicting{inner: inner{A: 1, C: 2}, other: other{C: 3, D: 4}}},
+	{"tag wins", tagWins{tagged: tagged{D: 1}, untagged: untagged{D: 2}}},
+	{"embedded pointer", embeddedPointer{Pointed: &Pointed{A: 1, B: "x"}, E: 5}},
+	{"nil embedded pointer", embeddedPointer{E: 5}},
+	{"named embedded", namedEmbedded{inner: inner{A: 1}}},
+}
+
+func TestCodecsAgreeOnEmbedding(t *testing.T) {
+	for _, tc := range embeddingMessages {
+		t.Run(tc.name, func(t *testing.T) { checkCodecsAgree(t, tc.msg) })
+	}
+}
+
+func TestMsgpackDecodesEmbeddedPointer(t *testing.T) {
+	want := embeddedPointer{Pointed: &Pointed{A: 1, B: "x"}, E: 5}
+	data, err := protocol.Msgpack.Marshal(want)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	var got embeddedPointer
+	if err := protocol.Msgpack.Unmarshal(data, &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// TestCodecsAgree decodes the frames of every codec into generic maps, so that a
+// message laid out differently by one of them is caught even if each codec reads
+// back its own output.
+func TestCodecsAgree(t *testing.T) {
+	for _, tc := range serverMessages {
+		t.Run(tc.name, func(t *testing.T) { checkCodecsAgree(t, tc.msg) })
+	}
+}
+
+// checkCodecsAgree fails unless msg decodes to the same generic map with every codec.
+func checkCodecsAgree(t *testing.T, msg interface{}) {
+	t.Helper()
+	var want map[string]interface{}
+	for _, codec := range codecs {
+		data, err := codec.Marshal(msg)
+		if err != nil {
+			t.Fatalf("%s encode: %v", codec.Name(), err)
+		}
+		var got map[string]interface{}
+		if err := codec.Unmarshal(data, &got); err != nil {
+			t.Fatalf("%s decode: %v", codec.Name(), err)
+		}
+		normalized := normalize(got).(map[string]interface{})
+		if want == nil {
+			want = normalized
+			continue
+		}
+		if !reflect.DeepEqual(normalized, want) {
+			t.Errorf("%s and %s disagree\n got: %v\nwant: %v", codec.Name(), codecs[0].Name(), normalized, want)
+		}
+	}
+}
+
+// normalize maps the generic values of all codecs to those of encoding/json:
+// numbers become float64 and byte strings base64 strings.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = normalize(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = normalize(item)
+		}
+		return out
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	}
+	return v
+}
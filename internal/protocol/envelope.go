@@ -0,0 +1,81 @@
+// Package protocol defines the WebSocket wire format shared by the transport
+// layer and the game services.
+package protocol
+
+import "fmt"
+
+// Version is the current protocol version. Frames with a different version are rejected.
+const Version = 1
+
+// Envelope is a single client-to-server frame. Seq is chosen by the client and is
+// echoed back in error frames so that failures can be matched to requests.
+type Envelope struct {
+	Version int        `json:"v"`
+	Type    string     `json:"type"`
+	Seq     uint64     `json:"seq,omitempty"`
+	Payload RawPayload `json:"payload,omitempty"`
+
+	codec Codec // Кодек, которым закодирован Payload
+}
+
+// DecodeEnvelope decodes a client frame with the given codec.
+func DecodeEnvelope(codec Codec, data []byte) (*Envelope, error) {
+	env := &Envelope{codec: codec}
+	if err := codec.Unmarshal(data, env); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// Error codes reported in error frames.
+const (
+	ErrCodeBadRequest         = "bad_request"
+	ErrCodeUnsupportedVersion = "unsupported_version"
+	ErrCodeUnknownType        = "unknown_type"
+	ErrCodeForbidden          = "forbidden"
+	ErrCodeInternal           = "internal_error"
+)
+
+// Error is a client-visible failure returned by message handlers.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewError creates a new Error with a formatted message.
+func NewError(code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// ErrorFrame is the server-to-client frame sent when a client message fails.
+type ErrorFrame struct {
+	Version int    `json:"v"`
+	Type    string `json:"type"` // "error"
+	Seq     uint64 `json:"seq"`
+	Payload *Error `json:"payload"`
+}
+
+// NewErrorFrame wraps err into an error frame that references the client's seq.
+func NewErrorFrame(seq uint64, err *Error) ErrorFrame {
+	return ErrorFrame{Version: Version, Type: "error", Seq: seq, Payload: err}
+}
+
+// DecodePayload unmarshals the envelope payload into v using the frame's codec.
+func (e *Envelope) DecodePayload(v interface{}) error {
+	if len(e.Payload) == 0 {
+		return NewError(ErrCodeBadRequest, "message %q requires a payload", e.Type)
+	}
+	codec := e.codec
+	if codec == nil {
+		codec = JSON
+	}
+	if err := codec.Unmarshal(e.Payload, v); err != nil {
+		return NewError(ErrCodeBadRequest, "invalid payload for %q: %v", e.Type, err)
+	}
+	return nil
+}
@@ -0,0 +1,752 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// This file implements the subset of MessagePack (https://msgpack.org) needed by
+// the wire protocol. Structs are encoded as maps keyed by their `json` tag names,
+// with the fields of embedded structs promoted as encoding/json does, so the same
+// message types serve both codecs and look alike in both.
+
+var errShortBuffer = errors.New("msgpack: unexpected end of data")
+
+var rawPayloadType = reflect.TypeOf(RawPayload(nil))
+
+// msgpackField describes an encodable struct field. index is the path of field
+// indexes from the outer struct, which is longer than one for fields promoted from
+// embedded structs.
+type msgpackField struct {
+	name      string
+	index     []int
+	tagged    bool // Имя задано тегом json
+	omitEmpty bool
+}
+
+var msgpackFieldCache sync.Map // reflect.Type -> []msgpackField
+
+// msgpackFields returns the encodable fields of a struct type, following the rules of
+// encoding/json: `json` tags name and omit fields, the fields of untagged anonymous
+// structs are promoted into the outer struct, and of several fields with one name the
+// shallowest wins, then the one with a tag; otherwise none of them is encoded.
+func msgpackFields(t reflect.Type) []msgpackField {
+	if cached, ok := msgpackFieldCache.Load(t); ok {
+		return cached.([]msgpackField)
+	}
+
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+	var candidates []msgpackField
+	depth := map[string]int{}         // Глубина, на которой впервые встретилось имя
+	visited := map[reflect.Type]int{} // Уровень, на котором тип был развернут
+	next := []embedded{{typ: t}}
+	for level := 0; len(next) > 0; level++ {
+		current := next
+		next = nil
+		for _, st := range current {
+			// A type embedded twice on one level yields conflicting fields, but deeper
+			// repetitions are hidden by the shallower ones
+			if l, ok := visited[st.typ]; ok && l < level {
+				continue
+			}
+			visited[st.typ] = level
+
+			for i := 0; i < st.typ.NumField(); i++ {
+				f := st.typ.Field(i)
+				ft := f.Type
+				if f.Anonymous && ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if f.Anonymous {
+					if f.PkgPath != "" && ft.Kind() != reflect.Struct {
+						continue // unexported non-struct
+					}
+				} else if f.PkgPath != "" {
+					continue // unexported
+				}
+
+				tag := f.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				parts := strings.Split(tag, ",")
+				index := append(append([]int(nil), st.index...), i)
+
+				if parts[0] == "" && f.Anonymous && ft.Kind() == reflect.Struct {
+					next = append(next, embedded{typ: ft, index: index})
+					continue
+				}
+				field := msgpackField{name: f.Name, index: index, tagged: parts[0] != ""}
+				if field.tagged {
+					field.name = parts[0]
+				}
+				for _, opt := range parts[1:] {
+					if opt == "omitempty" {
+						field.omitEmpty = true
+					}
+				}
+				if d, seen := depth[field.name]; seen && d < level {
+					continue // hidden by a shallower field
+				}
+				depth[field.name] = level
+				candidates = append(candidates, field)
+			}
+		}
+	}
+
+	// Of the fields sharing a name at the shallowest depth, only a single tagged one
+	// or a single field at all survives
+	var fields []msgpackField
+	for i, f := range candidates {
+		var rivals, tagged int
+		for _, other := range candidates {
+			if other.name == f.name {
+				rivals++
+				if other.tagged {
+					tagged++
+				}
+			}
+		}
+		if rivals == 1 || (tagged == 1 && f.tagged) {
+			fields = append(fields, candidates[i])
+		}
+	}
+	sort.SliceStable(fields, func(i, j int) bool { return lessIndex(fields[i].index, fields[j].index) })
+
+	msgpackFieldCache.Store(t, fields)
+	return fields
+}
+
+// lessIndex orders field index paths in struct declaration order.
+func lessIndex(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// fieldForEncode returns the field of v at index, or false if it is promoted through
+// a nil embedded pointer and therefore absent, as in encoding/json.
+func fieldForEncode(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// fieldForDecode returns the field of v at index, allocating nil embedded pointers on
+// the way. It returns false if such a pointer is unexported and cannot be allocated.
+func fieldForDecode(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// isEmptyMsgpackValue reports whether v is omitted by the omitempty option, which
+// like encoding/json drops empty collections and zero scalars but never structs.
+func isEmptyMsgpackValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Ptr:
+		return v.IsZero()
+	}
+	return false
+}
+
+// MarshalMsgpack encodes v as MessagePack.
+func MarshalMsgpack(v interface{}) ([]byte, error) {
+	e := &msgpackEncoder{buf: make([]byte, 0, 128)}
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+type msgpackEncoder struct {
+	buf []byte
+}
+
+func (e *msgpackEncoder) writeByte(b byte) {
+	e.buf = append(e.buf, b)
+}
+
+func (e *msgpackEncoder) writeUint16(b byte, n uint16) {
+	e.buf = append(e.buf, b)
+	e.buf = binary.BigEndian.AppendUint16(e.buf, n)
+}
+
+func (e *msgpackEncoder) writeUint32(b byte, n uint32) {
+	e.buf = append(e.buf, b)
+	e.buf = binary.BigEndian.AppendUint32(e.buf, n)
+}
+
+func (e *msgpackEncoder) writeUint64(b byte, n uint64) {
+	e.buf = append(e.buf, b)
+	e.buf = binary.BigEndian.AppendUint64(e.buf, n)
+}
+
+func (e *msgpackEncoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.writeByte(0xc0)
+		return nil
+	}
+	if v.Type() == rawPayloadType {
+		if v.Len() == 0 {
+			e.writeByte(0xc0)
+		} else {
+			e.buf = append(e.buf, v.Bytes()...)
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			e.writeByte(0xc0)
+			return nil
+		}
+		return e.encode(v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			e.writeByte(0xc3)
+		} else {
+			e.writeByte(0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.encodeInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.encodeUint(v.Uint())
+	case reflect.Float32:
+		e.writeUint32(0xca, math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		f := v.Float()
+		// Positions are often exactly representable in 32 bits; use the shorter form when lossless
+		if f32 := float32(f); float64(f32) == f {
+			e.writeUint32(0xca, math.Float32bits(f32))
+		} else {
+			e.writeUint64(0xcb, math.Float64bits(f))
+		}
+	case reflect.String:
+		e.encodeString(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.writeByte(0xc0)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.encodeBytes(v.Bytes())
+			return nil
+		}
+		return e.encodeArray(v)
+	case reflect.Array:
+		return e.encodeArray(v)
+	case reflect.Map:
+		if v.IsNil() {
+			e.writeByte(0xc0)
+			return nil
+		}
+		e.encodeMapHeader(v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := e.encode(iter.Key()); err != nil {
+				return err
+			}
+			if err := e.encode(iter.Value()); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		return e.encodeStruct(v)
+	default:
+		return fmt.Errorf("msgpack: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func (e *msgpackEncoder) encodeInt(n int64) {
+	switch {
+	case n >= 0:
+		e.encodeUint(uint64(n))
+	case n >= -32:
+		e.writeByte(byte(n))
+	case n >= math.MinInt8:
+		e.buf = append(e.buf, 0xd0, byte(n))
+	case n >= math.MinInt16:
+		e.writeUint16(0xd1, uint16(n))
+	case n >= math.MinInt32:
+		e.writeUint32(0xd2, uint32(n))
+	default:
+		e.writeUint64(0xd3, uint64(n))
+	}
+}
+
+func (e *msgpackEncoder) encodeUint(n uint64) {
+	switch {
+	case n <= 0x7f:
+		e.writeByte(byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xcc, byte(n))
+	case n <= math.MaxUint16:
+		e.writeUint16(0xcd, uint16(n))
+	case n <= math.MaxUint32:
+		e.writeUint32(0xce, uint32(n))
+	default:
+		e.writeUint64(0xcf, n)
+	}
+}
+
+func (e *msgpackEncoder) encodeString(s string) {
+	n := len(s)
+	switch {
+	case n <= 31:
+		e.writeByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		e.writeUint16(0xda, uint16(n))
+	default:
+		e.writeUint32(0xdb, uint32(n))
+	}
+	e.buf = append(e.buf, s...)
+}
+
+func (e *msgpackEncoder) encodeBytes(b []byte) {
+	n := len(b)
+	switch {
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		e.writeUint16(0xc5, uint16(n))
+	default:
+		e.writeUint32(0xc6, uint32(n))
+	}
+	e.buf = append(e.buf, b...)
+}
+
+func (e *msgpackEncoder) encodeArray(v reflect.Value) error {
+	n := v.Len()
+	switch {
+	case n <= 15:
+		e.writeByte(0x90 | byte(n))
+	case n <= math.MaxUint16:
+		e.writeUint16(0xdc, uint16(n))
+	default:
+		e.writeUint32(0xdd, uint32(n))
+	}
+	for i := 0; i < n; i++ {
+		if err := e.encode(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *msgpackEncoder) encodeMapHeader(n int) {
+	switch {
+	case n <= 15:
+		e.writeByte(0x80 | byte(n))
+	case n <= math.MaxUint16:
+		e.writeUint16(0xde, uint16(n))
+	default:
+		e.writeUint32(0xdf, uint32(n))
+	}
+}
+
+func (e *msgpackEncoder) encodeStruct(v reflect.Value) error {
+	fields := msgpackFields(v.Type())
+	names := make([]string, 0, len(fields))
+	values := make([]reflect.Value, 0, len(fields))
+	for _, f := range fields {
+		field, ok := fieldForEncode(v, f.index)
+		if !ok || (f.omitEmpty && isEmptyMsgpackValue(field)) {
+			continue
+		}
+		names = append(names, f.name)
+		values = append(values, field)
+	}
+
+	e.encodeMapHeader(len(names))
+	for i, name := range names {
+		e.encodeString(name)
+		if err := e.encode(values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnmarshalMsgpack decodes MessagePack data into the value pointed to by v.
+func UnmarshalMsgpack(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("msgpack: Unmarshal requires a non-nil pointer")
+	}
+	d := &msgpackDecoder{data: data}
+	if err := d.decode(rv.Elem()); err != nil {
+		return err
+	}
+	if d.pos != len(d.data) {
+		return fmt.Errorf("msgpack: %d trailing bytes", len(d.data)-d.pos)
+	}
+	return nil
+}
+
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *msgpackDecoder) read(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, errShortBuffer
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *msgpackDecoder) readByte() (byte, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (d *msgpackDecoder) readUint(size int) (uint64, error) {
+	b, err := d.read(size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+// readValue decodes the next object into a generic Go value.
+func (d *msgpackDecoder) readValue() (interface{}, error) {
+	c, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c >= 0xa0 && c <= 0xbf:
+		b, err := d.read(int(c & 0x1f))
+		return string(b), err
+	case c >= 0x90 && c <= 0x9f:
+		return d.readArray(int(c & 0x0f))
+	case c >= 0x80 && c <= 0x8f:
+		return d.readMap(int(c & 0x0f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := d.readUint(1 << (c - 0xcc))
+		return n, err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		n, err := d.readUint(size)
+		if err != nil {
+			return nil, err
+		}
+		shift := uint(64 - 8*size)
+		return int64(n<<shift) >> shift, nil
+	case 0xca:
+		n, err := d.readUint(4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb:
+		n, err := d.readUint(8)
+		return math.Float64frombits(n), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readUint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(int(n))
+		return string(b), err
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.readUint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(int(n))
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	case 0xdc, 0xdd:
+		n, err := d.readUint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.readArray(int(n))
+	case 0xde, 0xdf:
+		n, err := d.readUint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.readMap(int(n))
+	}
+	return nil, fmt.Errorf("msgpack: unsupported type byte 0x%02x", c)
+}
+
+func (d *msgpackDecoder) readArray(n int) ([]interface{}, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errShortBuffer
+	}
+	arr := make([]interface{}, n)
+	for i := range arr {
+		v, err := d.readValue()
+		if err != nil {
+			return nil, err
+		}
+		arr[i] = v
+	}
+	return arr, nil
+}
+
+func (d *msgpackDecoder) readMap(n int) (map[string]interface{}, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errShortBuffer
+	}
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		k, err := d.readValue()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			key = fmt.Sprint(k)
+		}
+		v, err := d.readValue()
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+// decode decodes the next object into v, which must be settable.
+func (d *msgpackDecoder) decode(v reflect.Value) error {
+	if v.Type() == rawPayloadType {
+		start := d.pos
+		if _, err := d.readValue(); err != nil {
+			return err
+		}
+		raw := d.data[start:d.pos]
+		if len(raw) == 1 && raw[0] == 0xc0 {
+			v.SetBytes(nil)
+		} else {
+			v.SetBytes(append([]byte(nil), raw...))
+		}
+		return nil
+	}
+
+	value, err := d.readValue()
+	if err != nil {
+		return err
+	}
+	return assignMsgpack(v, value)
+}
+
+// assignMsgpack stores a generic decoded value into v, converting between compatible kinds.
+func assignMsgpack(v reflect.Value, value interface{}) error {
+	if value == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() == 0 {
+			v.Set(reflect.ValueOf(value))
+			return nil
+		}
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return assignMsgpack(v.Elem(), value)
+	case reflect.Bool:
+		if b, ok := value.(bool); ok {
+			v.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch n := value.(type) {
+		case int64:
+			if !v.OverflowInt(n) {
+				v.SetInt(n)
+				return nil
+			}
+		case uint64:
+			if n <= math.MaxInt64 && !v.OverflowInt(int64(n)) {
+				v.SetInt(int64(n))
+				return nil
+			}
+		case float64:
+			if n == math.Trunc(n) && !v.OverflowInt(int64(n)) {
+				v.SetInt(int64(n))
+				return nil
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		switch n := value.(type) {
+		case int64:
+			if n >= 0 && !v.OverflowUint(uint64(n)) {
+				v.SetUint(uint64(n))
+				return nil
+			}
+		case uint64:
+			if !v.OverflowUint(n) {
+				v.SetUint(n)
+				return nil
+			}
+		case float64:
+			if n >= 0 && n == math.Trunc(n) && !v.OverflowUint(uint64(n)) {
+				v.SetUint(uint64(n))
+				return nil
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		switch n := value.(type) {
+		case float64:
+			v.SetFloat(n)
+			return nil
+		case int64:
+			v.SetFloat(float64(n))
+			return nil
+		case uint64:
+			v.SetFloat(float64(n))
+			return nil
+		}
+	case reflect.String:
+		if s, ok := value.(string); ok {
+			v.SetString(s)
+			return nil
+		}
+	case reflect.Slice:
+		if b, ok := value.([]byte); ok && v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(b)
+			return nil
+		}
+		if arr, ok := value.([]interface{}); ok {
+			slice := reflect.MakeSlice(v.Type(), len(arr), len(arr))
+			for i, item := range arr {
+				if err := assignMsgpack(slice.Index(i), item); err != nil {
+					return err
+				}
+			}
+			v.Set(slice)
+			return nil
+		}
+	case reflect.Map:
+		if m, ok := value.(map[string]interface{}); ok && v.Type().Key().Kind() == reflect.String {
+			out := reflect.MakeMapWithSize(v.Type(), len(m))
+			for key, item := range m {
+				elem := reflect.New(v.Type().Elem()).Elem()
+				if err := assignMsgpack(elem, item); err != nil {
+					return err
+				}
+				out.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+			}
+			v.Set(out)
+			return nil
+		}
+	case reflect.Struct:
+		if m, ok := value.(map[string]interface{}); ok {
+			for _, f := range msgpackFields(v.Type()) {
+				item, ok := lookupMsgpackKey(m, f.name)
+				if !ok {
+					continue
+				}
+				field, ok := fieldForDecode(v, f.index)
+				if !ok {
+					return fmt.Errorf("msgpack: cannot set embedded pointer to unexported struct in %s", v.Type())
+				}
+				if !field.CanSet() {
+					continue // unexported embedded struct with a tag
+				}
+				if field.Type() == rawPayloadType {
+					// The payload was already decoded generically, so re-encode it for the later typed decode
+					raw, err := MarshalMsgpack(item)
+					if err != nil {
+						return err
+					}
+					field.SetBytes(raw)
+					continue
+				}
+				if err := assignMsgpack(field, item); err != nil {
+					return fmt.Errorf("field %q: %w", f.name, err)
+				}
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("msgpack: cannot decode %T into %s", value, v.Type())
+}
+
+// lookupMsgpackKey finds a map entry for a struct field, preferring an exact key match
+// and falling back to a case-insensitive one, as encoding/json does.
+func lookupMsgpackKey(m map[string]interface{}, name string) (interface{}, bool) {
+	if item, ok := m[name]; ok {
+		return item, true
+	}
+	for key, item := range m {
+		if strings.EqualFold(key, name) {
+			return item, true
+		}
+	}
+	return nil, false
+}
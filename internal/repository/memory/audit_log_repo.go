@@ -0,0 +1,29 @@
+package memory
+
+import (
+	"anarchy-core/internal/domain"
+)
+
+// AuditLogRepositoryMemory implements domain.AuditLogRepository in memory.
+type AuditLogRepositoryMemory struct {
+	store *Store
+}
+
+var _ domain.AuditLogRepository = (*AuditLogRepositoryMemory)(nil)
+
+// NewAuditLogRepositoryMemory creates a new AuditLogRepositoryMemory.
+func NewAuditLogRepositoryMemory(store *Store) *AuditLogRepositoryMemory {
+	return &AuditLogRepositoryMemory{store: store}
+}
+
+// GetAuditEntries returns a page of the audit log, newest first.
+func (r *AuditLogRepositoryMemory) GetAuditEntries(limit, offset int) ([]domain.AuditEntry, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	entries := []domain.AuditEntry{}
+	for i := len(r.store.auditLog) - 1 - offset; i >= 0 && len(entries) < limit; i-- {
+		entries = append(entries, r.store.auditLog[i])
+	}
+	return entries, nil
+}
@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// ContentRepositoryMemory implements domain.ContentRepository in memory.
+type ContentRepositoryMemory struct {
+	store *Store
+}
+
+var _ domain.ContentRepository = (*ContentRepositoryMemory)(nil)
+
+// NewContentRepositoryMemory creates a new ContentRepositoryMemory.
+func NewContentRepositoryMemory(store *Store) *ContentRepositoryMemory {
+	return &ContentRepositoryMemory{store: store}
+}
+
+// GetContent returns all object, item and entity definitions ordered by ID.
+func (r *ContentRepositoryMemory) GetContent() (*domain.ContentBundle, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	bundle := &domain.ContentBundle{
+		ObjectLists: sortedByID(r.store.objectLists),
+		EntityLists: sortedByID(r.store.entityLists),
+	}
+	for _, itemList := range sortedByID(r.store.itemLists) {
+		bundle.ItemLists = append(bundle.ItemLists, r.store.itemDefinitionLocked(itemList))
+	}
+	return bundle, nil
+}
+
+// ApplyContentChanges applies changes in order and records each in the audit log.
+// If a change fails, the definitions are restored as they were before the call.
+func (r *ContentRepositoryMemory) ApplyContentChanges(changes []domain.ContentChange, audit *domain.AuditEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	s := r.store
+	objectLists, itemLists, entityLists := maps.Clone(s.objectLists), maps.Clone(s.itemLists), maps.Clone(s.entityLists)
+	objects, entities := maps.Clone(s.objects), maps.Clone(s.entities)
+	auditLen := len(s.auditLog)
+	nextObjectListID, nextItemListID, nextEntityListID, nextObjectID := s.nextObjectListID, s.nextItemListID, s.nextEntityListID, s.nextObjectID
+
+	for _, change := range changes {
+		entry := *audit
+		if err := s.applyContentChangeLocked(change, &entry); err != nil {
+			s.objectLists, s.itemLists, s.entityLists = objectLists, itemLists, entityLists
+			s.objects, s.entities = objects, entities
+			s.auditLog = s.auditLog[:auditLen]
+			s.nextObjectListID, s.nextItemListID, s.nextEntityListID, s.nextObjectID = nextObjectListID, nextItemListID, nextEntityListID, nextObjectID
+			return fmt.Errorf("failed to %s %s %d: %w", change.Action, change.Table, change.ID, err)
+		}
+	}
+	return nil
+}
+
+// applyContentChangeLocked applies a single change. s.mu must be held.
+func (s *Store) applyContentChangeLocked(change domain.ContentChange, audit *domain.AuditEntry) error {
+	switch change.Table {
+	case "object_list":
+		before, exists := s.objectLists[change.ID]
+		if err := checkContentBefore(change, exists, &before, util.ErrObjectListNotFound); err != nil {
+			return err
+		}
+		switch change.Action {
+		case domain.AuditActionDelete:
+			if err := s.checkObjectListUnusedLocked(change.ID); err != nil {
+				return err
+			}
+			s.deleteObjectListLocked(change.ID)
+			return s.recordChangeLocked(audit, change.Action, change.Table, change.ID, before, nil)
+		case domain.AuditActionCreate:
+			s.nextObjectListID = max(s.nextObjectListID, change.ID)
+			s.objectLists[change.ID] = *change.After.(*domain.ObjectList)
+			return s.recordChangeLocked(audit, change.Action, change.Table, change.ID, nil, change.After)
+		}
+		s.objectLists[change.ID] = *change.After.(*domain.ObjectList)
+		return s.recordChangeLocked(audit, change.Action, change.Table, change.ID, before, change.After)
+
+	case "item_list":
+		itemList, exists := s.itemLists[change.ID]
+		before := s.itemDefinitionLocked(itemList)
+		if err := checkContentBefore(change, exists, &before, util.ErrItemListNotFound); err != nil {
+			return err
+		}
+		switch change.Action {
+		case domain.AuditActionDelete:
+			if err := s.checkItemListUnusedLocked(change.ID); err != nil {
+				return err
+			}
+			s.deleteItemListLocked(change.ID)
+			return s.recordChangeLocked(audit, change.Action, change.Table, change.ID, before, nil)
+		case domain.AuditActionCreate:
+			s.nextItemListID = max(s.nextItemListID, change.ID)
+		}
+		after := *change.After.(*domain.ItemDefinition)
+		s.storeItemDefinitionLocked(itemList.ObjectID, &after)
+		if change.Action == domain.AuditActionCreate {
+			return s.recordChangeLocked(audit, change.Action, change.Table, change.ID, nil, &after)
+		}
+		return s.recordChangeLocked(audit, change.Action, change.Table, change.ID, before, &after)
+
+	case "entity_list":
+		before, exists := s.entityLists[change.ID]
+		if err := checkContentBefore(change, exists, &before, util.ErrEntityListNotFound); err != nil {
+			return err
+		}
+		switch change.Action {
+		case domain.AuditActionDelete:
+			s.deleteEntityListLocked(change.ID)
+			return s.recordChangeLocked(audit, change.Action, change.Table, change.ID, before, nil)
+		case domain.AuditActionCreate:
+			s.nextEntityListID = max(s.nextEntityListID, change.ID)
+			s.entityLists[change.ID] = *change.After.(*domain.EntityList)
+			return s.recordChangeLocked(audit, change.Action, change.Table, change.ID, nil, change.After)
+		}
+		s.entityLists[change.ID] = *change.After.(*domain.EntityList)
+		return s.recordChangeLocked(audit, change.Action, change.Table, change.ID, before, change.After)
+	}
+	return fmt.Errorf("unknown content table %q", change.Table)
+}
+
+// checkContentBefore checks that the stored definition current matches the state
+// change was planned against: absent for a creation, equal to Before otherwise.
+func checkContentBefore[T comparable](change domain.ContentChange, exists bool, current *T, notFound error) error {
+	if change.Action == domain.AuditActionCreate {
+		if exists {
+			return util.ErrContentChanged
+		}
+		return nil
+	}
+	if !exists {
+		return notFound
+	}
+	if *current != *change.Before.(*T) {
+		return util.ErrContentChanged
+	}
+	return nil
+}
+
+// itemDefinitionLocked adds the ObjectList of its object to an item definition. s.mu must be held.
+func (s *Store) itemDefinitionLocked(itemList domain.ItemList) domain.ItemDefinition {
+	return domain.ItemDefinition{ItemList: itemList, ObjectListID: s.objects[itemList.ObjectID].ObjectListID}
+}
+
+// bindObjectLocked returns an object of objectListID for an item definition: objectID
+// if it already is one, otherwise a new object. 0 as objectListID gives no object.
+// s.mu must be held.
+func (s *Store) bindObjectLocked(objectID, objectListID int) int {
+	if objectListID == 0 {
+		return 0
+	}
+	if object, ok := s.objects[objectID]; ok && object.ObjectListID == objectListID {
+		return objectID
+	}
+	s.nextObjectID++
+	s.objects[s.nextObjectID] = domain.Object{ID: s.nextObjectID, ObjectListID: objectListID}
+	return s.nextObjectID
+}
+
+// sortedByID returns the values of a table ordered by key.
+func sortedByID[T any](table map[int]T) []T {
+	ids := slices.Sorted(maps.Keys(table))
+	values := make([]T, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, table[id])
+	}
+	return values
+}
@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"sort"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// EntityListRepositoryMemory implements domain.EntityListRepository in memory.
+type EntityListRepositoryMemory struct {
+	store *Store
+}
+
+var _ domain.EntityListRepository = (*EntityListRepositoryMemory)(nil)
+
+// NewEntityListRepositoryMemory creates a new EntityListRepositoryMemory.
+func NewEntityListRepositoryMemory(store *Store) *EntityListRepositoryMemory {
+	return &EntityListRepositoryMemory{store: store}
+}
+
+// GetEntityListByID retrieves an entity template by its ID.
+func (r *EntityListRepositoryMemory) GetEntityListByID(id int) (*domain.EntityList, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	entityList, ok := r.store.entityLists[id]
+	if !ok {
+		return nil, util.ErrEntityListNotFound
+	}
+	return &entityList, nil
+}
+
+// GetAllEntityLists retrieves all entity templates ordered by ID.
+func (r *EntityListRepositoryMemory) GetAllEntityLists() ([]domain.EntityList, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	entityLists := make([]domain.EntityList, 0, len(r.store.entityLists))
+	for _, entityList := range r.store.entityLists {
+		entityLists = append(entityLists, entityList)
+	}
+	sort.Slice(entityLists, func(i, j int) bool { return entityLists[i].ID < entityLists[j].ID })
+	return entityLists, nil
+}
+
+// CreateEntityList stores a new entity template, assigns its ID and records it in the audit log.
+func (r *EntityListRepositoryMemory) CreateEntityList(entityList *domain.EntityList, audit *domain.AuditEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.nextEntityListID++
+	entityList.ID = r.store.nextEntityListID
+	if err := r.store.recordChangeLocked(audit, domain.AuditActionCreate, "entity_list", entityList.ID, nil, entityList); err != nil {
+		return err
+	}
+	r.store.entityLists[entityList.ID] = *entityList
+	return nil
+}
+
+// UpdateEntityList replaces an entity template and records the change in the audit log.
+func (r *EntityListRepositoryMemory) UpdateEntityList(entityList *domain.EntityList, audit *domain.AuditEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	before, ok := r.store.entityLists[entityList.ID]
+	if !ok {
+		return util.ErrEntityListNotFound
+	}
+	if err := r.store.recordChangeLocked(audit, domain.AuditActionUpdate, "entity_list", entityList.ID, before, entityList); err != nil {
+		return err
+	}
+	r.store.entityLists[entityList.ID] = *entityList
+	return nil
+}
+
+// DeleteEntityList removes an entity template and, like the ON DELETE CASCADE in
+// PostgreSQL, its entities, and records it in the audit log.
+func (r *EntityListRepositoryMemory) DeleteEntityList(id int, audit *domain.AuditEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	before, ok := r.store.entityLists[id]
+	if !ok {
+		return util.ErrEntityListNotFound
+	}
+	if err := r.store.recordChangeLocked(audit, domain.AuditActionDelete, "entity_list", id, before, nil); err != nil {
+		return err
+	}
+	r.store.deleteEntityListLocked(id)
+	return nil
+}
+
+// deleteEntityListLocked removes an entity template and its entities. s.mu must be held.
+func (s *Store) deleteEntityListLocked(id int) {
+	delete(s.entityLists, id)
+	for entityID, entity := range s.entities {
+		if entity.EntityListID == id {
+			delete(s.entities, entityID)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"sort"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// EntityRepositoryMemory implements domain.EntityRepository in memory.
+type EntityRepositoryMemory struct {
+	store *Store
+}
+
+var _ domain.EntityRepository = (*EntityRepositoryMemory)(nil)
+
+// NewEntityRepositoryMemory creates a new EntityRepositoryMemory.
+func NewEntityRepositoryMemory(store *Store) *EntityRepositoryMemory {
+	return &EntityRepositoryMemory{store: store}
+}
+
+// CreateEntity stores a new entity and assigns its ID.
+func (r *EntityRepositoryMemory) CreateEntity(entity *domain.Entity) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.nextEntityID++
+	entity.ID = r.store.nextEntityID
+	r.store.entities[entity.ID] = *entity
+	return nil
+}
+
+// GetEntityByID retrieves an entity by its ID.
+func (r *EntityRepositoryMemory) GetEntityByID(id int) (*domain.Entity, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	entity, ok := r.store.entities[id]
+	if !ok {
+		return nil, util.ErrEntityNotFound
+	}
+	return &entity, nil
+}
+
+// GetAllEntities retrieves all entities ordered by ID.
+func (r *EntityRepositoryMemory) GetAllEntities() ([]domain.Entity, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	entities := make([]domain.Entity, 0, len(r.store.entities))
+	for _, entity := range r.store.entities {
+		entities = append(entities, entity)
+	}
+	sort.Slice(entities, func(i, j int) bool { return entities[i].ID < entities[j].ID })
+	return entities, nil
+}
+
+// UpdateEntity overwrites an existing entity.
+func (r *EntityRepositoryMemory) UpdateEntity(entity *domain.Entity) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.entities[entity.ID]; !ok {
+		return util.ErrEntityNotFound
+	}
+	r.store.entities[entity.ID] = *entity
+	return nil
+}
+
+// DeleteEntity removes an entity by its ID.
+func (r *EntityRepositoryMemory) DeleteEntity(id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.entities[id]; !ok {
+		return util.ErrEntityNotFound
+	}
+	delete(r.store.entities, id)
+	return nil
+}
+
+// SpawnEntity stores a new object of objectListID and an entity bound to it.
+func (r *EntityRepositoryMemory) SpawnEntity(entity *domain.Entity, objectListID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.nextObjectID++
+	object := domain.Object{ID: r.store.nextObjectID, ObjectListID: objectListID}
+	r.store.objects[object.ID] = object
+
+	r.store.nextEntityID++
+	entity.ID = r.store.nextEntityID
+	entity.ObjectID = object.ID
+	r.store.entities[entity.ID] = *entity
+	return nil
+}
+
+// DespawnEntity removes an entity together with its object.
+func (r *EntityRepositoryMemory) DespawnEntity(id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	entity, ok := r.store.entities[id]
+	if !ok {
+		return util.ErrEntityNotFound
+	}
+	delete(r.store.entities, id)
+	delete(r.store.objects, entity.ObjectID)
+	return nil
+}
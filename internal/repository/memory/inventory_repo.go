@@ -0,0 +1,122 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// InventoryRepositoryMemory implements domain.InventoryRepository in memory.
+type InventoryRepositoryMemory struct {
+	store *Store
+}
+
+var _ domain.InventoryRepository = (*InventoryRepositoryMemory)(nil)
+
+// NewInventoryRepositoryMemory creates a new InventoryRepositoryMemory.
+func NewInventoryRepositoryMemory(store *Store) *InventoryRepositoryMemory {
+	return &InventoryRepositoryMemory{store: store}
+}
+
+// AddInventoryEntry stores a new inventory entry, generating its ID if empty. It fails
+// with util.ErrInventorySlotTaken if the slot of the entity is occupied.
+func (r *InventoryRepositoryMemory) AddInventoryEntry(entry *domain.Inventory) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if entry.ID == "" {
+		id, err := util.NewUUID()
+		if err != nil {
+			return fmt.Errorf("failed to add inventory entry: %w", err)
+		}
+		entry.ID = id
+	}
+	if err := r.store.checkInventorySlotsLocked(nil, []domain.Inventory{*entry}); err != nil {
+		return err
+	}
+	r.store.inventory[entry.ID] = *entry
+	return nil
+}
+
+// GetInventoryByEntityID retrieves all inventory entries of an entity ordered by slot.
+func (r *InventoryRepositoryMemory) GetInventoryByEntityID(entityID string) ([]domain.Inventory, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var entries []domain.Inventory
+	for _, entry := range r.store.inventory {
+		if entry.EntityID == entityID {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Slot < entries[j].Slot })
+	return entries, nil
+}
+
+// RemoveInventoryEntry removes an inventory entry by its ID.
+func (r *InventoryRepositoryMemory) RemoveInventoryEntry(id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.inventory[id]; !ok {
+		return util.ErrInventoryEntryNotFound
+	}
+	delete(r.store.inventory, id)
+	return nil
+}
+
+// SaveInventoryChanges applies removals, creates new items, inserts and updates
+// entries and deletes freed items as one atomic step.
+func (r *InventoryRepositoryMemory) SaveInventoryChanges(changes domain.InventoryChanges) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.saveInventoryChangesLocked(changes)
+}
+
+// PickUpEntity deletes a world entity and applies changes as one atomic step. The
+// entity's object passes to the first of changes.NewItems, or is deleted with the
+// entity if the pickup only topped up existing stacks.
+func (r *InventoryRepositoryMemory) PickUpEntity(entityID int, changes domain.InventoryChanges) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	entity, ok := r.store.entities[entityID]
+	if !ok {
+		return util.ErrEntityNotFound
+	}
+	if len(changes.NewItems) > 0 {
+		changes.NewItems[0].ObjectID = entity.ObjectID
+	}
+	if err := r.store.saveInventoryChangesLocked(changes); err != nil {
+		return err
+	}
+
+	delete(r.store.entities, entityID)
+	if len(changes.NewItems) == 0 {
+		delete(r.store.objects, entity.ObjectID)
+	}
+	return nil
+}
+
+// DropItem applies changes and spawns entity with a new object of objectListID as one atomic step.
+func (r *InventoryRepositoryMemory) DropItem(changes domain.InventoryChanges, entity *domain.Entity, objectListID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := r.store.saveInventoryChangesLocked(changes); err != nil {
+		return err
+	}
+
+	r.store.nextObjectID++
+	object := domain.Object{ID: r.store.nextObjectID, ObjectListID: objectListID}
+	r.store.objects[object.ID] = object
+
+	r.store.nextEntityID++
+	entity.ID = r.store.nextEntityID
+	entity.ObjectID = object.ID
+	r.store.entities[entity.ID] = *entity
+	return nil
+}
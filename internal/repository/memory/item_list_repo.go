@@ -0,0 +1,163 @@
+package memory
+
+import (
+	"sort"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// ItemListRepositoryMemory implements domain.ItemListRepository in memory.
+type ItemListRepositoryMemory struct {
+	store *Store
+}
+
+var _ domain.ItemListRepository = (*ItemListRepositoryMemory)(nil)
+
+// NewItemListRepositoryMemory creates a new ItemListRepositoryMemory.
+func NewItemListRepositoryMemory(store *Store) *ItemListRepositoryMemory {
+	return &ItemListRepositoryMemory{store: store}
+}
+
+// GetItemListByID retrieves an item definition by its ID.
+func (r *ItemListRepositoryMemory) GetItemListByID(id int) (*domain.ItemList, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	itemList, ok := r.store.itemLists[id]
+	if !ok {
+		return nil, util.ErrItemListNotFound
+	}
+	return &itemList, nil
+}
+
+// GetAllItemLists retrieves all item definitions ordered by ID.
+func (r *ItemListRepositoryMemory) GetAllItemLists() ([]domain.ItemList, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	itemLists := make([]domain.ItemList, 0, len(r.store.itemLists))
+	for _, itemList := range r.store.itemLists {
+		itemLists = append(itemLists, itemList)
+	}
+	sort.Slice(itemLists, func(i, j int) bool { return itemLists[i].ID < itemLists[j].ID })
+	return itemLists, nil
+}
+
+// GetItemListByObjectListID finds the item definition with the lowest ID whose object is of objectListID.
+func (r *ItemListRepositoryMemory) GetItemListByObjectListID(objectListID int) (*domain.ItemList, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var found *domain.ItemList
+	for _, itemList := range r.store.itemLists {
+		object, ok := r.store.objects[itemList.ObjectID]
+		if !ok || object.ObjectListID != objectListID {
+			continue
+		}
+		if found == nil || itemList.ID < found.ID {
+			itemList := itemList
+			found = &itemList
+		}
+	}
+	if found == nil {
+		return nil, util.ErrItemListNotFound
+	}
+	return found, nil
+}
+
+// CreateItemList stores a new item definition together with an object of its
+// ObjectList, assigns its ID and records it in the audit log.
+func (r *ItemListRepositoryMemory) CreateItemList(definition *domain.ItemDefinition, audit *domain.AuditEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.nextItemListID++
+	definition.ID = r.store.nextItemListID
+	r.store.storeItemDefinitionLocked(0, definition)
+	return r.store.recordChangeLocked(audit, domain.AuditActionCreate, "item_list", definition.ID, nil, definition)
+}
+
+// UpdateItemList replaces an item definition, moving it to an object of its new
+// ObjectList if that changed, and records the change in the audit log.
+func (r *ItemListRepositoryMemory) UpdateItemList(definition *domain.ItemDefinition, audit *domain.AuditEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	itemList, ok := r.store.itemLists[definition.ID]
+	if !ok {
+		return util.ErrItemListNotFound
+	}
+	before := r.store.itemDefinitionLocked(itemList)
+	r.store.storeItemDefinitionLocked(itemList.ObjectID, definition)
+	return r.store.recordChangeLocked(audit, domain.AuditActionUpdate, "item_list", definition.ID, &before, definition)
+}
+
+// DeleteItemList removes an item definition without items and records it in the audit log.
+func (r *ItemListRepositoryMemory) DeleteItemList(id int, audit *domain.AuditEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	itemList, ok := r.store.itemLists[id]
+	if !ok {
+		return util.ErrItemListNotFound
+	}
+	if err := r.store.checkItemListUnusedLocked(id); err != nil {
+		return err
+	}
+	before := r.store.itemDefinitionLocked(itemList)
+	r.store.deleteItemListLocked(id)
+	return r.store.recordChangeLocked(audit, domain.AuditActionDelete, "item_list", id, &before, nil)
+}
+
+// storeItemDefinitionLocked stores an item definition whose previous object was
+// objectID. The definition keeps that object while it is of the requested
+// ObjectList; otherwise it moves to a new one and the old one is deleted once
+// unused. s.mu must be held.
+func (s *Store) storeItemDefinitionLocked(objectID int, definition *domain.ItemDefinition) {
+	definition.ObjectID = s.bindObjectLocked(objectID, definition.ObjectListID)
+	s.itemLists[definition.ID] = definition.ItemList
+	if objectID != definition.ObjectID {
+		s.deleteUnusedObjectLocked(objectID)
+	}
+}
+
+// deleteItemListLocked removes an item definition together with its object, once
+// unused. s.mu must be held.
+func (s *Store) deleteItemListLocked(id int) {
+	objectID := s.itemLists[id].ObjectID
+	delete(s.itemLists, id)
+	s.deleteUnusedObjectLocked(objectID)
+}
+
+// deleteUnusedObjectLocked removes an object unless an item definition, item or
+// entity is made of it. s.mu must be held.
+func (s *Store) deleteUnusedObjectLocked(id int) {
+	for _, itemList := range s.itemLists {
+		if itemList.ObjectID == id {
+			return
+		}
+	}
+	for _, item := range s.items {
+		if item.ObjectID == id {
+			return
+		}
+	}
+	for _, entity := range s.entities {
+		if entity.ObjectID == id {
+			return
+		}
+	}
+	delete(s.objects, id)
+}
+
+// checkItemListUnusedLocked fails with util.ErrDefinitionInUse while items of
+// item definition id exist. s.mu must be held.
+func (s *Store) checkItemListUnusedLocked(id int) error {
+	for _, item := range s.items {
+		if item.ItemListID == id {
+			return util.ErrDefinitionInUse
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"strconv"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// ItemRepositoryMemory implements domain.ItemRepository in memory.
+type ItemRepositoryMemory struct {
+	store *Store
+}
+
+var _ domain.ItemRepository = (*ItemRepositoryMemory)(nil)
+
+// NewItemRepositoryMemory creates a new ItemRepositoryMemory.
+func NewItemRepositoryMemory(store *Store) *ItemRepositoryMemory {
+	return &ItemRepositoryMemory{store: store}
+}
+
+// CreateItem stores a new item and assigns its ID.
+func (r *ItemRepositoryMemory) CreateItem(item *domain.Item) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.nextItemID++
+	item.ID = r.store.nextItemID
+	r.store.items[item.ID] = *item
+	return nil
+}
+
+// GetItemByID retrieves an item by its ID.
+func (r *ItemRepositoryMemory) GetItemByID(id int) (*domain.Item, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	item, ok := r.store.items[id]
+	if !ok {
+		return nil, util.ErrItemNotFound
+	}
+	return &item, nil
+}
+
+// DeleteItem removes an item and, like the ON DELETE CASCADE in PostgreSQL,
+// every inventory entry that references it.
+func (r *ItemRepositoryMemory) DeleteItem(id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.items[id]; !ok {
+		return util.ErrItemNotFound
+	}
+	delete(r.store.items, id)
+
+	itemID := strconv.Itoa(id)
+	for entryID, entry := range r.store.inventory {
+		if entry.ItemID == itemID {
+			delete(r.store.inventory, entryID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"sort"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// ObjectListRepositoryMemory implements domain.ObjectListRepository in memory.
+type ObjectListRepositoryMemory struct {
+	store *Store
+}
+
+var _ domain.ObjectListRepository = (*ObjectListRepositoryMemory)(nil)
+
+// NewObjectListRepositoryMemory creates a new ObjectListRepositoryMemory.
+func NewObjectListRepositoryMemory(store *Store) *ObjectListRepositoryMemory {
+	return &ObjectListRepositoryMemory{store: store}
+}
+
+// GetObjectListByID retrieves an object definition by its ID.
+func (r *ObjectListRepositoryMemory) GetObjectListByID(id int) (*domain.ObjectList, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	objectList, ok := r.store.objectLists[id]
+	if !ok {
+		return nil, util.ErrObjectListNotFound
+	}
+	return &objectList, nil
+}
+
+// GetAllObjectLists retrieves all object definitions ordered by ID.
+func (r *ObjectListRepositoryMemory) GetAllObjectLists() ([]domain.ObjectList, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	objectLists := make([]domain.ObjectList, 0, len(r.store.objectLists))
+	for _, objectList := range r.store.objectLists {
+		objectLists = append(objectLists, objectList)
+	}
+	sort.Slice(objectLists, func(i, j int) bool { return objectLists[i].ID < objectLists[j].ID })
+	return objectLists, nil
+}
+
+// CreateObjectList stores a new object definition, assigns its ID and records it in the audit log.
+func (r *ObjectListRepositoryMemory) CreateObjectList(objectList *domain.ObjectList, audit *domain.AuditEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.nextObjectListID++
+	objectList.ID = r.store.nextObjectListID
+	if err := r.store.recordChangeLocked(audit, domain.AuditActionCreate, "object_list", objectList.ID, nil, objectList); err != nil {
+		return err
+	}
+	r.store.objectLists[objectList.ID] = *objectList
+	return nil
+}
+
+// UpdateObjectList replaces an object definition and records the change in the audit log.
+func (r *ObjectListRepositoryMemory) UpdateObjectList(objectList *domain.ObjectList, audit *domain.AuditEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	before, ok := r.store.objectLists[objectList.ID]
+	if !ok {
+		return util.ErrObjectListNotFound
+	}
+	if err := r.store.recordChangeLocked(audit, domain.AuditActionUpdate, "object_list", objectList.ID, before, objectList); err != nil {
+		return err
+	}
+	r.store.objectLists[objectList.ID] = *objectList
+	return nil
+}
+
+// DeleteObjectList removes an object definition that nothing refers to and records it in the audit log.
+func (r *ObjectListRepositoryMemory) DeleteObjectList(id int, audit *domain.AuditEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	before, ok := r.store.objectLists[id]
+	if !ok {
+		return util.ErrObjectListNotFound
+	}
+	if err := r.store.checkObjectListUnusedLocked(id); err != nil {
+		return err
+	}
+	if err := r.store.recordChangeLocked(audit, domain.AuditActionDelete, "object_list", id, before, nil); err != nil {
+		return err
+	}
+	r.store.deleteObjectListLocked(id)
+	return nil
+}
+
+// checkObjectListUnusedLocked fails with util.ErrDefinitionInUse while an entity
+// template, or an object that an item definition, item or entity is made of,
+// refers to object definition id. s.mu must be held.
+func (s *Store) checkObjectListUnusedLocked(id int) error {
+	for _, entityList := range s.entityLists {
+		if entityList.ObjectListID == id {
+			return util.ErrDefinitionInUse
+		}
+	}
+	used := make(map[int]bool)
+	for _, itemList := range s.itemLists {
+		used[itemList.ObjectID] = true
+	}
+	for _, item := range s.items {
+		used[item.ObjectID] = true
+	}
+	for _, entity := range s.entities {
+		used[entity.ObjectID] = true
+	}
+	for _, object := range s.objects {
+		if object.ObjectListID == id && used[object.ID] {
+			return util.ErrDefinitionInUse
+		}
+	}
+	return nil
+}
+
+// deleteObjectListLocked removes an object definition together with its objects,
+// which checkObjectListUnusedLocked has found unused. s.mu must be held.
+func (s *Store) deleteObjectListLocked(id int) {
+	for objectID, object := range s.objects {
+		if object.ObjectListID == id {
+			delete(s.objects, objectID)
+		}
+	}
+	delete(s.objectLists, id)
+}
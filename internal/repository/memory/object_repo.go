@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// ObjectRepositoryMemory implements domain.ObjectRepository in memory.
+type ObjectRepositoryMemory struct {
+	store *Store
+}
+
+var _ domain.ObjectRepository = (*ObjectRepositoryMemory)(nil)
+
+// NewObjectRepositoryMemory creates a new ObjectRepositoryMemory.
+func NewObjectRepositoryMemory(store *Store) *ObjectRepositoryMemory {
+	return &ObjectRepositoryMemory{store: store}
+}
+
+// CreateObject stores a new object and assigns its ID.
+func (r *ObjectRepositoryMemory) CreateObject(object *domain.Object) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.nextObjectID++
+	object.ID = r.store.nextObjectID
+	r.store.objects[object.ID] = *object
+	return nil
+}
+
+// GetObjectByID retrieves an object by its ID.
+func (r *ObjectRepositoryMemory) GetObjectByID(id int) (*domain.Object, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	object, ok := r.store.objects[id]
+	if !ok {
+		return nil, util.ErrObjectNotFound
+	}
+	return &object, nil
+}
+
+// DeleteObject removes an object by its ID.
+func (r *ObjectRepositoryMemory) DeleteObject(id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.objects[id]; !ok {
+		return util.ErrObjectNotFound
+	}
+	delete(r.store.objects, id)
+	return nil
+}
@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// PlayerMovementRepositoryMemory implements domain.PlayerMovementRepository in memory.
+type PlayerMovementRepositoryMemory struct {
+	store *Store
+}
+
+var _ domain.PlayerMovementRepository = (*PlayerMovementRepositoryMemory)(nil)
+
+// NewPlayerMovementRepositoryMemory creates a new PlayerMovementRepositoryMemory.
+func NewPlayerMovementRepositoryMemory(store *Store) *PlayerMovementRepositoryMemory {
+	return &PlayerMovementRepositoryMemory{store: store}
+}
+
+// SavePlayerLocation inserts or updates a player's location.
+func (r *PlayerMovementRepositoryMemory) SavePlayerLocation(location *domain.Location) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	location.UpdatedAt = time.Now()
+	r.store.locations[location.PlayerID] = *location
+	return nil
+}
+
+// GetPlayerLocation retrieves a player's location by player ID.
+func (r *PlayerMovementRepositoryMemory) GetPlayerLocation(playerID string) (*domain.Location, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	location, ok := r.store.locations[playerID]
+	if !ok {
+		return nil, util.ErrPlayerLocationNotFound
+	}
+	return &location, nil
+}
+
+// GetAllPlayerLocations retrieves all player locations ordered by player ID.
+func (r *PlayerMovementRepositoryMemory) GetAllPlayerLocations() ([]domain.Location, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	locations := make([]domain.Location, 0, len(r.store.locations))
+	for _, location := range r.store.locations {
+		locations = append(locations, location)
+	}
+	sort.Slice(locations, func(i, j int) bool { return locations[i].PlayerID < locations[j].PlayerID })
+	return locations, nil
+}
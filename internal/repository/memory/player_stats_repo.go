@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"time"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// PlayerStatsRepositoryMemory implements domain.PlayerStatsRepository in memory.
+type PlayerStatsRepositoryMemory struct {
+	store *Store
+}
+
+var _ domain.PlayerStatsRepository = (*PlayerStatsRepositoryMemory)(nil)
+
+// NewPlayerStatsRepositoryMemory creates a new PlayerStatsRepositoryMemory.
+func NewPlayerStatsRepositoryMemory(store *Store) *PlayerStatsRepositoryMemory {
+	return &PlayerStatsRepositoryMemory{store: store}
+}
+
+// SavePlayerStats inserts or updates a player's stats.
+func (r *PlayerStatsRepositoryMemory) SavePlayerStats(stats *domain.PlayerStats) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stats.UpdatedAt = time.Now()
+	r.store.playerStats[stats.PlayerID] = *stats
+	return nil
+}
+
+// GetPlayerStats retrieves a player's stats by player ID.
+func (r *PlayerStatsRepositoryMemory) GetPlayerStats(playerID string) (*domain.PlayerStats, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	stats, ok := r.store.playerStats[playerID]
+	if !ok {
+		return nil, util.ErrPlayerStatsNotFound
+	}
+	return &stats, nil
+}
@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// RefreshTokenRepositoryMemory implements domain.RefreshTokenRepository in memory.
+type RefreshTokenRepositoryMemory struct {
+	store *Store
+}
+
+var _ domain.RefreshTokenRepository = (*RefreshTokenRepositoryMemory)(nil)
+
+// NewRefreshTokenRepositoryMemory creates a new RefreshTokenRepositoryMemory.
+func NewRefreshTokenRepositoryMemory(store *Store) *RefreshTokenRepositoryMemory {
+	return &RefreshTokenRepositoryMemory{store: store}
+}
+
+// CreateRefreshToken stores a token that starts a new family, assigning its ID and family.
+func (r *RefreshTokenRepositoryMemory) CreateRefreshToken(token *domain.RefreshToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	familyID, err := util.NewUUID()
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	token.FamilyID = familyID
+	return r.store.insertRefreshTokenLocked(token)
+}
+
+// RotateRefreshToken exchanges the token with tokenHash for next.
+func (r *RefreshTokenRepositoryMemory) RotateRefreshToken(tokenHash string, next *domain.RefreshToken, now time.Time) (*domain.RefreshToken, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.refreshTokens[tokenHash]
+	if !ok {
+		return nil, util.ErrInvalidToken
+	}
+	switch {
+	case current.RevokedAt != nil:
+		return nil, util.ErrInvalidToken
+	case current.RotatedAt != nil:
+		r.store.revokeRefreshTokenFamilyLocked(current.FamilyID, now)
+		return nil, util.ErrRefreshTokenReused
+	case !now.Before(current.ExpiresAt):
+		return nil, util.ErrInvalidToken
+	}
+
+	next.UserID, next.FamilyID = current.UserID, current.FamilyID
+	if err := r.store.insertRefreshTokenLocked(next); err != nil {
+		return nil, err
+	}
+	rotatedAt := now
+	current.RotatedAt = &rotatedAt
+	r.store.refreshTokens[tokenHash] = current
+	return &current, nil
+}
+
+// RevokeRefreshTokenFamily revokes every token of the family of the token with tokenHash.
+func (r *RefreshTokenRepositoryMemory) RevokeRefreshTokenFamily(tokenHash string, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.refreshTokens[tokenHash]
+	if !ok {
+		return util.ErrInvalidToken
+	}
+	r.store.revokeRefreshTokenFamilyLocked(current.FamilyID, now)
+	return nil
+}
+
+// insertRefreshTokenLocked assigns a token its ID and creation time and stores it. s.mu must be held.
+func (s *Store) insertRefreshTokenLocked(token *domain.RefreshToken) error {
+	if _, ok := s.users[token.UserID]; !ok {
+		return util.ErrUserNotFound
+	}
+	id, err := util.NewUUID()
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	token.ID = id
+	token.CreatedAt = time.Now()
+	s.refreshTokens[token.TokenHash] = *token
+	return nil
+}
+
+// revokeRefreshTokenFamilyLocked revokes the tokens of a family that are not revoked yet. s.mu must be held.
+func (s *Store) revokeRefreshTokenFamilyLocked(familyID string, now time.Time) {
+	for hash, token := range s.refreshTokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			revokedAt := now
+			token.RevokedAt = &revokedAt
+			s.refreshTokens[hash] = token
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"sort"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// RoleRepositoryMemory implements domain.RoleRepository in memory.
+type RoleRepositoryMemory struct {
+	store *Store
+}
+
+var _ domain.RoleRepository = (*RoleRepositoryMemory)(nil)
+
+// NewRoleRepositoryMemory creates a new RoleRepositoryMemory.
+func NewRoleRepositoryMemory(store *Store) *RoleRepositoryMemory {
+	return &RoleRepositoryMemory{store: store}
+}
+
+// GetUserRoles returns the roles of a user in lexical order.
+func (r *RoleRepositoryMemory) GetUserRoles(userID string) ([]string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	roles := make([]string, 0, len(r.store.userRoles[userID]))
+	for role := range r.store.userRoles[userID] {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles, nil
+}
+
+// GrantRole gives a user a role.
+func (r *RoleRepositoryMemory) GrantRole(userID, role string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[userID]; !ok {
+		return util.ErrUserNotFound
+	}
+	if r.store.userRoles[userID] == nil {
+		r.store.userRoles[userID] = make(map[string]bool)
+	}
+	r.store.userRoles[userID][role] = true
+	return nil
+}
+
+// RevokeRole takes a role from a user.
+func (r *RoleRepositoryMemory) RevokeRole(userID, role string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.userRoles[userID], role)
+	return nil
+}
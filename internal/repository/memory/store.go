@@ -0,0 +1,185 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// worldKey addresses a terrain point by its grid coordinates.
+type worldKey struct {
+	x, y int
+}
+
+// Store holds all in-memory tables. Repositories created from the same Store
+// share data, and a single lock keeps multi-table operations consistent.
+type Store struct {
+	mu sync.RWMutex
+
+	users         map[string]domain.User         // by ID
+	userIDsByName map[string]string              // username -> ID
+	userRoles     map[string]map[string]bool     // Роли по ID пользователя
+	refreshTokens map[string]domain.RefreshToken // По хешу токена
+	locations     map[string]domain.Location
+	playerStats   map[string]domain.PlayerStats
+	entities      map[int]domain.Entity
+	entityLists   map[int]domain.EntityList
+	objectLists   map[int]domain.ObjectList
+	objects       map[int]domain.Object
+	items         map[int]domain.Item
+	itemLists     map[int]domain.ItemList
+	inventory     map[string]domain.Inventory
+	world         map[worldKey]domain.World
+	auditLog      []domain.AuditEntry // В порядке добавления
+
+	nextEntityID     int
+	nextObjectID     int
+	nextItemID       int
+	nextObjectListID int
+	nextItemListID   int
+	nextEntityListID int
+}
+
+// NewStore creates an empty Store.
+func NewStore() *Store {
+	return &Store{
+		users:         make(map[string]domain.User),
+		userIDsByName: make(map[string]string),
+		userRoles:     make(map[string]map[string]bool),
+		refreshTokens: make(map[string]domain.RefreshToken),
+		locations:     make(map[string]domain.Location),
+		playerStats:   make(map[string]domain.PlayerStats),
+		entities:      make(map[int]domain.Entity),
+		entityLists:   make(map[int]domain.EntityList),
+		objectLists:   make(map[int]domain.ObjectList),
+		objects:       make(map[int]domain.Object),
+		items:         make(map[int]domain.Item),
+		itemLists:     make(map[int]domain.ItemList),
+		inventory:     make(map[string]domain.Inventory),
+		world:         make(map[worldKey]domain.World),
+	}
+}
+
+// saveInventoryChangesLocked validates and applies inventory changes so that
+// either all or none of them take effect. s.mu must be held.
+func (s *Store) saveInventoryChangesLocked(changes domain.InventoryChanges) error {
+	for _, id := range changes.Removed {
+		if _, ok := s.inventory[id]; !ok {
+			return util.ErrInventoryEntryNotFound
+		}
+	}
+	for i := range changes.NewItems {
+		changes.NewItems[i].ID = s.nextItemID + 1 + i
+	}
+	saved, err := changes.SavedWithNewItems()
+	if err != nil {
+		return err
+	}
+	for i := range saved {
+		if saved[i].ID == "" {
+			id, err := util.NewUUID()
+			if err != nil {
+				return fmt.Errorf("failed to save inventory entry: %w", err)
+			}
+			saved[i].ID = id
+		}
+	}
+	if err := s.checkInventorySlotsLocked(changes.Removed, saved); err != nil {
+		return err
+	}
+
+	for _, id := range changes.Removed {
+		delete(s.inventory, id)
+	}
+	for _, item := range changes.NewItems {
+		s.items[item.ID] = item
+	}
+	s.nextItemID += len(changes.NewItems)
+	for _, entry := range saved {
+		s.inventory[entry.ID] = entry
+	}
+	for _, id := range changes.FreedItems {
+		s.deleteFreedItemLocked(id)
+	}
+	return nil
+}
+
+// checkInventorySlotsLocked fails with util.ErrInventorySlotTaken if removing and saving
+// the given entries would leave two entries in one slot of an entity, like the unique
+// (entity_id, slot) constraint of the inventory table. s.mu must be held.
+func (s *Store) checkInventorySlotsLocked(removed []string, saved []domain.Inventory) error {
+	type slotKey struct {
+		entityID string
+		slot     int
+	}
+	entries := make(map[string]domain.Inventory, len(s.inventory))
+	for id, entry := range s.inventory {
+		entries[id] = entry
+	}
+	for _, id := range removed {
+		delete(entries, id)
+	}
+	for _, entry := range saved {
+		entries[entry.ID] = entry
+	}
+
+	slots := make(map[slotKey]bool, len(entries))
+	for _, entry := range entries {
+		key := slotKey{entry.EntityID, entry.Slot}
+		if slots[key] {
+			return util.ErrInventorySlotTaken
+		}
+		slots[key] = true
+	}
+	return nil
+}
+
+// deleteFreedItemLocked deletes an item together with its object unless an inventory
+// entry still refers to it. s.mu must be held.
+func (s *Store) deleteFreedItemLocked(id int) {
+	itemID := strconv.Itoa(id)
+	for _, entry := range s.inventory {
+		if entry.ItemID == itemID {
+			return
+		}
+	}
+	if item, ok := s.items[id]; ok {
+		delete(s.items, id)
+		delete(s.objects, item.ObjectID)
+	}
+}
+
+// recordChangeLocked completes audit with a change of a definition and appends it
+// to the audit log. before and after are the record's states; nil means it did not
+// exist. s.mu must be held.
+func (s *Store) recordChangeLocked(audit *domain.AuditEntry, action, table string, recordID int, before, after interface{}) error {
+	audit.Action, audit.TableName, audit.RecordID = action, table, recordID
+	var err error
+	if audit.Before, err = auditSnapshot(before); err != nil {
+		return err
+	}
+	if audit.After, err = auditSnapshot(after); err != nil {
+		return err
+	}
+	audit.ID = int64(len(s.auditLog) + 1)
+	audit.CreatedAt = time.Now()
+	s.auditLog = append(s.auditLog, *audit)
+	return nil
+}
+
+// auditSnapshot encodes a record for the audit log; nil gives an empty snapshot.
+func auditSnapshot(record interface{}) (string, error) {
+	if record == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit snapshot: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// UserRepositoryMemory implements domain.UserRepository in memory.
+type UserRepositoryMemory struct {
+	store *Store
+}
+
+var _ domain.UserRepository = (*UserRepositoryMemory)(nil)
+
+// NewUserRepositoryMemory creates a new UserRepositoryMemory.
+func NewUserRepositoryMemory(store *Store) *UserRepositoryMemory {
+	return &UserRepositoryMemory{store: store}
+}
+
+// CreateUser stores a new user, assigning its ID and creation time.
+func (r *UserRepositoryMemory) CreateUser(user *domain.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.userIDsByName[user.Username]; exists {
+		return util.ErrUserAlreadyExists
+	}
+	id, err := util.NewUUID()
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	user.ID = id
+	user.CreatedAt = time.Now()
+	r.store.users[user.ID] = *user
+	r.store.userIDsByName[user.Username] = user.ID
+	return nil
+}
+
+// GetUserByUsername retrieves a user by their username.
+func (r *UserRepositoryMemory) GetUserByUsername(username string) (*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	id, ok := r.store.userIDsByName[username]
+	if !ok {
+		return nil, util.ErrUserNotFound
+	}
+	user := r.store.users[id]
+	return &user, nil
+}
+
+// GetUserByID retrieves a user by their ID.
+func (r *UserRepositoryMemory) GetUserByID(id string) (*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return nil, util.ErrUserNotFound
+	}
+	return &user, nil
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// WorldRepositoryMemory implements domain.WorldRepository in memory.
+type WorldRepositoryMemory struct {
+	store *Store
+}
+
+var _ domain.WorldRepository = (*WorldRepositoryMemory)(nil)
+
+// NewWorldRepositoryMemory creates a new WorldRepositoryMemory.
+func NewWorldRepositoryMemory(store *Store) *WorldRepositoryMemory {
+	return &WorldRepositoryMemory{store: store}
+}
+
+// SaveWorldPoints inserts or updates terrain points by their grid coordinates.
+func (r *WorldRepositoryMemory) SaveWorldPoints(points []domain.World) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.saveLocked(points)
+	return nil
+}
+
+// ReplaceWorldPoints replaces the whole terrain with points.
+func (r *WorldRepositoryMemory) ReplaceWorldPoints(points []domain.World) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.world = make(map[worldKey]domain.World, len(points))
+	r.saveLocked(points)
+	return nil
+}
+
+// saveLocked upserts points into the store. r.store.mu must be held.
+func (r *WorldRepositoryMemory) saveLocked(points []domain.World) {
+	for i := range points {
+		key := worldKey{x: points[i].X, y: points[i].Y}
+		if existing, ok := r.store.world[key]; ok {
+			points[i].ID = existing.ID
+		} else if points[i].ID == "" {
+			points[i].ID = fmt.Sprintf("%d:%d", points[i].X, points[i].Y)
+		}
+		r.store.world[key] = points[i]
+	}
+}
+
+// GetWorldPoint retrieves the terrain point at the given grid coordinates.
+func (r *WorldRepositoryMemory) GetWorldPoint(x, y int) (*domain.World, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	point, ok := r.store.world[worldKey{x: x, y: y}]
+	if !ok {
+		return nil, util.ErrWorldPointNotFound
+	}
+	return &point, nil
+}
+
+// GetAllWorldPoints retrieves all terrain points ordered by Y, then X.
+func (r *WorldRepositoryMemory) GetAllWorldPoints() ([]domain.World, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	points := make([]domain.World, 0, len(r.store.world))
+	for _, point := range r.store.world {
+		points = append(points, point)
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].Y != points[j].Y {
+			return points[i].Y < points[j].Y
+		}
+		return points[i].X < points[j].X
+	})
+	return points, nil
+}
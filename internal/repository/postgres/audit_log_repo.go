@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"anarchy-core/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ContentChangedChannel is the LISTEN/NOTIFY channel on which changes of content
+// definitions are announced; the payload is the name of the changed table.
+const ContentChangedChannel = "content_changed"
+
+// AuditLogRepositoryPostgres implements domain.AuditLogRepository for PostgreSQL.
+type AuditLogRepositoryPostgres struct {
+	db *sqlx.DB
+}
+
+var _ domain.AuditLogRepository = (*AuditLogRepositoryPostgres)(nil)
+
+// NewAuditLogRepositoryPostgres creates a new AuditLogRepositoryPostgres.
+func NewAuditLogRepositoryPostgres(db *sqlx.DB) *AuditLogRepositoryPostgres {
+	return &AuditLogRepositoryPostgres{db: db}
+}
+
+// GetAuditEntries returns a page of the audit log, newest first.
+func (r *AuditLogRepositoryPostgres) GetAuditEntries(limit, offset int) ([]domain.AuditEntry, error) {
+	entries := []domain.AuditEntry{}
+	query := `
+		SELECT id, COALESCE(user_id::text, '') AS user_id, username, action, table_name, record_id,
+			COALESCE(before::text, '') AS before, COALESCE(after::text, '') AS after, created_at
+		FROM audit_log
+		ORDER BY id DESC
+		LIMIT $1 OFFSET $2`
+	if err := r.db.Select(&entries, query, limit, offset); err != nil {
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// recordChange completes audit with a change of a definition, inserts it within tx
+// and announces the change on ContentChangedChannel once tx commits.
+// before and after are the record's states; nil means it did not exist.
+func recordChange(tx *sqlx.Tx, audit *domain.AuditEntry, action, table string, recordID int, before, after interface{}) error {
+	audit.Action, audit.TableName, audit.RecordID = action, table, recordID
+	var err error
+	if audit.Before, err = auditSnapshot(before); err != nil {
+		return err
+	}
+	if audit.After, err = auditSnapshot(after); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO audit_log (user_id, username, action, table_name, record_id, before, after)
+		VALUES (NULLIF($1, '')::uuid, $2, $3, $4, $5, NULLIF($6, '')::jsonb, NULLIF($7, '')::jsonb)
+		RETURNING id, created_at`
+	err = tx.QueryRow(query, audit.UserID, audit.Username, audit.Action, audit.TableName, audit.RecordID, audit.Before, audit.After).
+		Scan(&audit.ID, &audit.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	if _, err := tx.Exec(`SELECT pg_notify($1, $2)`, ContentChangedChannel, table); err != nil {
+		return fmt.Errorf("failed to announce content change: %w", err)
+	}
+	return nil
+}
+
+// auditSnapshot encodes a record for the audit log; nil gives an empty snapshot.
+func auditSnapshot(record interface{}) (string, error) {
+	if record == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit snapshot: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ContentRepositoryPostgres implements domain.ContentRepository for PostgreSQL.
+type ContentRepositoryPostgres struct {
+	db *sqlx.DB
+}
+
+var _ domain.ContentRepository = (*ContentRepositoryPostgres)(nil)
+
+// NewContentRepositoryPostgres creates a new ContentRepositoryPostgres.
+func NewContentRepositoryPostgres(db *sqlx.DB) *ContentRepositoryPostgres {
+	return &ContentRepositoryPostgres{db: db}
+}
+
+// itemDefinitionColumns selects an item_list row joined as i with its object o.
+const itemDefinitionColumns = `
+	i.id,
+	COALESCE(i.object_id, 0) AS object_id,
+	i.rarity,
+	i.is_stackable,
+	COALESCE(o.object_list_id, 0) AS object_list_id`
+
+// contentTables lists the definition tables in the order they are created.
+var contentTables = []string{"object_list", "item_list", "entity_list"}
+
+// GetContent returns all object, item and entity definitions as one consistent snapshot.
+func (r *ContentRepositoryPostgres) GetContent() (*domain.ContentBundle, error) {
+	tx, err := r.db.BeginTxx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	bundle := &domain.ContentBundle{}
+	if err := tx.Select(&bundle.ObjectLists, `SELECT `+objectListColumns+` FROM object_list ORDER BY id`); err != nil {
+		return nil, fmt.Errorf("failed to get object definitions: %w", err)
+	}
+	query := `SELECT ` + itemDefinitionColumns + ` FROM item_list i LEFT JOIN object o ON o.id = i.object_id ORDER BY i.id`
+	if err := tx.Select(&bundle.ItemLists, query); err != nil {
+		return nil, fmt.Errorf("failed to get item definitions: %w", err)
+	}
+	if err := tx.Select(&bundle.EntityLists, `SELECT `+entityListColumns+` FROM entity_list ORDER BY id`); err != nil {
+		return nil, fmt.Errorf("failed to get entity templates: %w", err)
+	}
+	return bundle, nil
+}
+
+// ApplyContentChanges applies changes in order in one transaction and records each in the audit log.
+func (r *ContentRepositoryPostgres) ApplyContentChanges(changes []domain.ContentChange, audit *domain.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	created := make(map[string]bool)
+	for _, change := range changes {
+		entry := *audit
+		var err error
+		switch change.Table {
+		case "object_list":
+			err = applyObjectListChange(tx, change, &entry)
+		case "item_list":
+			err = applyItemListChange(tx, change, &entry)
+		case "entity_list":
+			err = applyEntityListChange(tx, change, &entry)
+		default:
+			err = fmt.Errorf("unknown content table %q", change.Table)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to %s %s %d: %w", change.Action, change.Table, change.ID, err)
+		}
+		if change.Action == domain.AuditActionCreate {
+			created[change.Table] = true
+		}
+	}
+
+	// Rows were inserted with explicit IDs, so move the sequences past them
+	for _, table := range contentTables {
+		if !created[table] {
+			continue
+		}
+		query := `SELECT setval(pg_get_serial_sequence('` + table + `', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM ` + table
+		if _, err := tx.Exec(query); err != nil {
+			return fmt.Errorf("failed to advance %s ID sequence: %w", table, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit content changes: %w", err)
+	}
+	return nil
+}
+
+// applyObjectListChange applies a change of an object definition within tx.
+func applyObjectListChange(tx *sqlx.Tx, change domain.ContentChange, audit *domain.AuditEntry) error {
+	if change.Action == domain.AuditActionCreate {
+		after := change.After.(*domain.ObjectList)
+		query := `INSERT INTO object_list (id, name, image, description) VALUES ($1, $2, $3, $4)`
+		if _, err := tx.Exec(query, after.ID, after.Name, after.Image, after.Description); err != nil {
+			return err
+		}
+		return recordChange(tx, audit, change.Action, change.Table, change.ID, nil, after)
+	}
+
+	before, err := lockObjectList(tx, change.ID)
+	if err != nil {
+		return err
+	}
+	if *before != *change.Before.(*domain.ObjectList) {
+		return util.ErrContentChanged
+	}
+	if change.Action == domain.AuditActionDelete {
+		if err := deleteObjectList(tx, change.ID); err != nil {
+			return err
+		}
+		return recordChange(tx, audit, change.Action, change.Table, change.ID, before, nil)
+	}
+	after := change.After.(*domain.ObjectList)
+	if err := updateObjectList(tx, after); err != nil {
+		return err
+	}
+	return recordChange(tx, audit, change.Action, change.Table, change.ID, before, after)
+}
+
+// applyItemListChange applies a change of an item definition within tx. The definition
+// keeps its object while that is of the requested ObjectList; otherwise it moves to a new one.
+func applyItemListChange(tx *sqlx.Tx, change domain.ContentChange, audit *domain.AuditEntry) error {
+	if change.Action == domain.AuditActionCreate {
+		after := *change.After.(*domain.ItemDefinition)
+		objectID, err := bindObject(tx, 0, after.ObjectListID)
+		if err != nil {
+			return err
+		}
+		after.ObjectID = objectID
+		query := `INSERT INTO item_list (id, object_id, rarity, is_stackable) VALUES ($1, NULLIF($2, 0), $3, $4)`
+		if _, err := tx.Exec(query, after.ID, after.ObjectID, after.Rarity, after.IsStackable); err != nil {
+			return err
+		}
+		return recordChange(tx, audit, change.Action, change.Table, change.ID, nil, &after)
+	}
+
+	before, err := lockItemDefinition(tx, change.ID)
+	if err != nil {
+		return err
+	}
+	if *before != *change.Before.(*domain.ItemDefinition) {
+		return util.ErrContentChanged
+	}
+	if change.Action == domain.AuditActionDelete {
+		if err := deleteItemList(tx, change.ID); err != nil {
+			return err
+		}
+		return recordChange(tx, audit, change.Action, change.Table, change.ID, before, nil)
+	}
+	after := *change.After.(*domain.ItemDefinition)
+	if err := updateItemDefinition(tx, before.ObjectID, &after); err != nil {
+		return err
+	}
+	return recordChange(tx, audit, change.Action, change.Table, change.ID, before, &after)
+}
+
+// applyEntityListChange applies a change of an entity template within tx.
+func applyEntityListChange(tx *sqlx.Tx, change domain.ContentChange, audit *domain.AuditEntry) error {
+	if change.Action == domain.AuditActionCreate {
+		after := change.After.(*domain.EntityList)
+		query := `
+			INSERT INTO entity_list (id, object_list_id, damage, speed, cooldown, damage_radius, is_angry, visual_radius,
+				max_health, model, spawn, is_open, is_spawning, is_pick_up)
+			VALUES (:id, NULLIF(:object_list_id, 0), :damage, :speed, :cooldown, :damage_radius, :is_angry, :visual_radius,
+				:max_health, :model, :spawn, :is_open, :is_spawning, :is_pick_up)`
+		if _, err := tx.NamedExec(query, after); err != nil {
+			return err
+		}
+		return recordChange(tx, audit, change.Action, change.Table, change.ID, nil, after)
+	}
+
+	before, err := lockEntityList(tx, change.ID)
+	if err != nil {
+		return err
+	}
+	if *before != *change.Before.(*domain.EntityList) {
+		return util.ErrContentChanged
+	}
+	if change.Action == domain.AuditActionDelete {
+		if _, err := tx.Exec(`DELETE FROM entity_list WHERE id = $1`, change.ID); err != nil {
+			return err
+		}
+		return recordChange(tx, audit, change.Action, change.Table, change.ID, before, nil)
+	}
+	after := change.After.(*domain.EntityList)
+	if err := updateEntityList(tx, after); err != nil {
+		return err
+	}
+	return recordChange(tx, audit, change.Action, change.Table, change.ID, before, after)
+}
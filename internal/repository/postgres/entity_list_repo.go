@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// EntityListRepositoryPostgres implements domain.EntityListRepository for PostgreSQL.
+type EntityListRepositoryPostgres struct {
+	db *sqlx.DB
+}
+
+var _ domain.EntityListRepository = (*EntityListRepositoryPostgres)(nil)
+
+// NewEntityListRepositoryPostgres creates a new EntityListRepositoryPostgres.
+func NewEntityListRepositoryPostgres(db *sqlx.DB) *EntityListRepositoryPostgres {
+	return &EntityListRepositoryPostgres{db: db}
+}
+
+// entityListColumns selects an entity_list row, mapping a missing object definition to 0.
+const entityListColumns = `
+	id,
+	COALESCE(object_list_id, 0) AS object_list_id,
+	damage,
+	speed,
+	cooldown,
+	damage_radius,
+	is_angry,
+	visual_radius,
+	max_health,
+	model,
+	spawn,
+	is_open,
+	is_spawning,
+	is_pick_up`
+
+// GetEntityListByID retrieves an entity template by its ID.
+func (r *EntityListRepositoryPostgres) GetEntityListByID(id int) (*domain.EntityList, error) {
+	var entityList domain.EntityList
+	query := `SELECT ` + entityListColumns + ` FROM entity_list WHERE id = $1`
+	err := r.db.Get(&entityList, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, util.ErrEntityListNotFound
+		}
+		return nil, fmt.Errorf("failed to get entity template by ID: %w", err)
+	}
+	return &entityList, nil
+}
+
+// GetAllEntityLists retrieves all entity templates ordered by ID.
+func (r *EntityListRepositoryPostgres) GetAllEntityLists() ([]domain.EntityList, error) {
+	var entityLists []domain.EntityList
+	query := `SELECT ` + entityListColumns + ` FROM entity_list ORDER BY id`
+	if err := r.db.Select(&entityLists, query); err != nil {
+		return nil, fmt.Errorf("failed to get all entity templates: %w", err)
+	}
+	return entityLists, nil
+}
+
+// CreateEntityList inserts a new entity template and records it in the audit log.
+func (r *EntityListRepositoryPostgres) CreateEntityList(entityList *domain.EntityList, audit *domain.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO entity_list (object_list_id, damage, speed, cooldown, damage_radius, is_angry, visual_radius,
+			max_health, model, spawn, is_open, is_spawning, is_pick_up)
+		VALUES (NULLIF(:object_list_id, 0), :damage, :speed, :cooldown, :damage_radius, :is_angry, :visual_radius,
+			:max_health, :model, :spawn, :is_open, :is_spawning, :is_pick_up)
+		RETURNING id`
+	query, args, err := tx.BindNamed(query, entityList)
+	if err != nil {
+		return fmt.Errorf("failed to bind entity template: %w", err)
+	}
+	if err := tx.QueryRow(query, args...).Scan(&entityList.ID); err != nil {
+		return fmt.Errorf("failed to create entity template: %w", err)
+	}
+	if err := recordChange(tx, audit, domain.AuditActionCreate, "entity_list", entityList.ID, nil, entityList); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit entity template: %w", err)
+	}
+	return nil
+}
+
+// UpdateEntityList updates an entity template and records the change in the audit log.
+func (r *EntityListRepositoryPostgres) UpdateEntityList(entityList *domain.EntityList, audit *domain.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := lockEntityList(tx, entityList.ID)
+	if err != nil {
+		return err
+	}
+	if err := updateEntityList(tx, entityList); err != nil {
+		return err
+	}
+	if err := recordChange(tx, audit, domain.AuditActionUpdate, "entity_list", entityList.ID, before, entityList); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit entity template: %w", err)
+	}
+	return nil
+}
+
+// DeleteEntityList deletes an entity template, and by cascade its entities, and records it in the audit log.
+func (r *EntityListRepositoryPostgres) DeleteEntityList(id int, audit *domain.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := lockEntityList(tx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM entity_list WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete entity template: %w", err)
+	}
+	if err := recordChange(tx, audit, domain.AuditActionDelete, "entity_list", id, before, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit entity template: %w", err)
+	}
+	return nil
+}
+
+// lockEntityList reads an entity template within tx and locks it until tx ends.
+func lockEntityList(tx *sqlx.Tx, id int) (*domain.EntityList, error) {
+	var entityList domain.EntityList
+	query := `SELECT ` + entityListColumns + ` FROM entity_list WHERE id = $1 FOR UPDATE`
+	if err := tx.Get(&entityList, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, util.ErrEntityListNotFound
+		}
+		return nil, fmt.Errorf("failed to get entity template: %w", err)
+	}
+	return &entityList, nil
+}
+
+// updateEntityList writes the attributes of an entity template within tx.
+func updateEntityList(tx *sqlx.Tx, entityList *domain.EntityList) error {
+	query := `
+		UPDATE entity_list SET object_list_id = NULLIF(:object_list_id, 0), damage = :damage, speed = :speed,
+			cooldown = :cooldown, damage_radius = :damage_radius, is_angry = :is_angry, visual_radius = :visual_radius,
+			max_health = :max_health, model = :model, spawn = :spawn, is_open = :is_open,
+			is_spawning = :is_spawning, is_pick_up = :is_pick_up
+		WHERE id = :id`
+	if _, err := tx.NamedExec(query, entityList); err != nil {
+		return fmt.Errorf("failed to update entity template: %w", err)
+	}
+	return nil
+}
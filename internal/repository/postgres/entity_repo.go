@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// EntityRepositoryPostgres implements domain.EntityRepository for PostgreSQL.
+type EntityRepositoryPostgres struct {
+	db *sqlx.DB
+}
+
+var _ domain.EntityRepository = (*EntityRepositoryPostgres)(nil)
+
+// NewEntityRepositoryPostgres creates a new EntityRepositoryPostgres.
+func NewEntityRepositoryPostgres(db *sqlx.DB) *EntityRepositoryPostgres {
+	return &EntityRepositoryPostgres{db: db}
+}
+
+// entityColumns selects an entity row, mapping missing references to 0.
+const entityColumns = `id, COALESCE(object_id, 0) AS object_id, COALESCE(entity_list_id, 0) AS entity_list_id, health, x, y, z`
+
+// CreateEntity inserts a new entity into the database.
+func (r *EntityRepositoryPostgres) CreateEntity(entity *domain.Entity) error {
+	query := `
+		INSERT INTO entity (object_id, entity_list_id, health, x, y, z)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`
+	err := r.db.QueryRow(query, entity.ObjectID, entity.EntityListID, entity.Health, entity.X, entity.Y, entity.Z).Scan(&entity.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create entity: %w", err)
+	}
+	return nil
+}
+
+// GetEntityByID retrieves an entity by its ID.
+func (r *EntityRepositoryPostgres) GetEntityByID(id int) (*domain.Entity, error) {
+	var entity domain.Entity
+	query := `SELECT ` + entityColumns + ` FROM entity WHERE id = $1`
+	err := r.db.Get(&entity, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, util.ErrEntityNotFound
+		}
+		return nil, fmt.Errorf("failed to get entity by ID: %w", err)
+	}
+	return &entity, nil
+}
+
+// GetAllEntities retrieves all entities ordered by ID.
+func (r *EntityRepositoryPostgres) GetAllEntities() ([]domain.Entity, error) {
+	var entities []domain.Entity
+	query := `SELECT ` + entityColumns + ` FROM entity ORDER BY id`
+	err := r.db.Select(&entities, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all entities: %w", err)
+	}
+	return entities, nil
+}
+
+// UpdateEntity updates an existing entity.
+func (r *EntityRepositoryPostgres) UpdateEntity(entity *domain.Entity) error {
+	query := `
+		UPDATE entity
+		SET object_id = $2, entity_list_id = $3, health = $4, x = $5, y = $6, z = $7
+		WHERE id = $1`
+	res, err := r.db.Exec(query, entity.ID, entity.ObjectID, entity.EntityListID, entity.Health, entity.X, entity.Y, entity.Z)
+	if err != nil {
+		return fmt.Errorf("failed to update entity: %w", err)
+	}
+	return requireAffected(res, util.ErrEntityNotFound)
+}
+
+// DeleteEntity deletes an entity by its ID.
+func (r *EntityRepositoryPostgres) DeleteEntity(id int) error {
+	res, err := r.db.Exec(`DELETE FROM entity WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete entity: %w", err)
+	}
+	return requireAffected(res, util.ErrEntityNotFound)
+}
+
+// SpawnEntity inserts an object of objectListID and an entity bound to it in one transaction.
+func (r *EntityRepositoryPostgres) SpawnEntity(entity *domain.Entity, objectListID int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertSpawnedEntity(tx, entity, objectListID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit spawned entity: %w", err)
+	}
+	return nil
+}
+
+// insertSpawnedEntity inserts an object of objectListID and an entity bound to it within tx.
+func insertSpawnedEntity(tx *sqlx.Tx, entity *domain.Entity, objectListID int) error {
+	if err := tx.QueryRow(`INSERT INTO object (object_list_id) VALUES (NULLIF($1, 0)) RETURNING id`, objectListID).Scan(&entity.ObjectID); err != nil {
+		return fmt.Errorf("failed to create entity object: %w", err)
+	}
+	query := `
+		INSERT INTO entity (object_id, entity_list_id, health, x, y, z)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`
+	if err := tx.QueryRow(query, entity.ObjectID, entity.EntityListID, entity.Health, entity.X, entity.Y, entity.Z).Scan(&entity.ID); err != nil {
+		return fmt.Errorf("failed to create entity: %w", err)
+	}
+	return nil
+}
+
+// DespawnEntity deletes an entity together with its object in one transaction.
+func (r *EntityRepositoryPostgres) DespawnEntity(id int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var objectID sql.NullInt64
+	err = tx.QueryRow(`DELETE FROM entity WHERE id = $1 RETURNING object_id`, id).Scan(&objectID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return util.ErrEntityNotFound
+		}
+		return fmt.Errorf("failed to delete entity: %w", err)
+	}
+	if objectID.Valid {
+		if _, err := tx.Exec(`DELETE FROM object WHERE id = $1`, objectID.Int64); err != nil {
+			return fmt.Errorf("failed to delete entity object: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit despawned entity: %w", err)
+	}
+	return nil
+}
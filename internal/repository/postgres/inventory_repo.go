@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// InventoryRepositoryPostgres implements domain.InventoryRepository for PostgreSQL.
+type InventoryRepositoryPostgres struct {
+	db *sqlx.DB
+}
+
+var _ domain.InventoryRepository = (*InventoryRepositoryPostgres)(nil)
+
+// NewInventoryRepositoryPostgres creates a new InventoryRepositoryPostgres.
+func NewInventoryRepositoryPostgres(db *sqlx.DB) *InventoryRepositoryPostgres {
+	return &InventoryRepositoryPostgres{db: db}
+}
+
+// AddInventoryEntry inserts a new inventory entry, generating its ID if empty.
+func (r *InventoryRepositoryPostgres) AddInventoryEntry(entry *domain.Inventory) error {
+	if entry.ID == "" {
+		id, err := util.NewUUID()
+		if err != nil {
+			return err
+		}
+		entry.ID = id
+	}
+	query := `INSERT INTO inventory (id, entity_id, item_id, slot, quantity) VALUES ($1, $2, $3, $4, $5)`
+	if _, err := r.db.Exec(query, entry.ID, entry.EntityID, entry.ItemID, entry.Slot, entry.Quantity); err != nil {
+		return fmt.Errorf("failed to add inventory entry: %w", err)
+	}
+	return nil
+}
+
+// GetInventoryByEntityID retrieves all inventory entries of an entity ordered by slot.
+func (r *InventoryRepositoryPostgres) GetInventoryByEntityID(entityID string) ([]domain.Inventory, error) {
+	var entries []domain.Inventory
+	query := `SELECT id, entity_id, item_id, slot, quantity FROM inventory WHERE entity_id = $1 ORDER BY slot`
+	if err := r.db.Select(&entries, query, entityID); err != nil {
+		return nil, fmt.Errorf("failed to get inventory: %w", err)
+	}
+	return entries, nil
+}
+
+// RemoveInventoryEntry deletes an inventory entry by its ID.
+func (r *InventoryRepositoryPostgres) RemoveInventoryEntry(id string) error {
+	res, err := r.db.Exec(`DELETE FROM inventory WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to remove inventory entry: %w", err)
+	}
+	return requireAffected(res, util.ErrInventoryEntryNotFound)
+}
+
+// SaveInventoryChanges applies removals, creates new items, inserts and updates
+// entries and deletes freed items in one transaction.
+// The slot uniqueness constraint is deferred, so entries may swap slots.
+func (r *InventoryRepositoryPostgres) SaveInventoryChanges(changes domain.InventoryChanges) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := saveInventoryChanges(tx, changes); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit inventory changes: %w", err)
+	}
+	return nil
+}
+
+// saveInventoryChanges applies inventory changes within tx.
+func saveInventoryChanges(tx *sqlx.Tx, changes domain.InventoryChanges) error {
+	for _, id := range changes.Removed {
+		res, err := tx.Exec(`DELETE FROM inventory WHERE id = $1`, id)
+		if err != nil {
+			return fmt.Errorf("failed to remove inventory entry: %w", err)
+		}
+		if err := requireAffected(res, util.ErrInventoryEntryNotFound); err != nil {
+			return err
+		}
+	}
+
+	for i := range changes.NewItems {
+		item := &changes.NewItems[i]
+		query := `INSERT INTO item (object_id, item_list_id) VALUES (NULLIF($1, 0), $2) RETURNING id`
+		if err := tx.QueryRow(query, item.ObjectID, item.ItemListID).Scan(&item.ID); err != nil {
+			return fmt.Errorf("failed to create inventory item: %w", err)
+		}
+	}
+	saved, err := changes.SavedWithNewItems()
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO inventory (id, entity_id, item_id, slot, quantity)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE
+		SET entity_id = EXCLUDED.entity_id, item_id = EXCLUDED.item_id, slot = EXCLUDED.slot, quantity = EXCLUDED.quantity`
+	for i := range saved {
+		entry := &saved[i]
+		if entry.ID == "" {
+			id, err := util.NewUUID()
+			if err != nil {
+				return err
+			}
+			entry.ID = id
+		}
+		if _, err := tx.Exec(query, entry.ID, entry.EntityID, entry.ItemID, entry.Slot, entry.Quantity); err != nil {
+			return fmt.Errorf("failed to save inventory entry: %w", err)
+		}
+	}
+
+	// Items are deleted last, as deleting an item cascades to the entries still holding it
+	for _, id := range changes.FreedItems {
+		query := `
+			DELETE FROM item
+			WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM inventory WHERE item_id = $1)
+			RETURNING object_id`
+		var objectID sql.NullInt64
+		if err := tx.QueryRow(query, id).Scan(&objectID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return fmt.Errorf("failed to delete freed item: %w", err)
+		}
+		if objectID.Valid {
+			if _, err := tx.Exec(`DELETE FROM object WHERE id = $1`, objectID.Int64); err != nil {
+				return fmt.Errorf("failed to delete freed item object: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// PickUpEntity deletes a world entity and applies changes in one transaction. The
+// entity's object passes to the first of changes.NewItems, or is deleted with the
+// entity if the pickup only topped up existing stacks. Deleting the entity first makes
+// concurrent pickups of the same entity fail with util.ErrEntityNotFound in all but one transaction.
+func (r *InventoryRepositoryPostgres) PickUpEntity(entityID int, changes domain.InventoryChanges) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var objectID sql.NullInt64
+	err = tx.QueryRow(`DELETE FROM entity WHERE id = $1 RETURNING object_id`, entityID).Scan(&objectID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return util.ErrEntityNotFound
+		}
+		return fmt.Errorf("failed to delete picked up entity: %w", err)
+	}
+	switch {
+	case len(changes.NewItems) > 0:
+		changes.NewItems[0].ObjectID = int(objectID.Int64)
+	case objectID.Valid:
+		if _, err := tx.Exec(`DELETE FROM object WHERE id = $1`, objectID.Int64); err != nil {
+			return fmt.Errorf("failed to delete picked up entity object: %w", err)
+		}
+	}
+
+	if err := saveInventoryChanges(tx, changes); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit pickup: %w", err)
+	}
+	return nil
+}
+
+// DropItem applies changes and spawns entity with a new object of objectListID in one transaction.
+func (r *InventoryRepositoryPostgres) DropItem(changes domain.InventoryChanges, entity *domain.Entity, objectListID int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := saveInventoryChanges(tx, changes); err != nil {
+		return err
+	}
+	if err := insertSpawnedEntity(tx, entity, objectListID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit drop: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ItemListRepositoryPostgres implements domain.ItemListRepository for PostgreSQL.
+type ItemListRepositoryPostgres struct {
+	db *sqlx.DB
+}
+
+var _ domain.ItemListRepository = (*ItemListRepositoryPostgres)(nil)
+
+// NewItemListRepositoryPostgres creates a new ItemListRepositoryPostgres.
+func NewItemListRepositoryPostgres(db *sqlx.DB) *ItemListRepositoryPostgres {
+	return &ItemListRepositoryPostgres{db: db}
+}
+
+// itemListColumns selects an item_list row, mapping a missing object to 0.
+const itemListColumns = `
+	id,
+	COALESCE(object_id, 0) AS object_id,
+	rarity,
+	is_stackable`
+
+// GetItemListByID retrieves an item definition by its ID.
+func (r *ItemListRepositoryPostgres) GetItemListByID(id int) (*domain.ItemList, error) {
+	var itemList domain.ItemList
+	query := `SELECT ` + itemListColumns + ` FROM item_list WHERE id = $1`
+	err := r.db.Get(&itemList, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, util.ErrItemListNotFound
+		}
+		return nil, fmt.Errorf("failed to get item definition by ID: %w", err)
+	}
+	return &itemList, nil
+}
+
+// GetAllItemLists retrieves all item definitions ordered by ID.
+func (r *ItemListRepositoryPostgres) GetAllItemLists() ([]domain.ItemList, error) {
+	var itemLists []domain.ItemList
+	query := `SELECT ` + itemListColumns + ` FROM item_list ORDER BY id`
+	if err := r.db.Select(&itemLists, query); err != nil {
+		return nil, fmt.Errorf("failed to get all item definitions: %w", err)
+	}
+	return itemLists, nil
+}
+
+// GetItemListByObjectListID finds the item definition whose object is of objectListID.
+func (r *ItemListRepositoryPostgres) GetItemListByObjectListID(objectListID int) (*domain.ItemList, error) {
+	var itemList domain.ItemList
+	query := `
+		SELECT ` + itemListColumns + ` FROM item_list
+		WHERE object_id IN (SELECT id FROM object WHERE object_list_id = $1)
+		ORDER BY id
+		LIMIT 1`
+	err := r.db.Get(&itemList, query, objectListID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, util.ErrItemListNotFound
+		}
+		return nil, fmt.Errorf("failed to get item definition by object list: %w", err)
+	}
+	return &itemList, nil
+}
+
+// CreateItemList inserts a new item definition together with the object that ties
+// it to its ObjectList and records it in the audit log.
+func (r *ItemListRepositoryPostgres) CreateItemList(definition *domain.ItemDefinition, audit *domain.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if definition.ObjectID, err = bindObject(tx, 0, definition.ObjectListID); err != nil {
+		return err
+	}
+	query := `INSERT INTO item_list (object_id, rarity, is_stackable) VALUES (NULLIF($1, 0), $2, $3) RETURNING id`
+	if err := tx.QueryRow(query, definition.ObjectID, definition.Rarity, definition.IsStackable).Scan(&definition.ID); err != nil {
+		return fmt.Errorf("failed to create item definition: %w", err)
+	}
+	if err := recordChange(tx, audit, domain.AuditActionCreate, "item_list", definition.ID, nil, definition); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit item definition: %w", err)
+	}
+	return nil
+}
+
+// UpdateItemList updates an item definition, moving it to an object of its new
+// ObjectList if that changed, and records the change in the audit log.
+func (r *ItemListRepositoryPostgres) UpdateItemList(definition *domain.ItemDefinition, audit *domain.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := lockItemDefinition(tx, definition.ID)
+	if err != nil {
+		return err
+	}
+	if err := updateItemDefinition(tx, before.ObjectID, definition); err != nil {
+		return err
+	}
+	if err := recordChange(tx, audit, domain.AuditActionUpdate, "item_list", definition.ID, before, definition); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit item definition: %w", err)
+	}
+	return nil
+}
+
+// DeleteItemList deletes an item definition without items and records it in the audit log.
+func (r *ItemListRepositoryPostgres) DeleteItemList(id int, audit *domain.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := lockItemDefinition(tx, id)
+	if err != nil {
+		return err
+	}
+	if err := deleteItemList(tx, id); err != nil {
+		return err
+	}
+	if err := recordChange(tx, audit, domain.AuditActionDelete, "item_list", id, before, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit item definition: %w", err)
+	}
+	return nil
+}
+
+// lockItemDefinition reads an item definition with the ObjectList of its object
+// within tx and locks it until tx ends.
+func lockItemDefinition(tx *sqlx.Tx, id int) (*domain.ItemDefinition, error) {
+	var definition domain.ItemDefinition
+	query := `SELECT ` + itemDefinitionColumns + ` FROM item_list i LEFT JOIN object o ON o.id = i.object_id
+		WHERE i.id = $1 FOR UPDATE OF i`
+	if err := tx.Get(&definition, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, util.ErrItemListNotFound
+		}
+		return nil, fmt.Errorf("failed to get item definition: %w", err)
+	}
+	return &definition, nil
+}
+
+// updateItemDefinition writes the attributes of an item definition within tx. The
+// definition keeps its object objectID while that is of the requested ObjectList;
+// otherwise it moves to a new object and the old one is deleted once unused.
+func updateItemDefinition(tx *sqlx.Tx, objectID int, definition *domain.ItemDefinition) error {
+	var err error
+	if definition.ObjectID, err = bindObject(tx, objectID, definition.ObjectListID); err != nil {
+		return err
+	}
+	query := `UPDATE item_list SET object_id = NULLIF($2, 0), rarity = $3, is_stackable = $4 WHERE id = $1`
+	if _, err := tx.Exec(query, definition.ID, definition.ObjectID, definition.Rarity, definition.IsStackable); err != nil {
+		return fmt.Errorf("failed to update item definition: %w", err)
+	}
+	if objectID != definition.ObjectID {
+		return deleteUnusedObject(tx, objectID)
+	}
+	return nil
+}
+
+// deleteItemList deletes an item definition and its object within tx, failing with
+// util.ErrDefinitionInUse while items of it exist.
+func deleteItemList(tx *sqlx.Tx, id int) error {
+	var inUse bool
+	if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM item WHERE item_list_id = $1)`, id).Scan(&inUse); err != nil {
+		return fmt.Errorf("failed to check item definition references: %w", err)
+	}
+	if inUse {
+		return util.ErrDefinitionInUse
+	}
+	var objectID sql.NullInt64
+	if err := tx.QueryRow(`DELETE FROM item_list WHERE id = $1 RETURNING object_id`, id).Scan(&objectID); err != nil {
+		return fmt.Errorf("failed to delete item definition: %w", err)
+	}
+	return deleteUnusedObject(tx, int(objectID.Int64))
+}
+
+// bindObject returns an object of objectListID for an item definition: objectID if
+// it already is one, otherwise a new object. 0 as objectListID gives no object.
+func bindObject(tx *sqlx.Tx, objectID, objectListID int) (int, error) {
+	if objectListID == 0 {
+		return 0, nil
+	}
+	if objectID != 0 {
+		var current int
+		err := tx.QueryRow(`SELECT COALESCE(object_list_id, 0) FROM object WHERE id = $1`, objectID).Scan(&current)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("failed to get object: %w", err)
+		}
+		if err == nil && current == objectListID {
+			return objectID, nil
+		}
+	}
+	query := `INSERT INTO object (object_list_id) VALUES ($1) RETURNING id`
+	if err := tx.QueryRow(query, objectListID).Scan(&objectID); err != nil {
+		return 0, fmt.Errorf("failed to create object: %w", err)
+	}
+	return objectID, nil
+}
+
+// deleteUnusedObject deletes an object within tx unless an item definition, item or
+// entity is made of it. 0 is no object.
+func deleteUnusedObject(tx *sqlx.Tx, id int) error {
+	if id == 0 {
+		return nil
+	}
+	query := `
+		DELETE FROM object o
+		WHERE o.id = $1
+			AND NOT EXISTS (SELECT 1 FROM item_list WHERE object_id = o.id)
+			AND NOT EXISTS (SELECT 1 FROM item WHERE object_id = o.id)
+			AND NOT EXISTS (SELECT 1 FROM entity WHERE object_id = o.id)`
+	if _, err := tx.Exec(query, id); err != nil {
+		return fmt.Errorf("failed to delete unused object: %w", err)
+	}
+	return nil
+}
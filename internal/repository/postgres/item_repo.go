@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ItemRepositoryPostgres implements domain.ItemRepository for PostgreSQL.
+type ItemRepositoryPostgres struct {
+	db *sqlx.DB
+}
+
+var _ domain.ItemRepository = (*ItemRepositoryPostgres)(nil)
+
+// NewItemRepositoryPostgres creates a new ItemRepositoryPostgres.
+func NewItemRepositoryPostgres(db *sqlx.DB) *ItemRepositoryPostgres {
+	return &ItemRepositoryPostgres{db: db}
+}
+
+// CreateItem inserts a new item into the database.
+func (r *ItemRepositoryPostgres) CreateItem(item *domain.Item) error {
+	query := `INSERT INTO item (object_id, item_list_id) VALUES (NULLIF($1, 0), $2) RETURNING id`
+	err := r.db.QueryRow(query, item.ObjectID, item.ItemListID).Scan(&item.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create item: %w", err)
+	}
+	return nil
+}
+
+// GetItemByID retrieves an item by its ID.
+func (r *ItemRepositoryPostgres) GetItemByID(id int) (*domain.Item, error) {
+	var item domain.Item
+	query := `SELECT id, COALESCE(object_id, 0) AS object_id, COALESCE(item_list_id, 0) AS item_list_id FROM item WHERE id = $1`
+	err := r.db.Get(&item, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, util.ErrItemNotFound
+		}
+		return nil, fmt.Errorf("failed to get item by ID: %w", err)
+	}
+	return &item, nil
+}
+
+// DeleteItem deletes an item by its ID. Inventory entries referencing it are removed by cascade.
+func (r *ItemRepositoryPostgres) DeleteItem(id int) error {
+	res, err := r.db.Exec(`DELETE FROM item WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete item: %w", err)
+	}
+	return requireAffected(res, util.ErrItemNotFound)
+}
@@ -0,0 +1,171 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ObjectListRepositoryPostgres implements domain.ObjectListRepository for PostgreSQL.
+type ObjectListRepositoryPostgres struct {
+	db *sqlx.DB
+}
+
+var _ domain.ObjectListRepository = (*ObjectListRepositoryPostgres)(nil)
+
+// NewObjectListRepositoryPostgres creates a new ObjectListRepositoryPostgres.
+func NewObjectListRepositoryPostgres(db *sqlx.DB) *ObjectListRepositoryPostgres {
+	return &ObjectListRepositoryPostgres{db: db}
+}
+
+// objectListColumns selects an object_list row.
+const objectListColumns = `id, name, image, description`
+
+// GetObjectListByID retrieves an object definition by its ID.
+func (r *ObjectListRepositoryPostgres) GetObjectListByID(id int) (*domain.ObjectList, error) {
+	var objectList domain.ObjectList
+	query := `SELECT ` + objectListColumns + ` FROM object_list WHERE id = $1`
+	err := r.db.Get(&objectList, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, util.ErrObjectListNotFound
+		}
+		return nil, fmt.Errorf("failed to get object definition by ID: %w", err)
+	}
+	return &objectList, nil
+}
+
+// GetAllObjectLists retrieves all object definitions ordered by ID.
+func (r *ObjectListRepositoryPostgres) GetAllObjectLists() ([]domain.ObjectList, error) {
+	var objectLists []domain.ObjectList
+	query := `SELECT ` + objectListColumns + ` FROM object_list ORDER BY id`
+	if err := r.db.Select(&objectLists, query); err != nil {
+		return nil, fmt.Errorf("failed to get all object definitions: %w", err)
+	}
+	return objectLists, nil
+}
+
+// CreateObjectList inserts a new object definition and records it in the audit log.
+func (r *ObjectListRepositoryPostgres) CreateObjectList(objectList *domain.ObjectList, audit *domain.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO object_list (name, image, description) VALUES ($1, $2, $3) RETURNING id`
+	if err := tx.QueryRow(query, objectList.Name, objectList.Image, objectList.Description).Scan(&objectList.ID); err != nil {
+		return fmt.Errorf("failed to create object definition: %w", err)
+	}
+	if err := recordChange(tx, audit, domain.AuditActionCreate, "object_list", objectList.ID, nil, objectList); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit object definition: %w", err)
+	}
+	return nil
+}
+
+// UpdateObjectList updates an object definition and records the change in the audit log.
+func (r *ObjectListRepositoryPostgres) UpdateObjectList(objectList *domain.ObjectList, audit *domain.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := lockObjectList(tx, objectList.ID)
+	if err != nil {
+		return err
+	}
+	if err := updateObjectList(tx, objectList); err != nil {
+		return err
+	}
+	if err := recordChange(tx, audit, domain.AuditActionUpdate, "object_list", objectList.ID, before, objectList); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit object definition: %w", err)
+	}
+	return nil
+}
+
+// DeleteObjectList deletes an object definition that nothing refers to and records it in the audit log.
+func (r *ObjectListRepositoryPostgres) DeleteObjectList(id int, audit *domain.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := lockObjectList(tx, id)
+	if err != nil {
+		return err
+	}
+	if err := deleteObjectList(tx, id); err != nil {
+		return err
+	}
+	if err := recordChange(tx, audit, domain.AuditActionDelete, "object_list", id, before, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit object definition: %w", err)
+	}
+	return nil
+}
+
+// lockObjectList reads an object definition within tx and locks it until tx ends.
+func lockObjectList(tx *sqlx.Tx, id int) (*domain.ObjectList, error) {
+	var objectList domain.ObjectList
+	query := `SELECT ` + objectListColumns + ` FROM object_list WHERE id = $1 FOR UPDATE`
+	if err := tx.Get(&objectList, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, util.ErrObjectListNotFound
+		}
+		return nil, fmt.Errorf("failed to get object definition: %w", err)
+	}
+	return &objectList, nil
+}
+
+// updateObjectList writes the attributes of an object definition within tx.
+func updateObjectList(tx *sqlx.Tx, objectList *domain.ObjectList) error {
+	query := `UPDATE object_list SET name = $2, image = $3, description = $4 WHERE id = $1`
+	if _, err := tx.Exec(query, objectList.ID, objectList.Name, objectList.Image, objectList.Description); err != nil {
+		return fmt.Errorf("failed to update object definition: %w", err)
+	}
+	return nil
+}
+
+// deleteObjectList deletes an object definition within tx. Objects of it that no item
+// definition, item or entity is made of are deleted with it; any other reference
+// fails with util.ErrDefinitionInUse.
+func deleteObjectList(tx *sqlx.Tx, id int) error {
+	query := `
+		DELETE FROM object o
+		WHERE o.object_list_id = $1
+			AND NOT EXISTS (SELECT 1 FROM item_list WHERE object_id = o.id)
+			AND NOT EXISTS (SELECT 1 FROM item WHERE object_id = o.id)
+			AND NOT EXISTS (SELECT 1 FROM entity WHERE object_id = o.id)`
+	if _, err := tx.Exec(query, id); err != nil {
+		return fmt.Errorf("failed to delete unused objects: %w", err)
+	}
+	var inUse bool
+	query = `
+		SELECT EXISTS (SELECT 1 FROM entity_list WHERE object_list_id = $1)
+			OR EXISTS (SELECT 1 FROM object WHERE object_list_id = $1)`
+	if err := tx.QueryRow(query, id).Scan(&inUse); err != nil {
+		return fmt.Errorf("failed to check object definition references: %w", err)
+	}
+	if inUse {
+		return util.ErrDefinitionInUse
+	}
+	if _, err := tx.Exec(`DELETE FROM object_list WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete object definition: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ObjectRepositoryPostgres implements domain.ObjectRepository for PostgreSQL.
+type ObjectRepositoryPostgres struct {
+	db *sqlx.DB
+}
+
+var _ domain.ObjectRepository = (*ObjectRepositoryPostgres)(nil)
+
+// NewObjectRepositoryPostgres creates a new ObjectRepositoryPostgres.
+func NewObjectRepositoryPostgres(db *sqlx.DB) *ObjectRepositoryPostgres {
+	return &ObjectRepositoryPostgres{db: db}
+}
+
+// CreateObject inserts a new object into the database.
+func (r *ObjectRepositoryPostgres) CreateObject(object *domain.Object) error {
+	query := `INSERT INTO object (object_list_id) VALUES (NULLIF($1, 0)) RETURNING id`
+	if err := r.db.QueryRow(query, object.ObjectListID).Scan(&object.ID); err != nil {
+		return fmt.Errorf("failed to create object: %w", err)
+	}
+	return nil
+}
+
+// GetObjectByID retrieves an object by its ID.
+func (r *ObjectRepositoryPostgres) GetObjectByID(id int) (*domain.Object, error) {
+	var object domain.Object
+	query := `SELECT id, COALESCE(object_list_id, 0) AS object_list_id FROM object WHERE id = $1`
+	err := r.db.Get(&object, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, util.ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to get object by ID: %w", err)
+	}
+	return &object, nil
+}
+
+// DeleteObject deletes an object by its ID. Items and entities made of it are removed by cascade.
+func (r *ObjectRepositoryPostgres) DeleteObject(id int) error {
+	res, err := r.db.Exec(`DELETE FROM object WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return requireAffected(res, util.ErrObjectNotFound)
+}
@@ -16,6 +16,8 @@ type PlayerMovementRepositoryPostgres struct {
 	db *sqlx.DB
 }
 
+var _ domain.PlayerMovementRepository = (*PlayerMovementRepositoryPostgres)(nil)
+
 // NewPlayerMovementRepositoryPostgres creates a new PlayerMovementRepositoryPostgres.
 func NewPlayerMovementRepositoryPostgres(db *sqlx.DB) *PlayerMovementRepositoryPostgres {
 	return &PlayerMovementRepositoryPostgres{db: db}
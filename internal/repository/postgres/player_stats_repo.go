@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PlayerStatsRepositoryPostgres implements domain.PlayerStatsRepository for PostgreSQL.
+type PlayerStatsRepositoryPostgres struct {
+	db *sqlx.DB
+}
+
+var _ domain.PlayerStatsRepository = (*PlayerStatsRepositoryPostgres)(nil)
+
+// NewPlayerStatsRepositoryPostgres creates a new PlayerStatsRepositoryPostgres.
+func NewPlayerStatsRepositoryPostgres(db *sqlx.DB) *PlayerStatsRepositoryPostgres {
+	return &PlayerStatsRepositoryPostgres{db: db}
+}
+
+// SavePlayerStats inserts or updates a player's stats.
+func (r *PlayerStatsRepositoryPostgres) SavePlayerStats(stats *domain.PlayerStats) error {
+	query := `
+		INSERT INTO player_stats (player_id, health, max_health, deaths)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (player_id) DO UPDATE
+		SET health = EXCLUDED.health, max_health = EXCLUDED.max_health, deaths = EXCLUDED.deaths, updated_at = NOW()
+		RETURNING updated_at`
+	err := r.db.QueryRow(query, stats.PlayerID, stats.Health, stats.MaxHealth, stats.Deaths).Scan(&stats.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save player stats: %w", err)
+	}
+	return nil
+}
+
+// GetPlayerStats retrieves a player's stats by player ID.
+func (r *PlayerStatsRepositoryPostgres) GetPlayerStats(playerID string) (*domain.PlayerStats, error) {
+	var stats domain.PlayerStats
+	query := `SELECT player_id, health, max_health, deaths, updated_at FROM player_stats WHERE player_id = $1`
+	err := r.db.Get(&stats, query, playerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, util.ErrPlayerStatsNotFound
+		}
+		return nil, fmt.Errorf("failed to get player stats: %w", err)
+	}
+	return &stats, nil
+}
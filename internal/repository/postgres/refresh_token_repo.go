@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// RefreshTokenRepositoryPostgres implements domain.RefreshTokenRepository for PostgreSQL.
+type RefreshTokenRepositoryPostgres struct {
+	db *sqlx.DB
+}
+
+var _ domain.RefreshTokenRepository = (*RefreshTokenRepositoryPostgres)(nil)
+
+// NewRefreshTokenRepositoryPostgres creates a new RefreshTokenRepositoryPostgres.
+func NewRefreshTokenRepositoryPostgres(db *sqlx.DB) *RefreshTokenRepositoryPostgres {
+	return &RefreshTokenRepositoryPostgres{db: db}
+}
+
+// refreshTokenColumns selects a refresh_tokens row.
+const refreshTokenColumns = `id, user_id, family_id, token_hash, expires_at, created_at, rotated_at, revoked_at`
+
+// CreateRefreshToken stores a token that starts a new family, assigning its ID and family.
+func (r *RefreshTokenRepositoryPostgres) CreateRefreshToken(token *domain.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+		VALUES ($1, gen_random_uuid(), $2, $3)
+		RETURNING id, family_id, created_at`
+	err := r.db.QueryRow(query, token.UserID, token.TokenHash, token.ExpiresAt).Scan(&token.ID, &token.FamilyID, &token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	return nil
+}
+
+// RotateRefreshToken exchanges the token with tokenHash for next in one transaction.
+// The presented token is locked, so of two concurrent refreshes with the same token
+// only one succeeds and the other is treated as reuse.
+func (r *RefreshTokenRepositoryPostgres) RotateRefreshToken(tokenHash string, next *domain.RefreshToken, now time.Time) (*domain.RefreshToken, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	current, err := lockRefreshToken(tx, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case current.RevokedAt != nil:
+		return nil, util.ErrInvalidToken
+	case current.RotatedAt != nil:
+		// The token was exchanged before, so a copy of it is in someone else's hands
+		if err := revokeRefreshTokenFamily(tx, current.FamilyID, now); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit refresh token revocation: %w", err)
+		}
+		return nil, util.ErrRefreshTokenReused
+	case !now.Before(current.ExpiresAt):
+		return nil, util.ErrInvalidToken
+	}
+
+	if _, err := tx.Exec(`UPDATE refresh_tokens SET rotated_at = $2 WHERE id = $1`, current.ID, now); err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	current.RotatedAt = &now
+	next.UserID, next.FamilyID = current.UserID, current.FamilyID
+	query := `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+	if err := tx.QueryRow(query, next.UserID, next.FamilyID, next.TokenHash, next.ExpiresAt).Scan(&next.ID, &next.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit refresh token rotation: %w", err)
+	}
+	return current, nil
+}
+
+// RevokeRefreshTokenFamily revokes every token of the family of the token with tokenHash.
+func (r *RefreshTokenRepositoryPostgres) RevokeRefreshTokenFamily(tokenHash string, now time.Time) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	current, err := lockRefreshToken(tx, tokenHash)
+	if err != nil {
+		return err
+	}
+	if err := revokeRefreshTokenFamily(tx, current.FamilyID, now); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit refresh token revocation: %w", err)
+	}
+	return nil
+}
+
+// lockRefreshToken reads the refresh token with tokenHash within tx and locks it until tx ends.
+func lockRefreshToken(tx *sqlx.Tx, tokenHash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	query := `SELECT ` + refreshTokenColumns + ` FROM refresh_tokens WHERE token_hash = $1 FOR UPDATE`
+	if err := tx.Get(&token, query, tokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, util.ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	return &token, nil
+}
+
+// revokeRefreshTokenFamily revokes the tokens of a family that are not revoked yet.
+func revokeRefreshTokenFamily(tx *sqlx.Tx, familyID string, now time.Time) error {
+	query := `UPDATE refresh_tokens SET revoked_at = $2 WHERE family_id = $1 AND revoked_at IS NULL`
+	if _, err := tx.Exec(query, familyID, now); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return nil
+}
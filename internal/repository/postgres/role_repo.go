@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"fmt"
+
+	"anarchy-core/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// RoleRepositoryPostgres implements domain.RoleRepository for PostgreSQL.
+type RoleRepositoryPostgres struct {
+	db *sqlx.DB
+}
+
+var _ domain.RoleRepository = (*RoleRepositoryPostgres)(nil)
+
+// NewRoleRepositoryPostgres creates a new RoleRepositoryPostgres.
+func NewRoleRepositoryPostgres(db *sqlx.DB) *RoleRepositoryPostgres {
+	return &RoleRepositoryPostgres{db: db}
+}
+
+// GetUserRoles returns the roles of a user in lexical order.
+func (r *RoleRepositoryPostgres) GetUserRoles(userID string) ([]string, error) {
+	roles := []string{}
+	if err := r.db.Select(&roles, `SELECT role FROM user_roles WHERE user_id = $1 ORDER BY role`, userID); err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	return roles, nil
+}
+
+// GrantRole gives a user a role.
+func (r *RoleRepositoryPostgres) GrantRole(userID, role string) error {
+	query := `INSERT INTO user_roles (user_id, role) VALUES ($1, $2) ON CONFLICT (user_id, role) DO NOTHING`
+	if _, err := r.db.Exec(query, userID, role); err != nil {
+		return fmt.Errorf("failed to grant role: %w", err)
+	}
+	return nil
+}
+
+// RevokeRole takes a role from a user.
+func (r *RoleRepositoryPostgres) RevokeRole(userID, role string) error {
+	if _, err := r.db.Exec(`DELETE FROM user_roles WHERE user_id = $1 AND role = $2`, userID, role); err != nil {
+		return fmt.Errorf("failed to revoke role: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// requireAffected returns notFound if the statement did not touch any row.
+func requireAffected(res sql.Result, notFound error) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
@@ -16,6 +16,8 @@ type UserRepositoryPostgres struct {
 	db *sqlx.DB
 }
 
+var _ domain.UserRepository = (*UserRepositoryPostgres)(nil)
+
 // NewUserRepositoryPostgres creates a new UserRepositoryPostgres.
 func NewUserRepositoryPostgres(db *sqlx.DB) *UserRepositoryPostgres {
 	return &UserRepositoryPostgres{db: db}
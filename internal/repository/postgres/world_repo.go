@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// WorldRepositoryPostgres implements domain.WorldRepository for PostgreSQL.
+type WorldRepositoryPostgres struct {
+	db *sqlx.DB
+}
+
+var _ domain.WorldRepository = (*WorldRepositoryPostgres)(nil)
+
+// NewWorldRepositoryPostgres creates a new WorldRepositoryPostgres.
+func NewWorldRepositoryPostgres(db *sqlx.DB) *WorldRepositoryPostgres {
+	return &WorldRepositoryPostgres{db: db}
+}
+
+// worldInsertBatch is the number of points written per INSERT statement.
+// Each point takes 4 of the 65535 parameters a PostgreSQL statement may have.
+const worldInsertBatch = 1000
+
+// SaveWorldPoints inserts or updates terrain points by their grid coordinates in one transaction.
+func (r *WorldRepositoryPostgres) SaveWorldPoints(points []domain.World) error {
+	return r.withTx(func(tx *sqlx.Tx) error {
+		return insertWorldPoints(tx, points)
+	})
+}
+
+// ReplaceWorldPoints replaces the whole terrain with points in one transaction.
+func (r *WorldRepositoryPostgres) ReplaceWorldPoints(points []domain.World) error {
+	return r.withTx(func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM world`); err != nil {
+			return fmt.Errorf("failed to clear world points: %w", err)
+		}
+		return insertWorldPoints(tx, points)
+	})
+}
+
+// withTx runs fn in a transaction that is committed if fn succeeds.
+func (r *WorldRepositoryPostgres) withTx(fn func(tx *sqlx.Tx) error) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit world points: %w", err)
+	}
+	return nil
+}
+
+// insertWorldPoints upserts points with multi-row INSERT statements and stores
+// the IDs of the resulting rows back into points. Coordinates must not repeat within points.
+func insertWorldPoints(tx *sqlx.Tx, points []domain.World) error {
+	for start := 0; start < len(points); start += worldInsertBatch {
+		batch := points[start:min(start+worldInsertBatch, len(points))]
+
+		var query strings.Builder
+		query.WriteString(`INSERT INTO world (id, x, y, value) VALUES `)
+		args := make([]interface{}, 0, 4*len(batch))
+		index := make(map[[2]int]int, len(batch))
+		for i := range batch {
+			if batch[i].ID == "" {
+				batch[i].ID = fmt.Sprintf("%d:%d", batch[i].X, batch[i].Y)
+			}
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			n := len(args)
+			fmt.Fprintf(&query, "($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4)
+			args = append(args, batch[i].ID, batch[i].X, batch[i].Y, batch[i].Value)
+			index[[2]int{batch[i].X, batch[i].Y}] = i
+		}
+		query.WriteString(` ON CONFLICT (x, y) DO UPDATE SET value = EXCLUDED.value RETURNING id, x, y`)
+
+		var rows []domain.World
+		if err := tx.Select(&rows, query.String(), args...); err != nil {
+			return fmt.Errorf("failed to save world points: %w", err)
+		}
+		// Existing rows keep their ID, so map them back by coordinates
+		for _, row := range rows {
+			if i, ok := index[[2]int{row.X, row.Y}]; ok {
+				batch[i].ID = row.ID
+			}
+		}
+	}
+	return nil
+}
+
+// GetWorldPoint retrieves the terrain point at the given grid coordinates.
+func (r *WorldRepositoryPostgres) GetWorldPoint(x, y int) (*domain.World, error) {
+	var point domain.World
+	query := `SELECT id, x, y, value FROM world WHERE x = $1 AND y = $2`
+	err := r.db.Get(&point, query, x, y)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, util.ErrWorldPointNotFound
+		}
+		return nil, fmt.Errorf("failed to get world point: %w", err)
+	}
+	return &point, nil
+}
+
+// GetAllWorldPoints retrieves all terrain points ordered by Y, then X.
+func (r *WorldRepositoryPostgres) GetAllWorldPoints() ([]domain.World, error) {
+	var points []domain.World
+	query := `SELECT id, x, y, value FROM world ORDER BY y, x`
+	if err := r.db.Select(&points, query); err != nil {
+		return nil, fmt.Errorf("failed to get all world points: %w", err)
+	}
+	return points, nil
+}
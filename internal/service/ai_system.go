@@ -0,0 +1,417 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// Entity AI states, as sent to clients in snapshots.
+const (
+	EntityStateIdle   = "idle"   // Стоит на месте
+	EntityStateWander = "wander" // Бродит вокруг точки появления
+	EntityStateAlert  = "alert"  // Мирная сущность следит за игроком рядом
+	EntityStateChase  = "chase"  // Преследует игрока
+	EntityStateAttack = "attack" // Атакует игрока в радиусе урона
+	EntityStateReturn = "return" // Возвращается к точке появления
+)
+
+const (
+	// aiWanderRadius is how far from its home point an entity wanders.
+	aiWanderRadius = 10.0
+	// aiWanderSpeedFactor is the fraction of its speed an entity wanders at.
+	aiWanderSpeedFactor = 0.5
+	// aiMinIdle and aiMaxIdle bound the pause between two wander legs.
+	aiMinIdle = time.Second
+	aiMaxIdle = 4 * time.Second
+	// aiLeashFactor limits a chase to this many visual radii away from the home point.
+	aiLeashFactor = 2.0
+	// aiPersistInterval is how often moved entities are written back to storage.
+	aiPersistInterval = 10 * time.Second
+)
+
+// EntityUpdate is the current state of an NPC or mob.
+type EntityUpdate struct {
+	EntityID     int
+	EntityListID int
+	State        string
+	Health       float64
+	X            float64
+	Y            float64
+	Z            float64
+}
+
+// EntityAttack is an attack of an entity on a player within its damage radius.
+type EntityAttack struct {
+	EntityID int
+	PlayerID string
+	Damage   float64
+	X, Z     float64 // Позиция атакующей сущности
+}
+
+// EntityAttackMessage notifies clients near an entity that it attacked a player.
+type EntityAttackMessage struct {
+	Type     string  `json:"type"` // "entity_attack"
+	EntityID int     `json:"entity_id"`
+	PlayerID string  `json:"player_id"`
+	Damage   float64 `json:"damage"`
+}
+
+// mob is the runtime state of an entity driven by the AI.
+type mob struct {
+	entity   domain.Entity
+	template domain.EntityList
+
+	state        string
+	homeX, homeZ float64 // Точка появления, вокруг которой бродит сущность
+	goalX, goalZ float64 // Текущая цель блуждания
+	idleUntil    time.Time
+	lastAttack   time.Time
+	changed      bool // Состояние изменилось с прошлого тика
+	dirty        bool // Позиция не сохранена в хранилище
+}
+
+// AISystem drives NPCs and mobs from the attributes of their EntityList template:
+// entities wander around their home point, notice players within VisualRadius,
+// chase them at Speed if IsAngry and attack within DamageRadius once per Cooldown.
+// It is stepped by the game loop and is not safe for concurrent use.
+type AISystem struct {
+	entityRepo     domain.EntityRepository
+	entityListRepo domain.EntityListRepository
+	terrainService *TerrainService
+	logger         *util.Logger
+
+	rng         *rand.Rand
+	mobs        map[int]*mob
+	order       []int // ID сущностей по возрастанию, чтобы тики были воспроизводимы
+	lastPersist time.Time
+}
+
+// NewAISystem creates a new AISystem.
+func NewAISystem(
+	entityRepo domain.EntityRepository,
+	entityListRepo domain.EntityListRepository,
+	terrainService *TerrainService,
+	logger *util.Logger,
+) *AISystem {
+	return &AISystem{
+		entityRepo:     entityRepo,
+		entityListRepo: entityListRepo,
+		terrainService: terrainService,
+		logger:         logger,
+		rng:            rand.New(rand.NewSource(time.Now().UnixNano())),
+		mobs:           make(map[int]*mob),
+	}
+}
+
+// Load reads all entities and their templates from storage.
+func (s *AISystem) Load() error {
+	templates, err := s.entityListRepo.GetAllEntityLists()
+	if err != nil {
+		return fmt.Errorf("failed to load entity templates: %w", err)
+	}
+	byID := make(map[int]domain.EntityList, len(templates))
+	for _, template := range templates {
+		byID[template.ID] = template
+	}
+
+	entities, err := s.entityRepo.GetAllEntities()
+	if err != nil {
+		return fmt.Errorf("failed to load entities: %w", err)
+	}
+	s.mobs = make(map[int]*mob, len(entities))
+	s.order = s.order[:0]
+	for _, entity := range entities {
+		if entity.Health <= 0 {
+			continue // Убитые сущности остаются в хранилище, но не возвращаются в мир
+		}
+		template, ok := byID[entity.EntityListID]
+		if !ok {
+			s.logger.Error("Entity %d refers to unknown template %d, skipping", entity.ID, entity.EntityListID)
+			continue
+		}
+		s.mobs[entity.ID] = &mob{
+			entity:   entity,
+			template: template,
+			state:    EntityStateIdle,
+			homeX:    entity.X,
+			homeZ:    entity.Z,
+			changed:  true,
+		}
+		s.order = append(s.order, entity.ID)
+	}
+	sort.Ints(s.order)
+
+	s.logger.Info("AI loaded %d entities of %d templates", len(s.mobs), len(templates))
+	return nil
+}
+
+// ReloadTemplates re-reads the entity templates and applies them to the entities
+// in the world. Entities whose template was deleted are removed; their IDs are returned.
+func (s *AISystem) ReloadTemplates() ([]int, error) {
+	templates, err := s.entityListRepo.GetAllEntityLists()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load entity templates: %w", err)
+	}
+	byID := make(map[int]domain.EntityList, len(templates))
+	for _, template := range templates {
+		byID[template.ID] = template
+	}
+
+	var removed []int
+	for _, id := range append([]int(nil), s.order...) {
+		m := s.mobs[id]
+		template, ok := byID[m.entity.EntityListID]
+		if !ok {
+			s.Remove(id)
+			removed = append(removed, id)
+			continue
+		}
+		m.template = template
+		if m.entity.Health > template.MaxHealth {
+			m.entity.Health = template.MaxHealth
+			m.changed, m.dirty = true, true
+		}
+	}
+	return removed, nil
+}
+
+// Add starts driving a newly spawned entity.
+func (s *AISystem) Add(entity domain.Entity, template domain.EntityList) {
+	if _, ok := s.mobs[entity.ID]; !ok {
+		i := sort.SearchInts(s.order, entity.ID)
+		s.order = append(s.order, 0)
+		copy(s.order[i+1:], s.order[i:])
+		s.order[i] = entity.ID
+	}
+	s.mobs[entity.ID] = &mob{
+		entity:   entity,
+		template: template,
+		state:    EntityStateIdle,
+		homeX:    entity.X,
+		homeZ:    entity.Z,
+		changed:  true,
+	}
+}
+
+// Remove stops driving an entity, e.g. after it was despawned.
+func (s *AISystem) Remove(id int) bool {
+	if _, ok := s.mobs[id]; !ok {
+		return false
+	}
+	delete(s.mobs, id)
+	if i := sort.SearchInts(s.order, id); i < len(s.order) && s.order[i] == id {
+		s.order = append(s.order[:i], s.order[i+1:]...)
+	}
+	return true
+}
+
+// Entity returns the current state of a living entity.
+func (s *AISystem) Entity(id int) (EntityUpdate, bool) {
+	m, ok := s.mobs[id]
+	if !ok || m.entity.Health <= 0 {
+		return EntityUpdate{}, false
+	}
+	return m.update(), true
+}
+
+// Template returns the EntityList template of a living entity.
+func (s *AISystem) Template(id int) (domain.EntityList, bool) {
+	m, ok := s.mobs[id]
+	if !ok || m.entity.Health <= 0 {
+		return domain.EntityList{}, false
+	}
+	return m.template, true
+}
+
+// Damage lowers an entity's health by amount. An entity whose health drops to zero
+// is killed: its health is persisted at once and the AI stops driving it.
+// It returns the entity's state after the hit and whether it died.
+func (s *AISystem) Damage(id int, amount float64) (EntityUpdate, bool) {
+	m, ok := s.mobs[id]
+	if !ok || m.entity.Health <= 0 {
+		return EntityUpdate{}, false
+	}
+	m.entity.Health = math.Max(m.entity.Health-amount, 0)
+	m.changed, m.dirty = true, true
+	if m.entity.Health > 0 {
+		return m.update(), false
+	}
+
+	if err := s.entityRepo.UpdateEntity(&m.entity); err != nil {
+		s.logger.Error("Failed to persist death of entity %d: %v", m.entity.ID, err)
+	}
+	update := m.update()
+	s.Remove(id)
+	return update, true
+}
+
+// Step advances all entities by dt seconds given the current player positions.
+// It returns the entities whose state changed and the attacks made during the step.
+func (s *AISystem) Step(now time.Time, dt float64, players map[string]Position) ([]EntityUpdate, []EntityAttack) {
+	var updates []EntityUpdate
+	var attacks []EntityAttack
+
+	for _, id := range s.order {
+		m := s.mobs[id]
+		if m.entity.Health <= 0 {
+			continue
+		}
+		if attack, ok := s.think(m, now, dt, players); ok {
+			attacks = append(attacks, attack)
+		}
+		if m.changed {
+			updates = append(updates, m.update())
+			m.changed = false
+		}
+	}
+
+	if now.Sub(s.lastPersist) >= aiPersistInterval {
+		s.Persist()
+		s.lastPersist = now
+	}
+	return updates, attacks
+}
+
+// think runs one decision step for an entity.
+func (s *AISystem) think(m *mob, now time.Time, dt float64, players map[string]Position) (EntityAttack, bool) {
+	t := &m.template
+	targetID, target, dist, seen := nearestPlayer(m.entity.X, m.entity.Z, t.VisualRadius, players)
+	leashed := distance2D(m.entity.X, m.entity.Z, m.homeX, m.homeZ) > t.VisualRadius*aiLeashFactor
+
+	switch {
+	case seen && t.IsAngry && !leashed:
+		if dist <= t.DamageRadius {
+			m.setState(EntityStateAttack)
+			if now.Sub(m.lastAttack).Seconds() >= t.Cooldown {
+				m.lastAttack = now
+				return EntityAttack{EntityID: m.entity.ID, PlayerID: targetID, Damage: t.Damage, X: m.entity.X, Z: m.entity.Z}, true
+			}
+			return EntityAttack{}, false
+		}
+		m.setState(EntityStateChase)
+		// Stop just inside the damage radius rather than on top of the player
+		s.moveTowards(m, target.X, target.Z, math.Min(t.Speed*dt, dist-t.DamageRadius*0.9))
+
+	case seen && !t.IsAngry:
+		m.setState(EntityStateAlert)
+
+	case leashed || m.state == EntityStateReturn || m.state == EntityStateChase || m.state == EntityStateAttack:
+		m.setState(EntityStateReturn)
+		if s.moveTowards(m, m.homeX, m.homeZ, t.Speed*dt) {
+			s.rest(m, now)
+		}
+
+	case m.state == EntityStateWander:
+		if s.moveTowards(m, m.goalX, m.goalZ, t.Speed*aiWanderSpeedFactor*dt) {
+			s.rest(m, now)
+		}
+
+	default:
+		if m.state == EntityStateAlert {
+			s.rest(m, now)
+		}
+		if now.After(m.idleUntil) && t.Speed > 0 {
+			angle := s.rng.Float64() * 2 * math.Pi
+			radius := aiWanderRadius * math.Sqrt(s.rng.Float64())
+			m.goalX, m.goalZ = m.homeX+radius*math.Cos(angle), m.homeZ+radius*math.Sin(angle)
+			m.setState(EntityStateWander)
+		}
+	}
+	return EntityAttack{}, false
+}
+
+// rest makes an entity stand still for a random while.
+func (s *AISystem) rest(m *mob, now time.Time) {
+	m.setState(EntityStateIdle)
+	m.idleUntil = now.Add(aiMinIdle + time.Duration(s.rng.Int63n(int64(aiMaxIdle-aiMinIdle))))
+}
+
+// moveTowards moves an entity up to step units towards (x, z), following the terrain.
+// It returns true once the entity has arrived.
+func (s *AISystem) moveTowards(m *mob, x, z, step float64) bool {
+	dist := distance2D(m.entity.X, m.entity.Z, x, z)
+	if dist < 1e-6 {
+		return true
+	}
+	if step <= 0 {
+		return false
+	}
+	if step >= dist {
+		m.entity.X, m.entity.Z = x, z
+	} else {
+		m.entity.X += (x - m.entity.X) / dist * step
+		m.entity.Z += (z - m.entity.Z) / dist * step
+	}
+	if height, ok := s.terrainService.HeightAt(m.entity.X, m.entity.Z); ok {
+		m.entity.Y = height
+	}
+	m.changed, m.dirty = true, true
+	return step >= dist
+}
+
+// Persist writes the entities that moved since the last call back to storage.
+func (s *AISystem) Persist() {
+	saved := 0
+	for _, id := range s.order {
+		m := s.mobs[id]
+		if !m.dirty {
+			continue
+		}
+		if err := s.entityRepo.UpdateEntity(&m.entity); err != nil {
+			s.logger.Error("Failed to persist entity %d: %v", m.entity.ID, err)
+			continue
+		}
+		m.dirty = false
+		saved++
+	}
+	if saved > 0 {
+		s.logger.Info("Persisted %d entities", saved)
+	}
+}
+
+// setState switches an entity to a new AI state.
+func (m *mob) setState(state string) {
+	if m.state != state {
+		m.state = state
+		m.changed = true
+	}
+}
+
+// update returns the entity's state for broadcasting.
+func (m *mob) update() EntityUpdate {
+	return EntityUpdate{
+		EntityID:     m.entity.ID,
+		EntityListID: m.entity.EntityListID,
+		State:        m.state,
+		Health:       m.entity.Health,
+		X:            m.entity.X,
+		Y:            m.entity.Y,
+		Z:            m.entity.Z,
+	}
+}
+
+// nearestPlayer finds the closest player within radius of (x, z) on the X/Z plane.
+func nearestPlayer(x, z, radius float64, players map[string]Position) (string, Position, float64, bool) {
+	bestID, best, bestDist := "", Position{}, math.Inf(1)
+	for id, pos := range players {
+		dist := distance2D(x, z, pos.X, pos.Z)
+		if dist > radius {
+			continue
+		}
+		// Break ties by ID so the choice does not depend on map order
+		if dist < bestDist || (dist == bestDist && id < bestID) {
+			bestID, best, bestDist = id, pos, dist
+		}
+	}
+	return bestID, best, bestDist, bestID != ""
+}
+
+func distance2D(x1, z1, x2, z2 float64) float64 {
+	return math.Hypot(x2-x1, z2-z1)
+}
@@ -5,33 +5,56 @@ import (
 	"anarchy-core/internal/domain"
 	"anarchy-core/internal/util"
 	"errors"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
-// AuthService handles user authentication and registration.
+// TokenPair is a short-lived access token together with the refresh token that renews it.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    time.Duration // Время жизни токена доступа
+}
+
+// AuthService handles user authentication and registration. Logins hand out a
+// short-lived access token and a refresh token. Every refresh rotates the refresh
+// token; presenting a rotated one again revokes all tokens descended from the same login.
 type AuthService struct {
-	userRepo   domain.UserRepository
-	jwtManager *auth.JWTManager
-	logger     *util.Logger
+	userRepo    domain.UserRepository
+	refreshRepo domain.RefreshTokenRepository
+	roleService *RoleService
+	jwtManager  *auth.JWTManager
+	refreshTTL  time.Duration
+	logger      *util.Logger
 }
 
-// NewAuthService creates a new AuthService.
-func NewAuthService(userRepo domain.UserRepository, jwtManager *auth.JWTManager, logger *util.Logger) *AuthService {
+// NewAuthService creates a new AuthService issuing refresh tokens valid for refreshTTL.
+func NewAuthService(
+	userRepo domain.UserRepository,
+	refreshRepo domain.RefreshTokenRepository,
+	roleService *RoleService,
+	jwtManager *auth.JWTManager,
+	refreshTTL time.Duration,
+	logger *util.Logger,
+) *AuthService {
 	return &AuthService{
-		userRepo:   userRepo,
-		jwtManager: jwtManager,
-		logger:     logger,
+		userRepo:    userRepo,
+		refreshRepo: refreshRepo,
+		roleService: roleService,
+		jwtManager:  jwtManager,
+		refreshTTL:  refreshTTL,
+		logger:      logger,
 	}
 }
 
 // RegisterUser registers a new user.
-func (s *AuthService) RegisterUser(username, password string) (string, error) {
+func (s *AuthService) RegisterUser(username, password string) (*TokenPair, error) {
 	// Hash the password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		s.logger.Error("Failed to hash password: %v", err)
-		return "", util.ErrInternalServer
+		return nil, util.ErrInternalServer
 	}
 
 	user := &domain.User{
@@ -42,47 +65,127 @@ func (s *AuthService) RegisterUser(username, password string) (string, error) {
 	err = s.userRepo.CreateUser(user)
 	if err != nil {
 		if errors.Is(err, util.ErrUserAlreadyExists) {
-			return "", err
+			return nil, err
 		}
 		s.logger.Error("Failed to create user in repository: %v", err)
-		return "", util.ErrInternalServer
+		return nil, util.ErrInternalServer
 	}
 
-	// Generate JWT token for the newly registered user
-	token, err := s.jwtManager.GenerateToken(user.ID, user.Username)
+	// Generate tokens for the newly registered user
+	tokens, err := s.login(user)
 	if err != nil {
-		s.logger.Error("Failed to generate token for new user: %v", err)
-		return "", util.ErrInternalServer
+		s.logger.Error("Failed to generate tokens for new user: %v", err)
+		return nil, util.ErrInternalServer
 	}
 
 	s.logger.Info("User registered successfully: %s", username)
-	return token, nil
+	return tokens, nil
 }
 
-// LoginUser authenticates a user and returns a JWT token.
-func (s *AuthService) LoginUser(username, password string) (string, error) {
+// LoginUser authenticates a user and returns a new token pair.
+func (s *AuthService) LoginUser(username, password string) (*TokenPair, error) {
 	user, err := s.userRepo.GetUserByUsername(username)
 	if err != nil {
 		if errors.Is(err, util.ErrUserNotFound) {
-			return "", util.ErrInvalidCredentials
+			return nil, util.ErrInvalidCredentials
 		}
 		s.logger.Error("Failed to get user by username for login: %v", err)
-		return "", util.ErrInternalServer
+		return nil, util.ErrInternalServer
 	}
 
 	// Compare the provided password with the stored hash
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
 	if err != nil {
-		return "", util.ErrInvalidCredentials
+		return nil, util.ErrInvalidCredentials
 	}
 
-	// Generate JWT token
-	token, err := s.jwtManager.GenerateToken(user.ID, user.Username)
+	// Generate tokens
+	tokens, err := s.login(user)
 	if err != nil {
-		s.logger.Error("Failed to generate token for login: %v", err)
-		return "", util.ErrInternalServer
+		s.logger.Error("Failed to generate tokens for login: %v", err)
+		return nil, util.ErrInternalServer
 	}
 
 	s.logger.Info("User logged in successfully: %s", username)
-	return token, nil
+	return tokens, nil
+}
+
+// RefreshTokens exchanges a refresh token for a new token pair. The access token
+// carries the user's current roles. A refresh token can be exchanged only once.
+func (s *AuthService) RefreshTokens(refreshToken string) (*TokenPair, error) {
+	token, hash, err := auth.NewRefreshToken()
+	if err != nil {
+		s.logger.Error("Failed to generate refresh token: %v", err)
+		return nil, util.ErrInternalServer
+	}
+	now := time.Now()
+	next := &domain.RefreshToken{TokenHash: hash, ExpiresAt: now.Add(s.refreshTTL)}
+	current, err := s.refreshRepo.RotateRefreshToken(auth.HashRefreshToken(refreshToken), next, now)
+	if err != nil {
+		if errors.Is(err, util.ErrRefreshTokenReused) {
+			s.logger.Info("Reuse of a rotated refresh token detected, revoked its token family")
+			return nil, util.ErrInvalidToken
+		}
+		if errors.Is(err, util.ErrInvalidToken) {
+			return nil, err
+		}
+		s.logger.Error("Failed to rotate refresh token: %v", err)
+		return nil, util.ErrInternalServer
+	}
+
+	user, err := s.userRepo.GetUserByID(current.UserID)
+	if err != nil {
+		s.logger.Error("Failed to get user %s for token refresh: %v", current.UserID, err)
+		return nil, util.ErrInternalServer
+	}
+	accessToken, err := s.issueToken(user)
+	if err != nil {
+		s.logger.Error("Failed to generate token for refresh: %v", err)
+		return nil, util.ErrInternalServer
+	}
+	return &TokenPair{AccessToken: accessToken, RefreshToken: token, ExpiresIn: s.jwtManager.AccessTTL()}, nil
+}
+
+// Logout revokes a refresh token together with the tokens it was rotated from and into.
+// Access tokens already issued stay valid until they expire.
+func (s *AuthService) Logout(refreshToken string) error {
+	err := s.refreshRepo.RevokeRefreshTokenFamily(auth.HashRefreshToken(refreshToken), time.Now())
+	if err != nil {
+		if errors.Is(err, util.ErrInvalidToken) {
+			return err
+		}
+		s.logger.Error("Failed to revoke refresh token family: %v", err)
+		return util.ErrInternalServer
+	}
+	return nil
+}
+
+// login issues the token pair of a new session of user.
+func (s *AuthService) login(user *domain.User) (*TokenPair, error) {
+	accessToken, err := s.issueToken(user)
+	if err != nil {
+		return nil, err
+	}
+	token, hash, err := auth.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	refreshToken := &domain.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}
+	if err := s.refreshRepo.CreateRefreshToken(refreshToken); err != nil {
+		return nil, err
+	}
+	return &TokenPair{AccessToken: accessToken, RefreshToken: token, ExpiresIn: s.jwtManager.AccessTTL()}, nil
+}
+
+// issueToken generates a JWT token carrying the current roles of user.
+func (s *AuthService) issueToken(user *domain.User) (string, error) {
+	roles, err := s.roleService.RolesFor(user)
+	if err != nil {
+		return "", err
+	}
+	return s.jwtManager.GenerateToken(user.ID, user.Username, roles)
 }
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// catalogRefresh is how long a built catalog is served before it is read from storage again.
+const catalogRefresh = 30 * time.Second
+
+// CatalogObject is an object definition as published to clients.
+type CatalogObject struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Image       string `json:"image"`
+	Description string `json:"description"`
+	Rarity      int    `json:"rarity"`
+}
+
+// CatalogItem is an item definition as published to clients, with the look of its object.
+type CatalogItem struct {
+	ID           int    `json:"id"`
+	ObjectListID int    `json:"object_list_id"`
+	Name         string `json:"name"`
+	Image        string `json:"image"`
+	Description  string `json:"description"`
+	Rarity       int    `json:"rarity"`
+	Stackable    bool   `json:"stackable"`
+}
+
+// CatalogEntity is an entity template as published to clients, with the look of its object.
+type CatalogEntity struct {
+	ID           int     `json:"id"`
+	ObjectListID int     `json:"object_list_id"`
+	Name         string  `json:"name"`
+	Image        string  `json:"image"`
+	Description  string  `json:"description"`
+	Rarity       int     `json:"rarity"`
+	Model        string  `json:"model"`
+	MaxHealth    float64 `json:"max_health"`
+	Damage       float64 `json:"damage"`
+	DamageRadius float64 `json:"damage_radius"`
+	Speed        float64 `json:"speed"`
+	VisualRadius float64 `json:"visual_radius"`
+	IsAngry      bool    `json:"is_angry"`
+	IsOpen       bool    `json:"is_open"`
+	IsPickUp     bool    `json:"is_pick_up"`
+}
+
+// CatalogQuery selects a page of catalog entries. Nil rarity bounds are not applied.
+type CatalogQuery struct {
+	Limit     int
+	Offset    int
+	MinRarity *int
+	MaxRarity *int
+}
+
+// CatalogPage is a page of catalog entries.
+type CatalogPage[T any] struct {
+	Entries []T `json:"entries"`
+	Total   int `json:"total"` // Количество записей, подходящих под фильтр
+	Limit   int `json:"limit"`
+	Offset  int `json:"offset"`
+}
+
+// catalog is a snapshot of all published definitions ordered by ID.
+type catalog struct {
+	objects  []CatalogObject
+	items    []CatalogItem
+	entities []CatalogEntity
+}
+
+// CatalogService publishes the object, item and entity definitions clients need
+// to render the world. Objects, items and entities are tied together by their
+// ObjectList, whose name, image and description they share. An object or entity
+// has the rarity of the item definition of its ObjectList, or 0 if there is none.
+type CatalogService struct {
+	objectListRepo domain.ObjectListRepository
+	objectRepo     domain.ObjectRepository
+	itemListRepo   domain.ItemListRepository
+	entityListRepo domain.EntityListRepository
+	logger         *util.Logger
+
+	mu      sync.Mutex
+	catalog *catalog
+	builtAt time.Time
+}
+
+// NewCatalogService creates a new CatalogService.
+func NewCatalogService(
+	objectListRepo domain.ObjectListRepository,
+	objectRepo domain.ObjectRepository,
+	itemListRepo domain.ItemListRepository,
+	entityListRepo domain.EntityListRepository,
+	logger *util.Logger,
+) *CatalogService {
+	return &CatalogService{
+		objectListRepo: objectListRepo,
+		objectRepo:     objectRepo,
+		itemListRepo:   itemListRepo,
+		entityListRepo: entityListRepo,
+		logger:         logger,
+	}
+}
+
+// Objects returns a page of object definitions.
+func (s *CatalogService) Objects(query CatalogQuery) (*CatalogPage[CatalogObject], error) {
+	c, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	return paginate(c.objects, query, func(o CatalogObject) int { return o.Rarity }), nil
+}
+
+// Items returns a page of item definitions.
+func (s *CatalogService) Items(query CatalogQuery) (*CatalogPage[CatalogItem], error) {
+	c, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	return paginate(c.items, query, func(i CatalogItem) int { return i.Rarity }), nil
+}
+
+// Entities returns a page of entity templates.
+func (s *CatalogService) Entities(query CatalogQuery) (*CatalogPage[CatalogEntity], error) {
+	c, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	return paginate(c.entities, query, func(e CatalogEntity) int { return e.Rarity }), nil
+}
+
+// Invalidate makes the next request read the catalog from storage.
+func (s *CatalogService) Invalidate() {
+	s.mu.Lock()
+	s.catalog = nil
+	s.mu.Unlock()
+}
+
+// current returns the catalog, rebuilding it when it is missing or stale.
+func (s *CatalogService) current() (*catalog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.catalog != nil && now.Sub(s.builtAt) < catalogRefresh {
+		return s.catalog, nil
+	}
+	c, err := s.build()
+	if err != nil {
+		return nil, err
+	}
+	s.catalog, s.builtAt = c, now
+	return c, nil
+}
+
+// build reads all definitions from storage.
+func (s *CatalogService) build() (*catalog, error) {
+	objectLists, err := s.objectListRepo.GetAllObjectLists()
+	if err != nil {
+		return nil, err
+	}
+	itemLists, err := s.itemListRepo.GetAllItemLists()
+	if err != nil {
+		return nil, err
+	}
+	entityLists, err := s.entityListRepo.GetAllEntityLists()
+	if err != nil {
+		return nil, err
+	}
+
+	looks := make(map[int]domain.ObjectList, len(objectLists))
+	for _, objectList := range objectLists {
+		looks[objectList.ID] = objectList
+	}
+
+	c := &catalog{
+		objects:  make([]CatalogObject, 0, len(objectLists)),
+		items:    make([]CatalogItem, 0, len(itemLists)),
+		entities: make([]CatalogEntity, 0, len(entityLists)),
+	}
+	rarities := make(map[int]int) // Редкость по ID ObjectList
+	for _, itemList := range itemLists {
+		item := CatalogItem{ID: itemList.ID, Rarity: itemList.Rarity, Stackable: itemList.IsStackable}
+		if itemList.ObjectID != 0 {
+			object, err := s.objectRepo.GetObjectByID(itemList.ObjectID)
+			switch {
+			case errors.Is(err, util.ErrObjectNotFound):
+				s.logger.Error("Object %d of item definition %d not found", itemList.ObjectID, itemList.ID)
+			case err != nil:
+				return nil, err
+			default:
+				item.ObjectListID = object.ObjectListID
+			}
+		}
+		if look, ok := looks[item.ObjectListID]; ok {
+			item.Name, item.Image, item.Description = look.Name, look.Image, look.Description
+			// Item definitions are ordered by ID, so the first one of an ObjectList sets its rarity
+			if _, seen := rarities[look.ID]; !seen {
+				rarities[look.ID] = itemList.Rarity
+			}
+		}
+		c.items = append(c.items, item)
+	}
+	for _, objectList := range objectLists {
+		c.objects = append(c.objects, CatalogObject{
+			ID:          objectList.ID,
+			Name:        objectList.Name,
+			Image:       objectList.Image,
+			Description: objectList.Description,
+			Rarity:      rarities[objectList.ID],
+		})
+	}
+	for _, entityList := range entityLists {
+		look := looks[entityList.ObjectListID]
+		c.entities = append(c.entities, CatalogEntity{
+			ID:           entityList.ID,
+			ObjectListID: entityList.ObjectListID,
+			Name:         look.Name,
+			Image:        look.Image,
+			Description:  look.Description,
+			Rarity:       rarities[entityList.ObjectListID],
+			Model:        entityList.Model,
+			MaxHealth:    entityList.MaxHealth,
+			Damage:       entityList.Damage,
+			DamageRadius: entityList.DamageRadius,
+			Speed:        entityList.Speed,
+			VisualRadius: entityList.VisualRadius,
+			IsAngry:      entityList.IsAngry,
+			IsOpen:       entityList.IsOpen,
+			IsPickUp:     entityList.IsPickUp,
+		})
+	}
+	return c, nil
+}
+
+// paginate filters entries by rarity and cuts out the requested page.
+func paginate[T any](entries []T, query CatalogQuery, rarity func(T) int) *CatalogPage[T] {
+	filtered := make([]T, 0, len(entries))
+	for _, entry := range entries {
+		r := rarity(entry)
+		if (query.MinRarity != nil && r < *query.MinRarity) || (query.MaxRarity != nil && r > *query.MaxRarity) {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+
+	page := &CatalogPage[T]{Total: len(filtered), Limit: query.Limit, Offset: query.Offset}
+	start := min(query.Offset, len(filtered))
+	end := min(start+query.Limit, len(filtered))
+	page.Entries = filtered[start:end]
+	return page
+}
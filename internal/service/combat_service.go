@@ -0,0 +1,463 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// Kinds of combat participants, as sent to clients in combat events.
+const (
+	CombatantEntity = "entity"
+	CombatantPlayer = "player"
+)
+
+// Reasons an attack is rejected.
+const (
+	AttackMissNoTarget   = "no_target"    // Цель не найдена или уже мертва
+	AttackMissOutOfRange = "out_of_range" // Цель дальше дальности атаки
+	AttackMissCooldown   = "cooldown"     // Атака раньше окончания перезарядки
+	AttackMissDead       = "dead"         // Атакующий игрок мёртв
+)
+
+// combatPersistInterval is how often changed player stats are written back to storage.
+const combatPersistInterval = 10 * time.Second
+
+// CombatConfig holds the combat parameters of players.
+type CombatConfig struct {
+	MaxHealth      float64
+	Damage         float64
+	AttackRange    float64
+	AttackCooldown time.Duration
+	RespawnDelay   time.Duration
+}
+
+// AttackInput is an attack requested by a player and resolved on the next tick.
+type AttackInput struct {
+	PlayerID   string
+	TargetType string // CombatantEntity или CombatantPlayer
+	TargetID   string
+}
+
+// PlayerRespawn is a dead player brought back at a new position.
+type PlayerRespawn struct {
+	PlayerID string
+	Position Position
+}
+
+// DamageMessage notifies clients near the target that it took damage.
+type DamageMessage struct {
+	Type       string  `json:"type"` // "damage"
+	TargetType string  `json:"target_type"`
+	TargetID   string  `json:"target_id"`
+	SourceType string  `json:"source_type"`
+	SourceID   string  `json:"source_id"`
+	Amount     float64 `json:"amount"`
+	Health     float64 `json:"health"`
+}
+
+// DeathMessage notifies clients near the target that it was killed.
+type DeathMessage struct {
+	Type       string `json:"type"` // "death"
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	KillerType string `json:"killer_type"`
+	KillerID   string `json:"killer_id"`
+}
+
+// RespawnMessage notifies clients near the spawn point that a player or entity (re)appeared.
+type RespawnMessage struct {
+	Type       string  `json:"type"` // "respawn"
+	TargetType string  `json:"target_type"`
+	TargetID   string  `json:"target_id"`
+	X          float64 `json:"x"`
+	Y          float64 `json:"y"`
+	Z          float64 `json:"z"`
+	Health     float64 `json:"health"`
+}
+
+// AttackMissedMessage tells a player why its attack was not carried out.
+type AttackMissedMessage struct {
+	Type       string `json:"type"` // "attack_missed"
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	Reason     string `json:"reason"`
+}
+
+// PlayerStatsMessage tells a player its own combat state.
+type PlayerStatsMessage struct {
+	Type      string  `json:"type"` // "player_stats"
+	Health    float64 `json:"health"`
+	MaxHealth float64 `json:"max_health"`
+	Deaths    int     `json:"deaths"`
+	Dead      bool    `json:"dead"`
+}
+
+// combatant is the combat state of a player.
+type combatant struct {
+	stats      domain.PlayerStats
+	diedAt     time.Time
+	lastAttack time.Time
+	dirty      bool // Характеристики не сохранены в хранилище
+}
+
+func (c *combatant) dead() bool {
+	return c.stats.Health <= 0
+}
+
+// CombatService resolves attacks of players on entities and other players, applies
+// the attacks of entities on players and respawns dead players. Hits are resolved
+// on the game loop tick; damage, death and respawn events go to nearby clients.
+type CombatService struct {
+	statsRepo        domain.PlayerStatsRepository
+	websocketService *WebSocketService
+	terrainService   *TerrainService
+	aiSystem         *AISystem
+	spawner          *SpawnerService
+	config           CombatConfig
+	logger           *util.Logger
+
+	mu          sync.Mutex
+	players     map[string]*combatant
+	attacks     map[string]AttackInput // Последняя атака каждого игрока с прошлого тика
+	lastPersist time.Time
+}
+
+// NewCombatService creates a new CombatService.
+func NewCombatService(
+	statsRepo domain.PlayerStatsRepository,
+	websocketService *WebSocketService,
+	terrainService *TerrainService,
+	aiSystem *AISystem,
+	spawner *SpawnerService,
+	config CombatConfig,
+	logger *util.Logger,
+) *CombatService {
+	return &CombatService{
+		statsRepo:        statsRepo,
+		websocketService: websocketService,
+		terrainService:   terrainService,
+		aiSystem:         aiSystem,
+		spawner:          spawner,
+		config:           config,
+		logger:           logger,
+		players:          make(map[string]*combatant),
+		attacks:          make(map[string]AttackInput),
+	}
+}
+
+// QueueAttack queues a player's attack for the next tick.
+// Only the most recent attack per player is kept between ticks.
+func (s *CombatService) QueueAttack(input AttackInput) {
+	s.mu.Lock()
+	s.attacks[input.PlayerID] = input
+	s.mu.Unlock()
+}
+
+// SendStats sends a client the combat state of its player.
+func (s *CombatService) SendStats(client *Client) {
+	s.mu.Lock()
+	c := s.combatantLocked(client.UserID, time.Now())
+	msg := PlayerStatsMessage{
+		Type:      "player_stats",
+		Health:    c.stats.Health,
+		MaxHealth: c.stats.MaxHealth,
+		Deaths:    c.stats.Deaths,
+		Dead:      c.dead(),
+	}
+	s.mu.Unlock()
+
+	s.websocketService.SendToClient(client, msg)
+}
+
+// IsDead reports whether a player is dead and waiting to respawn.
+func (s *CombatService) IsDead(playerID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.players[playerID]
+	return ok && c.dead()
+}
+
+// combatantLocked returns the combat state of a player, loading it from storage
+// on first use. Players without stored stats start at full health. s.mu must be held.
+func (s *CombatService) combatantLocked(playerID string, now time.Time) *combatant {
+	if c, ok := s.players[playerID]; ok {
+		return c
+	}
+
+	c := &combatant{stats: domain.PlayerStats{PlayerID: playerID, Health: s.config.MaxHealth, MaxHealth: s.config.MaxHealth}}
+	stats, err := s.statsRepo.GetPlayerStats(playerID)
+	switch {
+	case err == nil:
+		c.stats = *stats
+		c.stats.MaxHealth = s.config.MaxHealth
+		c.stats.Health = math.Min(c.stats.Health, c.stats.MaxHealth)
+		if c.dead() {
+			// Players that were dead when the server stopped respawn after the usual delay
+			c.diedAt = now
+		}
+	case errors.Is(err, util.ErrPlayerStatsNotFound):
+		c.dirty = true
+	default:
+		s.logger.Error("Failed to load stats of player %s: %v", playerID, err)
+	}
+	s.players[playerID] = c
+	return c
+}
+
+// Respawns brings back the players whose respawn delay has passed. They reappear
+// at full health at the terrain's spawn point; the caller moves them there.
+func (s *CombatService) Respawns(now time.Time) []PlayerRespawn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var respawns []PlayerRespawn
+	for id, c := range s.players {
+		if !c.dead() || now.Sub(c.diedAt) < s.config.RespawnDelay {
+			continue
+		}
+		c.stats.Health = c.stats.MaxHealth
+		c.dirty = true
+		pos := s.terrainService.SpawnPoint()
+		respawns = append(respawns, PlayerRespawn{PlayerID: id, Position: pos})
+
+		s.websocketService.SendNear(pos.X, pos.Z, RespawnMessage{
+			Type:       "respawn",
+			TargetType: CombatantPlayer,
+			TargetID:   id,
+			X:          pos.X,
+			Y:          pos.Y,
+			Z:          pos.Z,
+			Health:     c.stats.Health,
+		})
+	}
+	sort.Slice(respawns, func(i, j int) bool { return respawns[i].PlayerID < respawns[j].PlayerID })
+	return respawns
+}
+
+// EntitySpawned announces an entity placed in the world by the spawner.
+func (s *CombatService) EntitySpawned(entity domain.Entity) {
+	s.websocketService.SendNear(entity.X, entity.Z, RespawnMessage{
+		Type:       "respawn",
+		TargetType: CombatantEntity,
+		TargetID:   strconv.Itoa(entity.ID),
+		X:          entity.X,
+		Y:          entity.Y,
+		Z:          entity.Z,
+		Health:     entity.Health,
+	})
+}
+
+// ApplyEntityAttacks deals the damage of the entities' attacks to their target players.
+func (s *CombatService) ApplyEntityAttacks(attacks []EntityAttack, players map[string]Position, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, attack := range attacks {
+		pos, ok := players[attack.PlayerID]
+		if !ok {
+			continue
+		}
+		s.damagePlayerLocked(attack.PlayerID, pos, attack.Damage, CombatantEntity, strconv.Itoa(attack.EntityID), now)
+	}
+}
+
+// ResolveAttacks carries out the attacks queued by players since the previous tick.
+// Attackers must be alive, off cooldown and within attack range of a living target.
+// It returns the IDs of the entities killed.
+func (s *CombatService) ResolveAttacks(players map[string]Position, now time.Time) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.attacks) == 0 {
+		s.persistIfDueLocked(now)
+		return nil
+	}
+	attacks := make([]AttackInput, 0, len(s.attacks))
+	for _, attack := range s.attacks {
+		attacks = append(attacks, attack)
+	}
+	s.attacks = make(map[string]AttackInput, len(attacks))
+	// Resolve attacks in a stable order so ticks are reproducible
+	sort.Slice(attacks, func(i, j int) bool { return attacks[i].PlayerID < attacks[j].PlayerID })
+
+	var killed []int
+	for _, attack := range attacks {
+		if id, ok := s.resolveLocked(attack, players, now); ok {
+			killed = append(killed, id)
+		}
+	}
+	s.persistIfDueLocked(now)
+	return killed
+}
+
+// resolveLocked carries out one attack. It returns the ID of the entity it killed, if any.
+// s.mu must be held.
+func (s *CombatService) resolveLocked(attack AttackInput, players map[string]Position, now time.Time) (int, bool) {
+	attacker := s.combatantLocked(attack.PlayerID, now)
+	origin, online := players[attack.PlayerID]
+	switch {
+	case attacker.dead():
+		s.missLocked(attack, AttackMissDead)
+		return 0, false
+	case !online:
+		s.missLocked(attack, AttackMissNoTarget)
+		return 0, false
+	case now.Sub(attacker.lastAttack) < s.config.AttackCooldown:
+		s.missLocked(attack, AttackMissCooldown)
+		return 0, false
+	}
+
+	switch attack.TargetType {
+	case CombatantEntity:
+		id, err := strconv.Atoi(attack.TargetID)
+		if err != nil {
+			s.missLocked(attack, AttackMissNoTarget)
+			return 0, false
+		}
+		target, ok := s.aiSystem.Entity(id)
+		if !ok {
+			s.missLocked(attack, AttackMissNoTarget)
+			return 0, false
+		}
+		if distance2D(origin.X, origin.Z, target.X, target.Z) > s.config.AttackRange {
+			s.missLocked(attack, AttackMissOutOfRange)
+			return 0, false
+		}
+		attacker.lastAttack = now
+		return s.damageEntityLocked(id, attack.PlayerID, now)
+
+	case CombatantPlayer:
+		target, ok := players[attack.TargetID]
+		if !ok || attack.TargetID == attack.PlayerID || s.combatantLocked(attack.TargetID, now).dead() {
+			s.missLocked(attack, AttackMissNoTarget)
+			return 0, false
+		}
+		if distance2D(origin.X, origin.Z, target.X, target.Z) > s.config.AttackRange {
+			s.missLocked(attack, AttackMissOutOfRange)
+			return 0, false
+		}
+		attacker.lastAttack = now
+		s.damagePlayerLocked(attack.TargetID, target, s.config.Damage, CombatantPlayer, attack.PlayerID, now)
+		return 0, false
+	}
+
+	s.missLocked(attack, AttackMissNoTarget)
+	return 0, false
+}
+
+// damageEntityLocked deals a player's damage to an entity. It returns the entity's
+// ID if the hit killed it. s.mu must be held.
+func (s *CombatService) damageEntityLocked(entityID int, playerID string, now time.Time) (int, bool) {
+	update, killed := s.aiSystem.Damage(entityID, s.config.Damage)
+	targetID := strconv.Itoa(entityID)
+	s.websocketService.SendNear(update.X, update.Z, DamageMessage{
+		Type:       "damage",
+		TargetType: CombatantEntity,
+		TargetID:   targetID,
+		SourceType: CombatantPlayer,
+		SourceID:   playerID,
+		Amount:     s.config.Damage,
+		Health:     update.Health,
+	})
+	if !killed {
+		return 0, false
+	}
+
+	s.websocketService.SendNear(update.X, update.Z, DeathMessage{
+		Type:       "death",
+		TargetType: CombatantEntity,
+		TargetID:   targetID,
+		KillerType: CombatantPlayer,
+		KillerID:   playerID,
+	})
+	s.spawner.EntityDied(entityID, update.EntityListID, now)
+	s.logger.Info("Entity %d was killed by player %s", entityID, playerID)
+	return entityID, true
+}
+
+// damagePlayerLocked deals damage to a player standing at pos. s.mu must be held.
+func (s *CombatService) damagePlayerLocked(playerID string, pos Position, amount float64, sourceType, sourceID string, now time.Time) {
+	c := s.combatantLocked(playerID, now)
+	if c.dead() {
+		return
+	}
+	c.stats.Health = math.Max(c.stats.Health-amount, 0)
+	c.dirty = true
+	s.websocketService.SendNear(pos.X, pos.Z, DamageMessage{
+		Type:       "damage",
+		TargetType: CombatantPlayer,
+		TargetID:   playerID,
+		SourceType: sourceType,
+		SourceID:   sourceID,
+		Amount:     amount,
+		Health:     c.stats.Health,
+	})
+	if !c.dead() {
+		return
+	}
+
+	c.diedAt = now
+	c.stats.Deaths++
+	s.websocketService.SendNear(pos.X, pos.Z, DeathMessage{
+		Type:       "death",
+		TargetType: CombatantPlayer,
+		TargetID:   playerID,
+		KillerType: sourceType,
+		KillerID:   sourceID,
+	})
+	// Deaths are written at once so that a restart cannot undo them
+	s.saveLocked(c)
+	s.logger.Info("Player %s was killed by %s %s", playerID, sourceType, sourceID)
+}
+
+// missLocked tells a player that its attack was rejected. s.mu must be held.
+func (s *CombatService) missLocked(attack AttackInput, reason string) {
+	s.websocketService.SendToPlayer(attack.PlayerID, AttackMissedMessage{
+		Type:       "attack_missed",
+		TargetType: attack.TargetType,
+		TargetID:   attack.TargetID,
+		Reason:     reason,
+	})
+}
+
+// persistIfDueLocked writes changed stats back every combatPersistInterval. s.mu must be held.
+func (s *CombatService) persistIfDueLocked(now time.Time) {
+	if now.Sub(s.lastPersist) < combatPersistInterval {
+		return
+	}
+	s.lastPersist = now
+	s.persistLocked()
+}
+
+// Persist writes the player stats changed since the last call back to storage.
+func (s *CombatService) Persist() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.persistLocked()
+}
+
+func (s *CombatService) persistLocked() {
+	for _, c := range s.players {
+		if c.dirty {
+			s.saveLocked(c)
+		}
+	}
+}
+
+// saveLocked writes a player's stats to storage. s.mu must be held.
+func (s *CombatService) saveLocked(c *combatant) {
+	if err := s.statsRepo.SavePlayerStats(&c.stats); err != nil {
+		s.logger.Error("Failed to persist stats of player %s: %v", c.stats.PlayerID, err)
+		return
+	}
+	c.dirty = false
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+
+	"anarchy-core/internal/util"
+)
+
+// Container actions a player can queue.
+const (
+	ContainerActionOpen  = "open_container"
+	ContainerActionClose = "close_container"
+	ContainerActionTake  = "container_take" // Из контейнера в инвентарь игрока
+	ContainerActionPut   = "container_put"  // Из инвентаря игрока в контейнер
+)
+
+// Reasons a container is closed by the server.
+const (
+	ContainerClosedByPlayer   = "closed"       // Игрок закрыл контейнер
+	ContainerClosedGone       = "gone"         // Сущность контейнера исчезла
+	ContainerClosedOutOfRange = "out_of_range" // Игрок отошёл слишком далеко
+	ContainerClosedLeft       = "left"         // Игрок умер или отключился
+)
+
+// ContainerAction is a container action requested by a player and resolved on the next tick.
+type ContainerAction struct {
+	PlayerID string
+	Action   string // Одно из ContainerAction*
+	EntityID int    // Открываемый контейнер
+	Slot     int    // Слот, из которого берутся предметы
+	ToSlot   int    // Слот назначения, отрицательный — любой подходящий
+	Quantity int    // Количество предметов, 0 — весь слот
+}
+
+// ContainerMessage sends the player that opened a container its contents.
+type ContainerMessage struct {
+	Type     string `json:"type"` // "container"
+	EntityID int    `json:"entity_id"`
+	InventoryView
+}
+
+// ContainerClosedMessage tells a player that its open container was closed.
+type ContainerClosedMessage struct {
+	Type     string `json:"type"` // "container_closed"
+	EntityID int    `json:"entity_id"`
+	Reason   string `json:"reason"`
+}
+
+// ContainerService lets players open entities whose template has IsOpen, such as
+// chests and corpses, and move items between the container's inventory and their own.
+// A container's inventory is owned by the entity ID. A container can be open for one
+// player at a time, and every transfer is a single transaction under the inventory
+// lock, so two players can never take the same items out of a container.
+type ContainerService struct {
+	inventoryService *InventoryService
+	aiSystem         *AISystem
+	combatService    *CombatService
+	websocketService *WebSocketService
+	containerRange   float64
+	logger           *util.Logger
+
+	mu      sync.Mutex
+	actions []ContainerAction // Действия в порядке поступления
+
+	open     map[int]string // Игрок, открывший контейнер, по ID сущности
+	openedBy map[string]int // Открытый контейнер по ID игрока
+}
+
+// NewContainerService creates a new ContainerService.
+func NewContainerService(
+	inventoryService *InventoryService,
+	aiSystem *AISystem,
+	combatService *CombatService,
+	websocketService *WebSocketService,
+	containerRange float64,
+	logger *util.Logger,
+) *ContainerService {
+	return &ContainerService{
+		inventoryService: inventoryService,
+		aiSystem:         aiSystem,
+		combatService:    combatService,
+		websocketService: websocketService,
+		containerRange:   containerRange,
+		logger:           logger,
+		open:             make(map[int]string),
+		openedBy:         make(map[string]int),
+	}
+}
+
+// ContainerOwnerID returns the inventory owner ID of a container entity.
+func ContainerOwnerID(entityID int) string {
+	return strconv.Itoa(entityID)
+}
+
+// QueueAction queues a container action for the next tick. Unlike movement,
+// every action is kept, so quick successive transfers are not lost.
+func (s *ContainerService) QueueAction(action ContainerAction) {
+	s.mu.Lock()
+	s.actions = append(s.actions, action)
+	s.mu.Unlock()
+}
+
+// drainActions takes all actions queued since the previous tick.
+func (s *ContainerService) drainActions() []ContainerAction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	actions := s.actions
+	s.actions = nil
+	return actions
+}
+
+// Resolve closes containers whose player left, died or walked away, then carries
+// out the actions queued since the previous tick given the positions of the living players.
+func (s *ContainerService) Resolve(players map[string]Position) {
+	s.closeStale(players)
+
+	for _, action := range s.drainActions() {
+		pos, alive := players[action.PlayerID]
+		if !alive || s.combatService.IsDead(action.PlayerID) {
+			s.fail(action, ItemActionDead)
+			continue
+		}
+		switch action.Action {
+		case ContainerActionOpen:
+			s.openContainer(action, pos)
+		case ContainerActionClose:
+			if entityID, ok := s.openedBy[action.PlayerID]; ok {
+				s.close(entityID, ContainerClosedByPlayer)
+			}
+		case ContainerActionTake, ContainerActionPut:
+			s.transfer(action)
+		}
+	}
+}
+
+// openContainer opens a container for a player within range, closing the one it had open.
+func (s *ContainerService) openContainer(action ContainerAction, pos Position) {
+	entity, ok := s.aiSystem.Entity(action.EntityID)
+	if !ok {
+		s.fail(action, ItemActionNoTarget)
+		return
+	}
+	if template, _ := s.aiSystem.Template(action.EntityID); !template.IsOpen {
+		s.fail(action, ItemActionNotContainer)
+		return
+	}
+	if distance2D(pos.X, pos.Z, entity.X, entity.Z) > s.containerRange {
+		s.fail(action, ItemActionOutOfRange)
+		return
+	}
+	if holder, taken := s.open[action.EntityID]; taken && holder != action.PlayerID {
+		s.fail(action, ItemActionInUse)
+		return
+	}
+	if current, ok := s.openedBy[action.PlayerID]; ok && current != action.EntityID {
+		s.close(current, ContainerClosedByPlayer)
+	}
+
+	s.open[action.EntityID] = action.PlayerID
+	s.openedBy[action.PlayerID] = action.EntityID
+	s.push(action.EntityID, action.PlayerID)
+}
+
+// transfer moves items between the player's inventory and the container it has open.
+func (s *ContainerService) transfer(action ContainerAction) {
+	if entityID, ok := s.openedBy[action.PlayerID]; !ok || entityID != action.EntityID {
+		s.fail(action, ItemActionNotOpen)
+		return
+	}
+
+	container := ContainerOwnerID(action.EntityID)
+	var err error
+	if action.Action == ContainerActionTake {
+		err = s.inventoryService.Transfer(container, action.Slot, action.PlayerID, action.ToSlot, action.Quantity)
+	} else {
+		err = s.inventoryService.Transfer(action.PlayerID, action.Slot, container, action.ToSlot, action.Quantity)
+	}
+	switch {
+	case err == nil:
+		s.push(action.EntityID, action.PlayerID)
+	case errors.Is(err, util.ErrInventoryEntryNotFound):
+		s.fail(action, ItemActionEmptySlot)
+	case errors.Is(err, util.ErrInventoryFull):
+		s.fail(action, ItemActionInventoryFull)
+	case errors.Is(err, util.ErrInvalidInventoryMove), errors.Is(err, util.ErrInvalidQuantity):
+		s.fail(action, ItemActionInvalidMove)
+	default:
+		s.logger.Error("Failed to %s for player %s at container %d: %v", action.Action, action.PlayerID, action.EntityID, err)
+		s.fail(action, ItemActionFailed)
+	}
+}
+
+// closeStale closes the containers that can no longer stay open.
+func (s *ContainerService) closeStale(players map[string]Position) {
+	entityIDs := make([]int, 0, len(s.open))
+	for entityID := range s.open {
+		entityIDs = append(entityIDs, entityID)
+	}
+	sort.Ints(entityIDs)
+
+	for _, entityID := range entityIDs {
+		playerID := s.open[entityID]
+		pos, alive := players[playerID]
+		entity, exists := s.aiSystem.Entity(entityID)
+		switch {
+		case !alive:
+			s.close(entityID, ContainerClosedLeft)
+		case !exists:
+			s.close(entityID, ContainerClosedGone)
+		case distance2D(pos.X, pos.Z, entity.X, entity.Z) > s.containerRange:
+			s.close(entityID, ContainerClosedOutOfRange)
+		}
+	}
+}
+
+// close releases a container and tells the player that had it open.
+func (s *ContainerService) close(entityID int, reason string) {
+	playerID, ok := s.open[entityID]
+	if !ok {
+		return
+	}
+	delete(s.open, entityID)
+	delete(s.openedBy, playerID)
+	s.websocketService.SendToPlayer(playerID, ContainerClosedMessage{Type: "container_closed", EntityID: entityID, Reason: reason})
+}
+
+// push sends a player the contents of the container it has open.
+func (s *ContainerService) push(entityID int, playerID string) {
+	view, err := s.inventoryService.Inventory(ContainerOwnerID(entityID))
+	if err != nil {
+		s.logger.Error("Failed to load inventory of container %d: %v", entityID, err)
+		return
+	}
+	s.websocketService.SendToPlayer(playerID, ContainerMessage{Type: "container", EntityID: entityID, InventoryView: *view})
+}
+
+// fail tells a player that its container action was rejected.
+func (s *ContainerService) fail(action ContainerAction, reason string) {
+	s.websocketService.SendToPlayer(action.PlayerID, ItemActionFailedMessage{
+		Type:     "item_action_failed",
+		Action:   action.Action,
+		EntityID: action.EntityID,
+		Slot:     action.Slot,
+		Reason:   reason,
+	})
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// maxContentStringLength is the length of the VARCHAR columns of the definition tables.
+const maxContentStringLength = 255
+
+// Actor identifies the administrator making a content change.
+type Actor struct {
+	UserID   string
+	Username string
+}
+
+// ContentService manages the object, item and entity definitions of the game.
+// Definitions are validated before they are stored, every change is recorded in
+// the audit log, and running game systems pick up the changes without a restart.
+type ContentService struct {
+	objectListRepo  domain.ObjectListRepository
+	objectRepo      domain.ObjectRepository
+	itemListRepo    domain.ItemListRepository
+	entityListRepo  domain.EntityListRepository
+	auditLogRepo    domain.AuditLogRepository
+	catalogService  *CatalogService
+	gameLoopService *GameLoopService
+	logger          *util.Logger
+}
+
+// NewContentService creates a new ContentService.
+func NewContentService(
+	objectListRepo domain.ObjectListRepository,
+	objectRepo domain.ObjectRepository,
+	itemListRepo domain.ItemListRepository,
+	entityListRepo domain.EntityListRepository,
+	auditLogRepo domain.AuditLogRepository,
+	catalogService *CatalogService,
+	gameLoopService *GameLoopService,
+	logger *util.Logger,
+) *ContentService {
+	return &ContentService{
+		objectListRepo:  objectListRepo,
+		objectRepo:      objectRepo,
+		itemListRepo:    itemListRepo,
+		entityListRepo:  entityListRepo,
+		auditLogRepo:    auditLogRepo,
+		catalogService:  catalogService,
+		gameLoopService: gameLoopService,
+		logger:          logger,
+	}
+}
+
+// Reload makes the running server use the current definitions. It is called after
+// every change made through this service and when another server announces one.
+func (s *ContentService) Reload(table string) {
+	s.catalogService.Invalidate()
+	s.gameLoopService.ReloadContent()
+	if table != "" {
+		s.logger.Info("Content definitions in %s changed, reloading", table)
+	}
+}
+
+// ObjectLists returns all object definitions.
+func (s *ContentService) ObjectLists() ([]domain.ObjectList, error) {
+	return s.objectListRepo.GetAllObjectLists()
+}
+
+// CreateObjectList validates and stores a new object definition.
+func (s *ContentService) CreateObjectList(actor Actor, objectList domain.ObjectList) (*domain.ObjectList, error) {
+	if err := ValidateObjectList(objectList); err != nil {
+		return nil, err
+	}
+	objectList.ID = 0
+	if err := s.objectListRepo.CreateObjectList(&objectList, s.audit(actor)); err != nil {
+		return nil, err
+	}
+	s.Reload("object_list")
+	return &objectList, nil
+}
+
+// UpdateObjectList validates and replaces an object definition.
+func (s *ContentService) UpdateObjectList(actor Actor, objectList domain.ObjectList) (*domain.ObjectList, error) {
+	if err := ValidateObjectList(objectList); err != nil {
+		return nil, err
+	}
+	if err := s.objectListRepo.UpdateObjectList(&objectList, s.audit(actor)); err != nil {
+		return nil, err
+	}
+	s.Reload("object_list")
+	return &objectList, nil
+}
+
+// DeleteObjectList deletes an object definition that no item or entity uses.
+func (s *ContentService) DeleteObjectList(actor Actor, id int) error {
+	if err := s.objectListRepo.DeleteObjectList(id, s.audit(actor)); err != nil {
+		return err
+	}
+	s.Reload("object_list")
+	return nil
+}
+
+// ItemLists returns all item definitions.
+func (s *ContentService) ItemLists() ([]domain.ItemDefinition, error) {
+	itemLists, err := s.itemListRepo.GetAllItemLists()
+	if err != nil {
+		return nil, err
+	}
+	definitions := make([]domain.ItemDefinition, 0, len(itemLists))
+	for _, itemList := range itemLists {
+		definition := domain.ItemDefinition{ItemList: itemList}
+		if itemList.ObjectID != 0 {
+			object, err := s.objectRepo.GetObjectByID(itemList.ObjectID)
+			if err != nil && !errors.Is(err, util.ErrObjectNotFound) {
+				return nil, err
+			}
+			if object != nil {
+				definition.ObjectListID = object.ObjectListID
+			}
+		}
+		definitions = append(definitions, definition)
+	}
+	return definitions, nil
+}
+
+// CreateItemList validates and stores a new item definition, creating the object
+// that ties it to its ObjectList.
+func (s *ContentService) CreateItemList(actor Actor, definition domain.ItemDefinition) (*domain.ItemDefinition, error) {
+	if err := s.validateItemDefinition(definition); err != nil {
+		return nil, err
+	}
+	definition.ID, definition.ObjectID = 0, 0
+	if err := s.itemListRepo.CreateItemList(&definition, s.audit(actor)); err != nil {
+		return nil, err
+	}
+	s.Reload("item_list")
+	return &definition, nil
+}
+
+// UpdateItemList validates and replaces an item definition, moving it to an object
+// of its ObjectList if that changed.
+func (s *ContentService) UpdateItemList(actor Actor, definition domain.ItemDefinition) (*domain.ItemDefinition, error) {
+	if err := s.validateItemDefinition(definition); err != nil {
+		return nil, err
+	}
+	if err := s.itemListRepo.UpdateItemList(&definition, s.audit(actor)); err != nil {
+		return nil, err
+	}
+	s.Reload("item_list")
+	return &definition, nil
+}
+
+// DeleteItemList deletes an item definition of which no items exist.
+func (s *ContentService) DeleteItemList(actor Actor, id int) error {
+	if err := s.itemListRepo.DeleteItemList(id, s.audit(actor)); err != nil {
+		return err
+	}
+	s.Reload("item_list")
+	return nil
+}
+
+// EntityLists returns all entity templates.
+func (s *ContentService) EntityLists() ([]domain.EntityList, error) {
+	return s.entityListRepo.GetAllEntityLists()
+}
+
+// CreateEntityList validates and stores a new entity template.
+func (s *ContentService) CreateEntityList(actor Actor, entityList domain.EntityList) (*domain.EntityList, error) {
+	if err := s.validateEntityList(entityList); err != nil {
+		return nil, err
+	}
+	entityList.ID = 0
+	if err := s.entityListRepo.CreateEntityList(&entityList, s.audit(actor)); err != nil {
+		return nil, err
+	}
+	s.Reload("entity_list")
+	return &entityList, nil
+}
+
+// UpdateEntityList validates and replaces an entity template. Entities in the world
+// take on the new attributes; a changed spawn rule respawns the template's population.
+func (s *ContentService) UpdateEntityList(actor Actor, entityList domain.EntityList) (*domain.EntityList, error) {
+	if err := s.validateEntityList(entityList); err != nil {
+		return nil, err
+	}
+	if err := s.entityListRepo.UpdateEntityList(&entityList, s.audit(actor)); err != nil {
+		return nil, err
+	}
+	s.Reload("entity_list")
+	return &entityList, nil
+}
+
+// DeleteEntityList deletes an entity template together with its entities.
+func (s *ContentService) DeleteEntityList(actor Actor, id int) error {
+	if err := s.entityListRepo.DeleteEntityList(id, s.audit(actor)); err != nil {
+		return err
+	}
+	s.Reload("entity_list")
+	return nil
+}
+
+// AuditLog returns a page of the audit log, newest first.
+func (s *ContentService) AuditLog(limit, offset int) ([]domain.AuditEntry, error) {
+	return s.auditLogRepo.GetAuditEntries(limit, offset)
+}
+
+// audit starts the audit entry of a change made by actor.
+func (s *ContentService) audit(actor Actor) *domain.AuditEntry {
+	return &domain.AuditEntry{UserID: actor.UserID, Username: actor.Username}
+}
+
+// validateItemDefinition validates an item definition and checks that its ObjectList exists.
+func (s *ContentService) validateItemDefinition(definition domain.ItemDefinition) error {
+	if err := ValidateItemList(definition.ItemList); err != nil {
+		return err
+	}
+	return s.requireObjectList(definition.ObjectListID)
+}
+
+// validateEntityList validates an entity template and checks that its ObjectList exists.
+func (s *ContentService) validateEntityList(entityList domain.EntityList) error {
+	if err := ValidateEntityList(entityList); err != nil {
+		return err
+	}
+	return s.requireObjectList(entityList.ObjectListID)
+}
+
+// requireObjectList checks that a referenced ObjectList exists; 0 refers to none.
+func (s *ContentService) requireObjectList(id int) error {
+	if id == 0 {
+		return nil
+	}
+	if _, err := s.objectListRepo.GetObjectListByID(id); err != nil {
+		if errors.Is(err, util.ErrObjectListNotFound) {
+			return invalidDefinition("object_list_id %d does not exist", id)
+		}
+		return err
+	}
+	return nil
+}
+
+// ValidateObjectList checks the attributes of an object definition.
+func ValidateObjectList(objectList domain.ObjectList) error {
+	if strings.TrimSpace(objectList.Name) == "" {
+		return invalidDefinition("name is required")
+	}
+	if len(objectList.Name) > maxContentStringLength {
+		return invalidDefinition("name must be at most %d bytes", maxContentStringLength)
+	}
+	if len(objectList.Image) > maxContentStringLength {
+		return invalidDefinition("image must be at most %d bytes", maxContentStringLength)
+	}
+	return nil
+}
+
+// ValidateItemList checks the attributes of an item definition.
+func ValidateItemList(itemList domain.ItemList) error {
+	if itemList.Rarity < domain.MinRarity || itemList.Rarity > domain.MaxRarity {
+		return invalidDefinition("rarity must be between %d and %d", domain.MinRarity, domain.MaxRarity)
+	}
+	return nil
+}
+
+// ValidateEntityList checks the attributes of an entity template.
+func ValidateEntityList(entityList domain.EntityList) error {
+	if !(entityList.MaxHealth > 0) || math.IsInf(entityList.MaxHealth, 0) {
+		return invalidDefinition("max_health must be positive")
+	}
+	for _, attr := range []struct {
+		name  string
+		value float64
+	}{
+		{"damage", entityList.Damage},
+		{"speed", entityList.Speed},
+		{"cooldown", entityList.Cooldown},
+		{"damage_radius", entityList.DamageRadius},
+		{"visual_radius", entityList.VisualRadius},
+	} {
+		if !(attr.value >= 0) || math.IsInf(attr.value, 0) {
+			return invalidDefinition("%s must not be negative", attr.name)
+		}
+	}
+	if len(entityList.Model) > maxContentStringLength {
+		return invalidDefinition("model must be at most %d bytes", maxContentStringLength)
+	}
+	if len(entityList.Spawn) > maxContentStringLength {
+		return invalidDefinition("spawn must be at most %d bytes", maxContentStringLength)
+	}
+	if entityList.IsSpawning && entityList.Spawn == "" {
+		return invalidDefinition("spawn is required for a spawning template")
+	}
+	if entityList.Spawn != "" {
+		if _, err := ParseSpawnRule(entityList.Spawn); err != nil {
+			return invalidDefinition("spawn: %v", err)
+		}
+	}
+	return nil
+}
+
+// invalidDefinition returns a util.ErrInvalidDefinition explaining what is wrong.
+func invalidDefinition(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", util.ErrInvalidDefinition, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"anarchy-core/internal/protocol"
+	"anarchy-core/internal/util"
+)
+
+// PlayerInput is a movement request received from a client and applied on the next tick.
+type PlayerInput struct {
+	PlayerID string
+	Username string
+	X        float64
+	Y        float64
+	Z        float64
+}
+
+// PositionCorrectionMessage tells a client that its move was rejected and where its player actually is.
+type PositionCorrectionMessage struct {
+	Type   string  `json:"type"` // "position_correction"
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Z      float64 `json:"z"`
+	Reason string  `json:"reason"`
+}
+
+// GameLoopService runs the server-authoritative simulation at a fixed tick rate.
+// Client inputs are queued as they arrive and applied once per tick, after which
+// a single consolidated state update is emitted to the WebSocketService.
+// Moves are clamped to the terrain and validated against the player's authoritative
+// position before being applied; dead players cannot move until they respawn.
+type GameLoopService struct {
+	playerService    *PlayerService
+	websocketService *WebSocketService
+	terrainService   *TerrainService
+	validator        *MovementValidator
+	aiSystem         *AISystem
+	spawner          *SpawnerService
+	combatService    *CombatService
+	pickupService    *PickupService
+	containerService *ContainerService
+	tickInterval     time.Duration
+	logger           *util.Logger
+
+	inputMu sync.Mutex
+	inputs  map[string]PlayerInput // Последний ввод каждого игрока с прошлого тика
+
+	contentChanged atomic.Bool // Определения контента изменились, шаблоны нужно перечитать
+
+	tick uint64
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewGameLoopService creates a new GameLoopService ticking tickRate times per second.
+func NewGameLoopService(
+	playerService *PlayerService,
+	websocketService *WebSocketService,
+	terrainService *TerrainService,
+	validator *MovementValidator,
+	aiSystem *AISystem,
+	spawner *SpawnerService,
+	combatService *CombatService,
+	pickupService *PickupService,
+	containerService *ContainerService,
+	tickRate int,
+	logger *util.Logger,
+) *GameLoopService {
+	return &GameLoopService{
+		playerService:    playerService,
+		websocketService: websocketService,
+		terrainService:   terrainService,
+		validator:        validator,
+		aiSystem:         aiSystem,
+		spawner:          spawner,
+		combatService:    combatService,
+		pickupService:    pickupService,
+		containerService: containerService,
+		tickInterval:     time.Second / time.Duration(tickRate),
+		logger:           logger,
+		inputs:           make(map[string]PlayerInput),
+		stop:             make(chan struct{}),
+		done:             make(chan struct{}),
+	}
+}
+
+// QueueInput queues a player's input for the next tick.
+// Only the most recent input per player is kept between ticks.
+func (s *GameLoopService) QueueInput(input PlayerInput) {
+	s.inputMu.Lock()
+	s.inputs[input.PlayerID] = input
+	s.inputMu.Unlock()
+}
+
+// ReloadContent makes the next tick re-read the entity and item definitions,
+// so that changes made while the server runs take effect without a restart.
+func (s *GameLoopService) ReloadContent() {
+	s.contentChanged.Store(true)
+}
+
+// Run starts the game loop and blocks until Stop is called.
+func (s *GameLoopService) Run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.tickInterval)
+	defer ticker.Stop()
+
+	s.logger.Info("Game loop started at %d ticks per second", time.Second/s.tickInterval)
+	for {
+		select {
+		case <-ticker.C:
+			s.step()
+		case <-s.stop:
+			s.playerService.Persist()
+			s.aiSystem.Persist()
+			s.combatService.Persist()
+			s.logger.Info("Game loop stopped after %d ticks", s.tick)
+			return
+		}
+	}
+}
+
+// Stop stops the game loop and waits for the current tick to finish.
+func (s *GameLoopService) Stop() {
+	close(s.stop)
+	<-s.done
+}
+
+// drainInputs takes all inputs queued since the previous tick.
+func (s *GameLoopService) drainInputs() []PlayerInput {
+	s.inputMu.Lock()
+	defer s.inputMu.Unlock()
+
+	if len(s.inputs) == 0 {
+		return nil
+	}
+	inputs := make([]PlayerInput, 0, len(s.inputs))
+	for _, input := range s.inputs {
+		inputs = append(inputs, input)
+	}
+	s.inputs = make(map[string]PlayerInput, len(inputs))
+
+	// Apply inputs in a stable order so ticks are reproducible
+	sort.Slice(inputs, func(i, j int) bool { return inputs[i].PlayerID < inputs[j].PlayerID })
+	return inputs
+}
+
+// step advances the simulation by one tick.
+func (s *GameLoopService) step() {
+	s.tick++
+	now := time.Now()
+
+	updates := s.applyInputs(s.drainInputs(), now)
+	updates = append(updates, s.respawnPlayers(now)...)
+	s.playerService.PersistIfDue(now)
+
+	spawned, removed := s.spawner.Step(now)
+	reload := s.contentChanged.Swap(false)
+	if reload {
+		removed = append(removed, s.spawner.Reload(now)...)
+	}
+	for _, id := range removed {
+		s.aiSystem.Remove(id)
+	}
+	if reload {
+		removed = append(removed, s.reloadTemplates()...)
+	}
+	for _, spawn := range spawned {
+		s.aiSystem.Add(spawn.Entity, spawn.Template)
+		s.combatService.EntitySpawned(spawn.Entity)
+	}
+
+	// Entities react to where living players are after this tick's moves
+	players := s.websocketService.PlayerPositions()
+	for _, update := range updates {
+		players[update.PlayerID] = Position{X: update.X, Y: update.Y, Z: update.Z}
+	}
+	for id := range players {
+		if s.combatService.IsDead(id) {
+			delete(players, id)
+		}
+	}
+	entityUpdates, attacks := s.aiSystem.Step(now, s.tickInterval.Seconds(), players)
+	for _, attack := range attacks {
+		s.websocketService.SendNear(attack.X, attack.Z, EntityAttackMessage{
+			Type:     "entity_attack",
+			EntityID: attack.EntityID,
+			PlayerID: attack.PlayerID,
+			Damage:   attack.Damage,
+		})
+	}
+	s.combatService.ApplyEntityAttacks(attacks, players, now)
+	removed = append(removed, s.combatService.ResolveAttacks(players, now)...)
+	removed = append(removed, s.pickupService.Resolve(players, now)...)
+	s.containerService.Resolve(players)
+
+	if len(updates) > 0 || len(entityUpdates) > 0 || len(removed) > 0 {
+		s.websocketService.BroadcastStateUpdate(s.tick, updates, entityUpdates, removed)
+	}
+}
+
+// reloadTemplates applies changed definitions to the entities in the world and
+// returns the IDs of the entities removed because their template was deleted.
+func (s *GameLoopService) reloadTemplates() []int {
+	removed, err := s.aiSystem.ReloadTemplates()
+	if err != nil {
+		s.logger.Error("Failed to reload entity templates: %v", err)
+	}
+	s.pickupService.ReloadTemplates()
+	s.logger.Info("Reloaded content definitions, %d entities removed", len(removed))
+	return removed
+}
+
+// applyInputs validates and applies the players' inputs of a tick.
+func (s *GameLoopService) applyInputs(inputs []PlayerInput, now time.Time) []PlayerLocationUpdate {
+	updates := make([]PlayerLocationUpdate, 0, len(inputs))
+	for _, input := range inputs {
+		if s.combatService.IsDead(input.PlayerID) {
+			continue
+		}
+		// Players cannot sink below the ground; the client is told where it really is
+		requestedY := input.Y
+		input.Y = s.terrainService.ClampToGround(input.X, input.Y, input.Z)
+		if !s.validateMove(input, now) {
+			continue
+		}
+		if input.Y != requestedY {
+			s.sendCorrection(input.PlayerID, Position{X: input.X, Y: input.Y, Z: input.Z}, "terrain")
+		}
+		s.websocketService.PushChunks(input.PlayerID, s.terrainService.ChunksNear(input.X, input.Z))
+		loc := s.playerService.UpdatePlayerLocation(input.PlayerID, input.X, input.Y, input.Z)
+		updates = append(updates, NewPlayerLocationUpdate(input.Username, loc))
+	}
+	return updates
+}
+
+// respawnPlayers moves the players whose respawn delay has passed to their respawn point.
+func (s *GameLoopService) respawnPlayers(now time.Time) []PlayerLocationUpdate {
+	var updates []PlayerLocationUpdate
+	for _, respawn := range s.combatService.Respawns(now) {
+		pos := respawn.Position
+		username := s.websocketService.PlayerName(respawn.PlayerID)
+		s.validator.Place(respawn.PlayerID, username, pos, now)
+		loc := s.playerService.UpdatePlayerLocation(respawn.PlayerID, pos.X, pos.Y, pos.Z)
+		s.sendCorrection(respawn.PlayerID, pos, "respawn")
+		s.websocketService.PushChunks(respawn.PlayerID, s.terrainService.ChunksNear(pos.X, pos.Z))
+		updates = append(updates, NewPlayerLocationUpdate(username, loc))
+	}
+	return updates
+}
+
+// PlacePlayer seeds the movement validator with a player's stored location, so that
+// the first move of a session is validated like any other. Players without a stored
+// location are placed at the spawn point. A player whose location cannot be loaded is
+// left unplaced rather than moved away from it.
+func (s *GameLoopService) PlacePlayer(playerID, username string, now time.Time) {
+	var pos Position
+	loc, err := s.playerService.GetPlayerLocation(playerID)
+	switch {
+	case err == nil:
+		pos = Position{X: loc.X, Y: loc.Y, Z: loc.Z}
+	case errors.Is(err, util.ErrPlayerLocationNotFound):
+		pos = s.terrainService.SpawnPoint()
+		s.playerService.UpdatePlayerLocation(playerID, pos.X, pos.Y, pos.Z)
+	default:
+		return
+	}
+	s.validator.Place(playerID, username, pos, now)
+}
+
+// validateMove checks an input against the player's authoritative position. Rejected
+// moves are answered with a position correction, and players whose suspicion score
+// reaches the threshold are kicked.
+func (s *GameLoopService) validateMove(input PlayerInput, now time.Time) bool {
+	if !s.validator.Known(input.PlayerID) {
+		s.PlacePlayer(input.PlayerID, input.Username, now)
+	}
+
+	result := s.validator.Validate(input.PlayerID, input.Username, Position{X: input.X, Y: input.Y, Z: input.Z}, now)
+	if result.Accepted {
+		return true
+	}
+
+	s.logger.Info("Tick %d: rejected move of player %s to (%.2f, %.2f, %.2f), suspicion %.2f",
+		s.tick, input.Username, input.X, input.Y, input.Z, result.Score)
+	s.sendCorrection(input.PlayerID, result.Position, "speed")
+	if result.Kick {
+		s.logger.Info("Kicking player %s (ID: %s) for suspected speed hacking", input.Username, input.PlayerID)
+		s.websocketService.KickPlayer(input.PlayerID, protocol.CloseKicked)
+	}
+	return false
+}
+
+// sendCorrection tells a player's client the authoritative position of its player.
+func (s *GameLoopService) sendCorrection(playerID string, pos Position, reason string) {
+	s.websocketService.SendToPlayer(playerID, PositionCorrectionMessage{
+		Type:   "position_correction",
+		X:      pos.X,
+		Y:      pos.Y,
+		Z:      pos.Z,
+		Reason: reason,
+	})
+}
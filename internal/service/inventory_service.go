@@ -0,0 +1,554 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// InventorySlot is an occupied inventory slot as shown to clients.
+type InventorySlot struct {
+	Slot       int  `json:"slot"`
+	ItemID     int  `json:"item_id"`
+	ItemListID int  `json:"item_list_id"`
+	Quantity   int  `json:"quantity"`
+	Stackable  bool `json:"stackable"`
+}
+
+// InventoryView is the contents of an inventory.
+type InventoryView struct {
+	OwnerID  string          `json:"owner_id"`
+	Capacity int             `json:"capacity"`
+	Slots    []InventorySlot `json:"slots"`
+}
+
+// InventoryMessage pushes the contents of a player's inventory to its client.
+type InventoryMessage struct {
+	Type string `json:"type"` // "inventory"
+	InventoryView
+}
+
+// inventoryContents is an inventory loaded for modification.
+type inventoryContents struct {
+	ownerID  string
+	slots    map[int]*InventorySlot
+	entries  map[int]domain.Inventory // Записи по номеру слота
+	original map[string]domain.Inventory
+}
+
+// InventoryService manages the inventories of players and entities. An inventory
+// has a fixed number of slots; each slot holds one item, or a stack of up to
+// stackSize items of the same stackable ItemList. Changes are pushed to the owner's client.
+type InventoryService struct {
+	inventoryRepo    domain.InventoryRepository
+	itemRepo         domain.ItemRepository
+	itemListRepo     domain.ItemListRepository
+	websocketService *WebSocketService
+	capacity         int
+	stackSize        int
+	logger           *util.Logger
+
+	mu sync.Mutex // Сериализует изменения инвентарей, чтобы проверка и запись не пересекались
+}
+
+// NewInventoryService creates a new InventoryService with inventories of capacity
+// slots holding stacks of up to stackSize items.
+func NewInventoryService(
+	inventoryRepo domain.InventoryRepository,
+	itemRepo domain.ItemRepository,
+	itemListRepo domain.ItemListRepository,
+	websocketService *WebSocketService,
+	capacity int,
+	stackSize int,
+	logger *util.Logger,
+) *InventoryService {
+	return &InventoryService{
+		inventoryRepo:    inventoryRepo,
+		itemRepo:         itemRepo,
+		itemListRepo:     itemListRepo,
+		websocketService: websocketService,
+		capacity:         capacity,
+		stackSize:        stackSize,
+		logger:           logger,
+	}
+}
+
+// Inventory returns the contents of an owner's inventory.
+func (s *InventoryService) Inventory(ownerID string) (*InventoryView, error) {
+	contents, err := s.load(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return contents.view(s.capacity), nil
+}
+
+// PushInventory sends a player's client the contents of its inventory.
+func (s *InventoryService) PushInventory(playerID string) {
+	view, err := s.Inventory(playerID)
+	if err != nil {
+		s.logger.Error("Failed to load inventory of player %s: %v", playerID, err)
+		return
+	}
+	s.websocketService.SendToPlayer(playerID, InventoryMessage{Type: "inventory", InventoryView: *view})
+}
+
+// PickUp turns a world entity into one unit of itemList in an owner's inventory and
+// returns the item of the slot it went into. Only a pickup that opens a new slot
+// creates an item; one that tops up a stack adds to the stack's item. The entity is
+// deleted in the same transaction, so only one of several concurrent pickups of it
+// succeeds; the others fail with util.ErrEntityNotFound.
+func (s *InventoryService) PickUp(ownerID string, entityID int, itemList domain.ItemList) (*domain.Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contents, err := s.load(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	slot, err := contents.add(domain.Item{ItemListID: itemList.ID}, itemList.IsStackable, 1, s.capacity, s.stackSize)
+	if err != nil {
+		return nil, err
+	}
+	changes := inventoryChanges(contents)
+	if err := s.inventoryRepo.PickUpEntity(entityID, changes); err != nil {
+		return nil, err
+	}
+	assignNewItems(changes.NewItems, contents)
+	s.pushLocked(contents)
+	return &domain.Item{ID: contents.slots[slot].ItemID, ItemListID: itemList.ID}, nil
+}
+
+// DropItem takes one item out of an inventory slot and turns it into a world entity
+// in one transaction. place is given the slot's contents and returns the entity to
+// create and the object list of its object.
+func (s *InventoryService) DropItem(ownerID string, slot int, place func(InventorySlot) (*domain.Entity, int, error)) (*domain.Entity, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contents, err := s.load(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	dropped, ok := contents.slots[slot]
+	if !ok {
+		return nil, util.ErrInventoryEntryNotFound
+	}
+	entity, objectListID, err := place(*dropped)
+	if err != nil {
+		return nil, err
+	}
+	if err := contents.remove(slot, 1); err != nil {
+		return nil, err
+	}
+	if err := s.inventoryRepo.DropItem(inventoryChanges(contents), entity, objectListID); err != nil {
+		return nil, err
+	}
+	s.pushLocked(contents)
+	return entity, nil
+}
+
+// RemoveItem takes quantity units out of an inventory slot; 0 removes the whole slot.
+func (s *InventoryService) RemoveItem(ownerID string, slot, quantity int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contents, err := s.load(ownerID)
+	if err != nil {
+		return err
+	}
+	if err := contents.remove(slot, quantity); err != nil {
+		return err
+	}
+	return s.save(contents)
+}
+
+// MoveItem moves quantity units from one slot to another; 0 moves the whole slot.
+// Moving onto a stack of the same stackable item merges as much as fits, moving
+// part of a stack to an empty slot splits it, and moving a whole slot onto a
+// different item swaps the two slots.
+func (s *InventoryService) MoveItem(ownerID string, from, to, quantity int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if to < 0 || to >= s.capacity || from == to {
+		return util.ErrInvalidInventoryMove
+	}
+	contents, err := s.load(ownerID)
+	if err != nil {
+		return err
+	}
+	if err := contents.move(from, to, quantity, s.stackSize); err != nil {
+		return err
+	}
+	return s.save(contents)
+}
+
+// Transfer moves quantity units from a slot of one inventory into another in one
+// transaction; 0 moves the whole slot. A negative to slot puts the items wherever
+// they fit, stacks first; otherwise they go into that slot, which must be empty or
+// hold a stack of the same item with enough room.
+func (s *InventoryService) Transfer(fromOwner string, from int, toOwner string, to, quantity int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fromOwner == toOwner || to >= s.capacity {
+		return util.ErrInvalidInventoryMove
+	}
+	src, err := s.load(fromOwner)
+	if err != nil {
+		return err
+	}
+	dst, err := s.load(toOwner)
+	if err != nil {
+		return err
+	}
+	slot, ok := src.slots[from]
+	if !ok {
+		return util.ErrInventoryEntryNotFound
+	}
+	if quantity == 0 {
+		quantity = slot.Quantity
+	}
+	moved := *slot
+	if err := src.remove(from, quantity); err != nil {
+		return err
+	}
+	if err := dst.put(moved, quantity, to, s.capacity, s.stackSize); err != nil {
+		return err
+	}
+	return s.save(src, dst)
+}
+
+// load reads an owner's inventory together with the items in it.
+func (s *InventoryService) load(ownerID string) (*inventoryContents, error) {
+	entries, err := s.inventoryRepo.GetInventoryByEntityID(ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := &inventoryContents{
+		ownerID:  ownerID,
+		slots:    make(map[int]*InventorySlot, len(entries)),
+		entries:  make(map[int]domain.Inventory, len(entries)),
+		original: make(map[string]domain.Inventory, len(entries)),
+	}
+	lists := make(map[int]*domain.ItemList)
+	for _, entry := range entries {
+		itemID, err := strconv.Atoi(entry.ItemID)
+		if err != nil {
+			return nil, fmt.Errorf("inventory entry %s has invalid item ID %q", entry.ID, entry.ItemID)
+		}
+		item, err := s.itemRepo.GetItemByID(itemID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load item %d of inventory entry %s: %w", itemID, entry.ID, err)
+		}
+		list, ok := lists[item.ItemListID]
+		if !ok {
+			if list, err = s.itemListRepo.GetItemListByID(item.ItemListID); err != nil {
+				return nil, fmt.Errorf("failed to load definition of item %d: %w", itemID, err)
+			}
+			lists[item.ItemListID] = list
+		}
+
+		contents.slots[entry.Slot] = &InventorySlot{
+			Slot:       entry.Slot,
+			ItemID:     itemID,
+			ItemListID: item.ItemListID,
+			Quantity:   entry.Quantity,
+			Stackable:  list.IsStackable,
+		}
+		contents.entries[entry.Slot] = entry
+		contents.original[entry.ID] = entry
+	}
+	return contents, nil
+}
+
+// save writes the changes made to inventories in one transaction and pushes them to their owners.
+func (s *InventoryService) save(contents ...*inventoryContents) error {
+	changes := inventoryChanges(contents...)
+	if len(changes.Saved) == 0 && len(changes.Removed) == 0 {
+		return nil
+	}
+	if err := s.inventoryRepo.SaveInventoryChanges(changes); err != nil {
+		return err
+	}
+	assignNewItems(changes.NewItems, contents...)
+	for _, c := range contents {
+		s.pushLocked(c)
+	}
+	return nil
+}
+
+// pushLocked sends the owner's client the saved contents of its inventory. s.mu must be held.
+func (s *InventoryService) pushLocked(contents *inventoryContents) {
+	s.websocketService.SendToPlayer(contents.ownerID, InventoryMessage{Type: "inventory", InventoryView: *contents.view(s.capacity)})
+}
+
+// view returns the contents ordered by slot.
+func (c *inventoryContents) view(capacity int) *InventoryView {
+	view := &InventoryView{OwnerID: c.ownerID, Capacity: capacity, Slots: make([]InventorySlot, 0, len(c.slots))}
+	for _, slot := range c.slots {
+		view.Slots = append(view.Slots, *slot)
+	}
+	sort.Slice(view.Slots, func(i, j int) bool { return view.Slots[i].Slot < view.Slots[j].Slot })
+	return view
+}
+
+// add places quantity units of item into free room, stacks first, and returns the
+// first slot the units went into.
+func (c *inventoryContents) add(item domain.Item, stackable bool, quantity, capacity, stackSize int) (int, error) {
+	first := -1
+	remaining := quantity
+	if stackable {
+		for _, slot := range c.sortedSlots() {
+			if remaining == 0 {
+				break
+			}
+			if s := c.slots[slot]; s.Stackable && s.ItemListID == item.ItemListID && s.Quantity < stackSize {
+				n := min(remaining, stackSize-s.Quantity)
+				s.Quantity += n
+				remaining -= n
+				if first < 0 {
+					first = slot
+				}
+			}
+		}
+	}
+
+	perSlot := 1
+	if stackable {
+		perSlot = stackSize
+	}
+	for slot := 0; slot < capacity && remaining > 0; slot++ {
+		if _, taken := c.slots[slot]; taken {
+			continue
+		}
+		n := min(remaining, perSlot)
+		c.slots[slot] = &InventorySlot{Slot: slot, ItemID: item.ID, ItemListID: item.ItemListID, Quantity: n, Stackable: stackable}
+		remaining -= n
+		if first < 0 {
+			first = slot
+		}
+	}
+	if remaining > 0 {
+		return 0, util.ErrInventoryFull
+	}
+	return first, nil
+}
+
+// put places quantity units of the item in slot into a given slot, or anywhere if to is negative.
+func (c *inventoryContents) put(slot InventorySlot, quantity, to, capacity, stackSize int) error {
+	if to < 0 {
+		_, err := c.add(domain.Item{ID: slot.ItemID, ItemListID: slot.ItemListID}, slot.Stackable, quantity, capacity, stackSize)
+		return err
+	}
+	dst, occupied := c.slots[to]
+	switch {
+	case !occupied:
+		c.slots[to] = &InventorySlot{Slot: to, ItemID: slot.ItemID, ItemListID: slot.ItemListID, Quantity: quantity, Stackable: slot.Stackable}
+	case slot.Stackable && dst.ItemListID == slot.ItemListID && dst.Quantity+quantity <= stackSize:
+		dst.Quantity += quantity
+	default:
+		return util.ErrInvalidInventoryMove
+	}
+	return nil
+}
+
+// remove takes quantity units out of a slot; 0 empties it.
+func (c *inventoryContents) remove(slot, quantity int) error {
+	s, ok := c.slots[slot]
+	if !ok {
+		return util.ErrInventoryEntryNotFound
+	}
+	if quantity < 0 || quantity > s.Quantity {
+		return util.ErrInvalidQuantity
+	}
+	if quantity == 0 || quantity == s.Quantity {
+		delete(c.slots, slot)
+		return nil
+	}
+	s.Quantity -= quantity
+	return nil
+}
+
+// move moves quantity units between two slots; 0 moves the whole slot.
+func (c *inventoryContents) move(from, to, quantity, stackSize int) error {
+	src, ok := c.slots[from]
+	if !ok {
+		return util.ErrInventoryEntryNotFound
+	}
+	if quantity < 0 || quantity > src.Quantity {
+		return util.ErrInvalidQuantity
+	}
+	if quantity == 0 {
+		quantity = src.Quantity
+	}
+	whole := quantity == src.Quantity
+	dst, occupied := c.slots[to]
+
+	switch {
+	case !occupied && whole:
+		delete(c.slots, from)
+		src.Slot = to
+		c.slots[to] = src
+		c.moveEntry(from, to)
+
+	case !occupied:
+		// Splitting a stack; the new stack gets an item of its own when saved
+		src.Quantity -= quantity
+		c.slots[to] = &InventorySlot{Slot: to, ItemListID: src.ItemListID, Quantity: quantity, Stackable: src.Stackable}
+
+	case src.Stackable && dst.ItemListID == src.ItemListID:
+		n := min(quantity, stackSize-dst.Quantity)
+		if n <= 0 {
+			return util.ErrInvalidInventoryMove
+		}
+		dst.Quantity += n
+		if src.Quantity -= n; src.Quantity == 0 {
+			delete(c.slots, from)
+		}
+
+	case whole:
+		src.Slot, dst.Slot = to, from
+		c.slots[from], c.slots[to] = dst, src
+		c.entries[from], c.entries[to] = c.entries[to], c.entries[from]
+
+	default:
+		return util.ErrInvalidInventoryMove
+	}
+	return nil
+}
+
+// moveEntry lets the stored entry of a slot follow its contents to another slot.
+func (c *inventoryContents) moveEntry(from, to int) {
+	if entry, ok := c.entries[from]; ok {
+		delete(c.entries, from)
+		c.entries[to] = entry
+	}
+}
+
+// inventoryChanges compares the slots of inventories changed together with their
+// stored entries and returns what must be written. Every slot gets an item of its own:
+// a slot keeps the item its stored entry refers to, the first other slot holding an
+// item no entry keeps takes it over, and any further slot holding it gets a new item.
+// Items of the stored entries that no slot holds any more are freed.
+func inventoryChanges(contents ...*inventoryContents) domain.InventoryChanges {
+	kept := make(map[int]bool)
+	for _, c := range contents {
+		for slot, s := range c.slots {
+			if c.keepsItem(slot) {
+				kept[s.ItemID] = true
+			}
+		}
+	}
+	held := make(map[int]bool)
+	for _, c := range contents {
+		for _, slot := range c.sortedSlots() {
+			s := c.slots[slot]
+			if s.ItemID == 0 {
+				continue
+			}
+			if !c.keepsItem(slot) && (kept[s.ItemID] || held[s.ItemID]) {
+				s.ItemID = 0
+				continue
+			}
+			held[s.ItemID] = true
+		}
+	}
+
+	var changes domain.InventoryChanges
+	freed := make(map[int]bool)
+	for _, c := range contents {
+		c.appendChanges(&changes)
+		for _, entry := range c.original {
+			if itemID, err := strconv.Atoi(entry.ItemID); err == nil && !held[itemID] {
+				freed[itemID] = true
+			}
+		}
+	}
+	for itemID := range freed {
+		changes.FreedItems = append(changes.FreedItems, itemID)
+	}
+	sort.Ints(changes.FreedItems)
+	return changes
+}
+
+// keepsItem reports whether a slot holds the item its stored entry refers to.
+func (c *inventoryContents) keepsItem(slot int) bool {
+	s, ok := c.slots[slot]
+	if !ok || s.ItemID == 0 {
+		return false
+	}
+	entry, ok := c.entries[slot]
+	return ok && entry.ItemID == itemRef(s.ItemID)
+}
+
+// appendChanges appends the entries of c that must be written or removed to changes.
+// Slots without an item get a new item of their ItemList.
+func (c *inventoryContents) appendChanges(changes *domain.InventoryChanges) {
+	kept := make(map[string]bool, len(c.slots))
+	for _, slot := range c.sortedSlots() {
+		s := c.slots[slot]
+		entry, ok := c.entries[slot]
+		if !ok || entry.ItemID != itemRef(s.ItemID) {
+			entry = domain.Inventory{EntityID: c.ownerID}
+		}
+		entry.ItemID = itemRef(s.ItemID)
+		entry.Slot = s.Slot
+		entry.Quantity = s.Quantity
+		if entry.ID != "" {
+			kept[entry.ID] = true
+		}
+		if entry != c.original[entry.ID] {
+			changes.Saved = append(changes.Saved, entry)
+			if entry.ItemID == "" {
+				changes.NewItems = append(changes.NewItems, domain.Item{ItemListID: s.ItemListID})
+			}
+		}
+	}
+	var removed []string
+	for id := range c.original {
+		if !kept[id] {
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(removed)
+	changes.Removed = append(changes.Removed, removed...)
+}
+
+// assignNewItems gives the slots without an item the items created for them, in the
+// order in which inventoryChanges listed the slots.
+func assignNewItems(items []domain.Item, contents ...*inventoryContents) {
+	next := 0
+	for _, c := range contents {
+		for _, slot := range c.sortedSlots() {
+			if s := c.slots[slot]; s.ItemID == 0 && next < len(items) {
+				s.ItemID = items[next].ID
+				next++
+			}
+		}
+	}
+}
+
+// itemRef returns the ItemID of an inventory entry; item 0 is the item still to be
+// created by the transaction that saves the entry.
+func itemRef(itemID int) string {
+	if itemID == 0 {
+		return ""
+	}
+	return strconv.Itoa(itemID)
+}
+
+// sortedSlots returns the occupied slot numbers in ascending order.
+func (c *inventoryContents) sortedSlots() []int {
+	slots := make([]int, 0, len(c.slots))
+	for slot := range c.slots {
+		slots = append(slots, slot)
+	}
+	sort.Ints(slots)
+	return slots
+}
@@ -0,0 +1,455 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+const (
+	testCapacity  = 3
+	testStackSize = 10
+)
+
+// stack is a slot holding quantity units of the stackable ItemList 1.
+func stack(slot, itemID, quantity int) InventorySlot {
+	return InventorySlot{Slot: slot, ItemID: itemID, ItemListID: 1, Quantity: quantity, Stackable: true}
+}
+
+// sword is a slot holding an item of the unstackable ItemList 2.
+func sword(slot, itemID int) InventorySlot {
+	return InventorySlot{Slot: slot, ItemID: itemID, ItemListID: 2, Quantity: 1}
+}
+
+// entry is the stored inventory entry "e<slot>" of owner.
+func entry(owner string, slot, itemID, quantity int) domain.Inventory {
+	return domain.Inventory{ID: "e" + strconv.Itoa(slot), EntityID: owner, ItemID: itemRef(itemID), Slot: slot, Quantity: quantity}
+}
+
+// newContents builds the contents of owner as loaded from stored entries "e<slot>".
+func newContents(owner string, slots ...InventorySlot) *inventoryContents {
+	c := &inventoryContents{
+		ownerID:  owner,
+		slots:    make(map[int]*InventorySlot),
+		entries:  make(map[int]domain.Inventory),
+		original: make(map[string]domain.Inventory),
+	}
+	for _, s := range slots {
+		s := s
+		c.slots[s.Slot] = &s
+		e := entry(owner, s.Slot, s.ItemID, s.Quantity)
+		c.entries[s.Slot] = e
+		c.original[e.ID] = e
+	}
+	return c
+}
+
+// slotsOf returns the slots of c ordered by slot.
+func slotsOf(c *inventoryContents) []InventorySlot {
+	slots := make([]InventorySlot, 0, len(c.slots))
+	for _, s := range c.slots {
+		slots = append(slots, *s)
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].Slot < slots[j].Slot })
+	return slots
+}
+
+func TestInventoryMove(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		slots       []InventorySlot
+		moves       [][3]int // from, to, quantity
+		wantErr     error
+		wantSlots   []InventorySlot
+		wantChanges domain.InventoryChanges
+	}{
+		{
+			name:        "whole stack to an empty slot",
+			slots:       []InventorySlot{stack(0, 10, 5)},
+			moves:       [][3]int{{0, 2, 0}},
+			wantSlots:   []InventorySlot{stack(2, 10, 5)},
+			wantChanges: domain.InventoryChanges{Saved: []domain.Inventory{{ID: "e0", EntityID: "p1", ItemID: "10", Slot: 2, Quantity: 5}}},
+		},
+		{
+			name:      "split to an empty slot",
+			slots:     []InventorySlot{stack(0, 10, 10)},
+			moves:     [][3]int{{0, 2, 4}},
+			wantSlots: []InventorySlot{stack(0, 10, 6), stack(2, 0, 4)},
+			wantChanges: domain.InventoryChanges{
+				Saved:    []domain.Inventory{entry("p1", 0, 10, 6), {EntityID: "p1", Slot: 2, Quantity: 4}},
+				NewItems: []domain.Item{{ItemListID: 1}},
+			},
+		},
+		{
+			name:      "merge as much as fits",
+			slots:     []InventorySlot{stack(0, 10, 8), stack(1, 11, 5)},
+			moves:     [][3]int{{0, 1, 0}},
+			wantSlots: []InventorySlot{stack(0, 10, 3), stack(1, 11, 10)},
+			wantChanges: domain.InventoryChanges{
+				Saved: []domain.Inventory{entry("p1", 0, 10, 3), entry("p1", 1, 11, 10)},
+			},
+		},
+		{
+			name:      "merge a whole stack frees its item",
+			slots:     []InventorySlot{stack(0, 10, 3), stack(1, 11, 5)},
+			moves:     [][3]int{{0, 1, 0}},
+			wantSlots: []InventorySlot{stack(1, 11, 8)},
+			wantChanges: domain.InventoryChanges{
+				Saved:      []domain.Inventory{entry("p1", 1, 11, 8)},
+				Removed:    []string{"e0"},
+				FreedItems: []int{10},
+			},
+		},
+		{
+			name:      "merge part of a stack",
+			slots:     []InventorySlot{stack(0, 10, 6), stack(1, 11, 2)},
+			moves:     [][3]int{{0, 1, 4}},
+			wantSlots: []InventorySlot{stack(0, 10, 2), stack(1, 11, 6)},
+			wantChanges: domain.InventoryChanges{
+				Saved: []domain.Inventory{entry("p1", 0, 10, 2), entry("p1", 1, 11, 6)},
+			},
+		},
+		{
+			name:      "split and merge back changes nothing",
+			slots:     []InventorySlot{stack(0, 10, 10)},
+			moves:     [][3]int{{0, 1, 4}, {1, 0, 0}},
+			wantSlots: []InventorySlot{stack(0, 10, 10)},
+		},
+		{
+			name:      "swap different items",
+			slots:     []InventorySlot{stack(0, 10, 3), sword(1, 20)},
+			moves:     [][3]int{{0, 1, 0}},
+			wantSlots: []InventorySlot{sword(0, 20), stack(1, 10, 3)},
+			wantChanges: domain.InventoryChanges{
+				Saved: []domain.Inventory{
+					{ID: "e1", EntityID: "p1", ItemID: "20", Slot: 0, Quantity: 1},
+					{ID: "e0", EntityID: "p1", ItemID: "10", Slot: 1, Quantity: 3},
+				},
+			},
+		},
+		{
+			name:      "unstackable items swap",
+			slots:     []InventorySlot{sword(0, 20), sword(1, 21)},
+			moves:     [][3]int{{0, 1, 0}},
+			wantSlots: []InventorySlot{sword(0, 21), sword(1, 20)},
+			wantChanges: domain.InventoryChanges{
+				Saved: []domain.Inventory{
+					{ID: "e1", EntityID: "p1", ItemID: "21", Slot: 0, Quantity: 1},
+					{ID: "e0", EntityID: "p1", ItemID: "20", Slot: 1, Quantity: 1},
+				},
+			},
+		},
+		{
+			name:    "onto a full stack",
+			slots:   []InventorySlot{stack(0, 10, 3), stack(1, 11, testStackSize)},
+			moves:   [][3]int{{0, 1, 0}},
+			wantErr: util.ErrInvalidInventoryMove,
+		},
+		{
+			name:    "part of a stack onto another item",
+			slots:   []InventorySlot{stack(0, 10, 3), sword(1, 20)},
+			moves:   [][3]int{{0, 1, 2}},
+			wantErr: util.ErrInvalidInventoryMove,
+		},
+		{
+			name:    "more than the stack holds",
+			slots:   []InventorySlot{stack(0, 10, 3)},
+			moves:   [][3]int{{0, 1, 4}},
+			wantErr: util.ErrInvalidQuantity,
+		},
+		{
+			name:    "negative quantity",
+			slots:   []InventorySlot{stack(0, 10, 3)},
+			moves:   [][3]int{{0, 1, -1}},
+			wantErr: util.ErrInvalidQuantity,
+		},
+		{
+			name:    "from an empty slot",
+			slots:   []InventorySlot{stack(0, 10, 3)},
+			moves:   [][3]int{{2, 1, 0}},
+			wantErr: util.ErrInventoryEntryNotFound,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newContents("p1", tc.slots...)
+			var err error
+			for _, m := range tc.moves {
+				if err = c.move(m[0], m[1], m[2], testStackSize); err != nil {
+					break
+				}
+			}
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tc.wantErr)
+			}
+			if tc.wantErr != nil {
+				if got := slotsOf(c); !reflect.DeepEqual(got, tc.slots) {
+					t.Errorf("failed move changed the slots to %+v", got)
+				}
+				return
+			}
+			if got := slotsOf(c); !reflect.DeepEqual(got, tc.wantSlots) {
+				t.Errorf("slots = %+v, want %+v", got, tc.wantSlots)
+			}
+			if got := inventoryChanges(c); !reflect.DeepEqual(got, tc.wantChanges) {
+				t.Errorf("changes:\n got: %+v\nwant: %+v", got, tc.wantChanges)
+			}
+		})
+	}
+}
+
+func TestInventoryAdd(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		slots     []InventorySlot
+		item      domain.Item
+		stackable bool
+		quantity  int
+		wantErr   error
+		wantFirst int
+		wantSlots []InventorySlot
+	}{
+		{
+			name:      "into an empty inventory",
+			item:      domain.Item{ItemListID: 1},
+			stackable: true,
+			quantity:  4,
+			wantSlots: []InventorySlot{stack(0, 0, 4)},
+		},
+		{
+			name:      "tops up stacks before opening a slot",
+			slots:     []InventorySlot{stack(0, 10, 8), stack(2, 11, 9)},
+			item:      domain.Item{ItemListID: 1},
+			stackable: true,
+			quantity:  5,
+			wantSlots: []InventorySlot{stack(0, 10, 10), stack(1, 0, 2), stack(2, 11, 10)},
+		},
+		{
+			name:      "first slot is the first topped up stack",
+			slots:     []InventorySlot{sword(0, 20), stack(1, 10, 9)},
+			item:      domain.Item{ItemListID: 1},
+			stackable: true,
+			quantity:  1,
+			wantFirst: 1,
+			wantSlots: []InventorySlot{sword(0, 20), stack(1, 10, 10)},
+		},
+		{
+			name:      "more than a stack spreads over slots",
+			item:      domain.Item{ID: 30, ItemListID: 1},
+			stackable: true,
+			quantity:  25,
+			wantSlots: []InventorySlot{stack(0, 30, 10), stack(1, 30, 10), stack(2, 30, 5)},
+		},
+		{
+			name:      "unstackable items take a slot each",
+			slots:     []InventorySlot{sword(1, 20)},
+			item:      domain.Item{ItemListID: 2},
+			quantity:  2,
+			wantSlots: []InventorySlot{sword(0, 0), sword(1, 20), sword(2, 0)},
+		},
+		{
+			name:      "unstackable items do not stack",
+			slots:     []InventorySlot{sword(0, 20)},
+			item:      domain.Item{ItemListID: 2},
+			quantity:  1,
+			wantFirst: 1,
+			wantSlots: []InventorySlot{sword(0, 20), sword(1, 0)},
+		},
+		{
+			name:      "stacks of other items are left alone",
+			slots:     []InventorySlot{{Slot: 0, ItemID: 40, ItemListID: 3, Quantity: 1, Stackable: true}},
+			item:      domain.Item{ItemListID: 1},
+			stackable: true,
+			quantity:  1,
+			wantFirst: 1,
+			wantSlots: []InventorySlot{{Slot: 0, ItemID: 40, ItemListID: 3, Quantity: 1, Stackable: true}, stack(1, 0, 1)},
+		},
+		{
+			name:      "full inventory",
+			slots:     []InventorySlot{sword(0, 20), sword(1, 21), stack(2, 10, 9)},
+			item:      domain.Item{ItemListID: 1},
+			stackable: true,
+			quantity:  2,
+			wantErr:   util.ErrInventoryFull,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newContents("p1", tc.slots...)
+			first, err := c.add(tc.item, tc.stackable, tc.quantity, testCapacity, testStackSize)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if first != tc.wantFirst {
+				t.Errorf("first slot = %d, want %d", first, tc.wantFirst)
+			}
+			if got := slotsOf(c); !reflect.DeepEqual(got, tc.wantSlots) {
+				t.Errorf("slots = %+v, want %+v", got, tc.wantSlots)
+			}
+		})
+	}
+}
+
+func TestInventoryRemove(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		slot        int
+		quantity    int
+		wantErr     error
+		wantSlots   []InventorySlot
+		wantChanges domain.InventoryChanges
+	}{
+		{
+			name:        "part of a stack",
+			quantity:    2,
+			wantSlots:   []InventorySlot{stack(0, 10, 3), sword(1, 20)},
+			wantChanges: domain.InventoryChanges{Saved: []domain.Inventory{entry("p1", 0, 10, 3)}},
+		},
+		{
+			name:        "the whole stack",
+			quantity:    5,
+			wantSlots:   []InventorySlot{sword(1, 20)},
+			wantChanges: domain.InventoryChanges{Removed: []string{"e0"}, FreedItems: []int{10}},
+		},
+		{
+			name:        "the whole slot",
+			slot:        1,
+			wantSlots:   []InventorySlot{stack(0, 10, 5)},
+			wantChanges: domain.InventoryChanges{Removed: []string{"e1"}, FreedItems: []int{20}},
+		},
+		{name: "more than the stack holds", quantity: 6, wantErr: util.ErrInvalidQuantity},
+		{name: "empty slot", slot: 2, wantErr: util.ErrInventoryEntryNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newContents("p1", stack(0, 10, 5), sword(1, 20))
+			err := c.remove(tc.slot, tc.quantity)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := slotsOf(c); !reflect.DeepEqual(got, tc.wantSlots) {
+				t.Errorf("slots = %+v, want %+v", got, tc.wantSlots)
+			}
+			if got := inventoryChanges(c); !reflect.DeepEqual(got, tc.wantChanges) {
+				t.Errorf("changes:\n got: %+v\nwant: %+v", got, tc.wantChanges)
+			}
+		})
+	}
+}
+
+func TestInventoryTransferChanges(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		src, dst    []InventorySlot
+		from        int
+		quantity    int
+		to          int
+		wantChanges domain.InventoryChanges
+	}{
+		{
+			name: "whole slot keeps its item",
+			src:  []InventorySlot{sword(0, 20)},
+			to:   1,
+			wantChanges: domain.InventoryChanges{
+				Saved:   []domain.Inventory{{EntityID: "p2", ItemID: "20", Slot: 1, Quantity: 1}},
+				Removed: []string{"e0"},
+			},
+		},
+		{
+			name:     "part of a stack gets a new item",
+			src:      []InventorySlot{stack(0, 10, 5)},
+			quantity: 2,
+			to:       0,
+			wantChanges: domain.InventoryChanges{
+				Saved:    []domain.Inventory{entry("p1", 0, 10, 3), {EntityID: "p2", Slot: 0, Quantity: 2}},
+				NewItems: []domain.Item{{ItemListID: 1}},
+			},
+		},
+		{
+			name: "merged stack frees its item",
+			src:  []InventorySlot{stack(0, 10, 5)},
+			dst:  []InventorySlot{stack(0, 11, 2)},
+			to:   0,
+			wantChanges: domain.InventoryChanges{
+				Saved:      []domain.Inventory{entry("p2", 0, 11, 7)},
+				Removed:    []string{"e0"},
+				FreedItems: []int{10},
+			},
+		},
+		{
+			name: "stack spread over slots gets an item per slot",
+			src:  []InventorySlot{stack(0, 10, 10)},
+			dst:  []InventorySlot{stack(1, 11, 6)},
+			to:   -1,
+			wantChanges: domain.InventoryChanges{
+				Saved: []domain.Inventory{
+					{EntityID: "p2", ItemID: "10", Slot: 0, Quantity: 6},
+					entry("p2", 1, 11, 10),
+				},
+				Removed: []string{"e0"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			src, dst := newContents("p1", tc.src...), newContents("p2", tc.dst...)
+			// The steps of Transfer
+			moved := *src.slots[tc.from]
+			quantity := tc.quantity
+			if quantity == 0 {
+				quantity = moved.Quantity
+			}
+			if err := src.remove(tc.from, quantity); err != nil {
+				t.Fatalf("remove: %v", err)
+			}
+			if err := dst.put(moved, quantity, tc.to, testCapacity, testStackSize); err != nil {
+				t.Fatalf("put: %v", err)
+			}
+			if got := inventoryChanges(src, dst); !reflect.DeepEqual(got, tc.wantChanges) {
+				t.Errorf("changes:\n got: %+v\nwant: %+v", got, tc.wantChanges)
+			}
+		})
+	}
+}
+
+func TestInventoryChangesGiveEverySlotItsOwnItem(t *testing.T) {
+	// Slots filled from one item, as by a transfer spread over several slots
+	c := newContents("p1")
+	if _, err := c.add(domain.Item{ID: 10, ItemListID: 1}, true, 25, testCapacity, testStackSize); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := inventoryChanges(c)
+	want := domain.InventoryChanges{
+		Saved: []domain.Inventory{
+			{EntityID: "p1", ItemID: "10", Slot: 0, Quantity: 10},
+			{EntityID: "p1", Slot: 1, Quantity: 10},
+			{EntityID: "p1", Slot: 2, Quantity: 5},
+		},
+		NewItems: []domain.Item{{ItemListID: 1}, {ItemListID: 1}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("changes:\n got: %+v\nwant: %+v", changes, want)
+	}
+	saved, err := domain.InventoryChanges{Saved: changes.Saved, NewItems: []domain.Item{{ID: 31}, {ID: 32}}}.SavedWithNewItems()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, itemID := range []string{"10", "31", "32"} {
+		if saved[i].ItemID != itemID {
+			t.Errorf("slot %d: item %q, want %q", saved[i].Slot, saved[i].ItemID, itemID)
+		}
+	}
+
+	assignNewItems([]domain.Item{{ID: 31}, {ID: 32}}, c)
+	if got := []int{c.slots[0].ItemID, c.slots[1].ItemID, c.slots[2].ItemID}; !reflect.DeepEqual(got, []int{10, 31, 32}) {
+		t.Errorf("items after saving = %v, want [10 31 32]", got)
+	}
+}
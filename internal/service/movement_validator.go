@@ -0,0 +1,182 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// speedTolerance is the fraction by which a move may exceed the speed limit before
+	// it is rejected, absorbing jitter in when inputs reach the server.
+	speedTolerance = 0.25
+	// maxMoveInterval caps the elapsed time credited to a single move, so that standing
+	// still for a while does not allow a long jump afterwards.
+	maxMoveInterval = time.Second
+	// minMoveInterval is the smallest elapsed time credited to a move, so that two inputs
+	// arriving in quick succession are not rejected for a tiny step.
+	minMoveInterval = 50 * time.Millisecond
+	// suspicionHalfLife is how quickly a player's suspicion score decays.
+	suspicionHalfLife = 30 * time.Second
+	// maxViolationPenalty caps the suspicion added by a single rejected move.
+	maxViolationPenalty = 5.0
+)
+
+// Position is a point in world space.
+type Position struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// PlayerSuspicion is the anti-cheat record of a player, as exposed to admins.
+type PlayerSuspicion struct {
+	PlayerID      string     `json:"player_id"`
+	Username      string     `json:"username"`
+	Score         float64    `json:"score"`
+	RejectedMoves int        `json:"rejected_moves"`
+	Kicks         int        `json:"kicks"`
+	LastViolation *time.Time `json:"last_violation,omitempty"`
+}
+
+// movementState is the last authoritative position of a player and its suspicion record.
+type movementState struct {
+	pos       Position
+	movedAt   time.Time
+	suspicion PlayerSuspicion
+	scoredAt  time.Time // Момент последнего пересчета Score
+}
+
+// MoveResult is the outcome of validating a move.
+type MoveResult struct {
+	Accepted bool
+	Position Position // Authoritative position after the move
+	Score    float64  // Suspicion score after the move
+	Kick     bool     // Score reached the kick threshold
+}
+
+// MovementValidator checks client moves against the previous authoritative position,
+// the time elapsed since it and the maximum player speed, and keeps a decaying
+// suspicion score for players whose moves are rejected.
+type MovementValidator struct {
+	maxSpeed      float64 // Максимальная скорость игрока (единиц в секунду)
+	kickThreshold float64 // Порог подозрительности для отключения, 0 — не отключать
+
+	mu      sync.Mutex
+	players map[string]*movementState
+}
+
+// NewMovementValidator creates a new MovementValidator.
+func NewMovementValidator(maxSpeed, kickThreshold float64) *MovementValidator {
+	return &MovementValidator{
+		maxSpeed:      maxSpeed,
+		kickThreshold: kickThreshold,
+		players:       make(map[string]*movementState),
+	}
+}
+
+// Known reports whether the validator has an authoritative position for the player.
+func (v *MovementValidator) Known(playerID string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	_, ok := v.players[playerID]
+	return ok
+}
+
+// Place sets a player's authoritative position without validation, e.g. from storage
+// or after a server-side correction.
+func (v *MovementValidator) Place(playerID, username string, pos Position, now time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	state := v.stateLocked(playerID, username, now)
+	state.pos = pos
+	state.movedAt = now
+}
+
+// Validate checks a move of a player to target. Players without a known position
+// are accepted wherever they appear.
+func (v *MovementValidator) Validate(playerID, username string, target Position, now time.Time) MoveResult {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	state, known := v.players[playerID]
+	if !known {
+		state = v.stateLocked(playerID, username, now)
+		state.pos, state.movedAt = target, now
+		return MoveResult{Accepted: true, Position: target}
+	}
+	state.suspicion.Username = username
+	v.decayLocked(state, now)
+
+	elapsed := now.Sub(state.movedAt)
+	if elapsed > maxMoveInterval {
+		elapsed = maxMoveInterval
+	} else if elapsed < minMoveInterval {
+		elapsed = minMoveInterval
+	}
+	allowed := v.maxSpeed * elapsed.Seconds() * (1 + speedTolerance)
+	distance := math.Sqrt(sq(target.X-state.pos.X) + sq(target.Y-state.pos.Y) + sq(target.Z-state.pos.Z))
+
+	if distance <= allowed {
+		state.pos, state.movedAt = target, now
+		return MoveResult{Accepted: true, Position: target, Score: state.suspicion.Score}
+	}
+
+	state.suspicion.Score += math.Min(distance/allowed, maxViolationPenalty)
+	state.suspicion.RejectedMoves++
+	violation := now
+	state.suspicion.LastViolation = &violation
+
+	result := MoveResult{Position: state.pos, Score: state.suspicion.Score}
+	if v.kickThreshold > 0 && state.suspicion.Score >= v.kickThreshold {
+		result.Kick = true
+		state.suspicion.Score = 0
+		state.suspicion.Kicks++
+	}
+	return result
+}
+
+// Suspicions returns the anti-cheat records of all players that had a move rejected,
+// most suspicious first.
+func (v *MovementValidator) Suspicions(now time.Time) []PlayerSuspicion {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	result := make([]PlayerSuspicion, 0)
+	for _, state := range v.players {
+		v.decayLocked(state, now)
+		if state.suspicion.RejectedMoves > 0 {
+			result = append(result, state.suspicion)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return result[i].PlayerID < result[j].PlayerID
+	})
+	return result
+}
+
+// stateLocked returns the state of a player, creating it if needed. v.mu must be held.
+func (v *MovementValidator) stateLocked(playerID, username string, now time.Time) *movementState {
+	state, ok := v.players[playerID]
+	if !ok {
+		state = &movementState{suspicion: PlayerSuspicion{PlayerID: playerID}, scoredAt: now}
+		v.players[playerID] = state
+	}
+	state.suspicion.Username = username
+	return state
+}
+
+// decayLocked applies the exponential decay of the suspicion score up to now. v.mu must be held.
+func (v *MovementValidator) decayLocked(state *movementState, now time.Time) {
+	if elapsed := now.Sub(state.scoredAt); elapsed > 0 {
+		state.suspicion.Score *= math.Pow(0.5, elapsed.Seconds()/suspicionHalfLife.Seconds())
+		state.scoredAt = now
+	}
+}
+
+func sq(v float64) float64 { return v * v }
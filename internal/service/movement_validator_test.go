@@ -0,0 +1,207 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/repository/memory"
+	"anarchy-core/internal/util"
+)
+
+func TestMovementValidatorValidate(t *testing.T) {
+	start := time.Unix(1792210000, 0)
+	origin := Position{X: 10, Y: 5, Z: -10}
+	// With a speed of 10 a move of 1s may cover 10 * 1.25 = 12.5 units
+	for _, tc := range []struct {
+		name      string
+		placed    bool
+		elapsed   time.Duration
+		target    Position
+		accepted  bool
+		wantScore float64
+	}{
+		{"unknown player appears anywhere", false, 0, Position{X: 1e6}, true, 0},
+		{"standing still", true, time.Second, origin, true, 0},
+		{"within the speed limit", true, time.Second, Position{X: 20, Y: 5, Z: -10}, true, 0},
+		{"within the tolerance", true, time.Second, Position{X: 22.5, Y: 5, Z: -10}, true, 0},
+		{"beyond the tolerance", true, time.Second, Position{X: 25, Y: 5, Z: -10}, false, 15.0 / 12.5},
+		{"diagonal distance counts", true, time.Second, Position{X: 19, Y: 5, Z: -1}, false, math.Sqrt(162) / 12.5},
+		{"climbing counts", true, time.Second, Position{X: 10, Y: 20, Z: -10}, false, 15.0 / 12.5},
+		{"falling counts", true, time.Second, Position{X: 10, Y: -10, Z: -10}, false, 15.0 / 12.5},
+		{"long wait does not allow a long jump", true, time.Minute, Position{X: 35, Y: 5, Z: -10}, false, 25.0 / 12.5},
+		{"quick inputs get the minimum interval", true, time.Millisecond, Position{X: 10.6, Y: 5, Z: -10}, true, 0},
+		{"quick inputs cannot jump", true, time.Millisecond, Position{X: 12, Y: 5, Z: -10}, false, 2 / 0.625},
+		{"teleport penalty is capped", true, time.Second, Position{X: 1e6}, false, maxViolationPenalty},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			v := NewMovementValidator(10, 0)
+			if tc.placed {
+				v.Place("p1", "alice", origin, start)
+			}
+			result := v.Validate("p1", "alice", tc.target, start.Add(tc.elapsed))
+			if result.Accepted != tc.accepted {
+				t.Fatalf("accepted = %v, want %v", result.Accepted, tc.accepted)
+			}
+			wantPos := tc.target
+			if !tc.accepted {
+				wantPos = origin
+			}
+			if result.Position != wantPos {
+				t.Errorf("position = %+v, want %+v", result.Position, wantPos)
+			}
+			if math.Abs(result.Score-tc.wantScore) > 1e-9 {
+				t.Errorf("score = %v, want %v", result.Score, tc.wantScore)
+			}
+			if result.Kick {
+				t.Error("kicked with kicking disabled")
+			}
+		})
+	}
+}
+
+func TestMovementValidatorRejectedMoveKeepsPosition(t *testing.T) {
+	start := time.Unix(1792210000, 0)
+	v := NewMovementValidator(10, 0)
+	v.Place("p1", "alice", Position{}, start)
+
+	if v.Validate("p1", "alice", Position{X: 100}, start.Add(time.Second)).Accepted {
+		t.Fatal("teleport accepted")
+	}
+	// The next move is measured from the authoritative position, not the rejected target
+	if v.Validate("p1", "alice", Position{X: 105}, start.Add(2*time.Second)).Accepted {
+		t.Error("move continuing from the rejected target accepted")
+	}
+	if !v.Validate("p1", "alice", Position{X: 10}, start.Add(3*time.Second)).Accepted {
+		t.Error("move from the authoritative position rejected")
+	}
+}
+
+func TestMovementValidatorSuspicion(t *testing.T) {
+	start := time.Unix(1792210000, 0)
+	teleport := func(v *MovementValidator, at time.Time) MoveResult {
+		return v.Validate("p1", "alice", Position{X: 1e6}, at)
+	}
+
+	t.Run("decays by half every half-life", func(t *testing.T) {
+		v := NewMovementValidator(10, 0)
+		v.Place("p1", "alice", Position{}, start)
+		teleport(v, start.Add(time.Second))
+
+		suspicions := v.Suspicions(start.Add(time.Second + suspicionHalfLife))
+		if len(suspicions) != 1 || math.Abs(suspicions[0].Score-maxViolationPenalty/2) > 1e-9 {
+			t.Fatalf("suspicions = %+v, want a score of %v", suspicions, maxViolationPenalty/2)
+		}
+		if suspicions[0].RejectedMoves != 1 || suspicions[0].LastViolation == nil {
+			t.Errorf("suspicion = %+v, want one recorded violation", suspicions[0])
+		}
+	})
+
+	t.Run("kick at the threshold resets the score", func(t *testing.T) {
+		v := NewMovementValidator(10, 3*maxViolationPenalty)
+		v.Place("p1", "alice", Position{}, start)
+		for i := 1; i <= 2; i++ {
+			if result := teleport(v, start); result.Kick {
+				t.Fatalf("kicked after %d violations", i)
+			}
+		}
+		result := teleport(v, start)
+		if !result.Kick || result.Score != 3*maxViolationPenalty {
+			t.Fatalf("result = %+v, want a kick at score %v", result, 3*maxViolationPenalty)
+		}
+		suspicions := v.Suspicions(start)
+		if len(suspicions) != 1 || suspicions[0].Score != 0 || suspicions[0].Kicks != 1 || suspicions[0].RejectedMoves != 3 {
+			t.Errorf("suspicions = %+v, want score 0 after one kick and three rejected moves", suspicions)
+		}
+	})
+
+	t.Run("decay keeps a slow cheater below the threshold", func(t *testing.T) {
+		v := NewMovementValidator(10, 2*maxViolationPenalty)
+		v.Place("p1", "alice", Position{}, start)
+		for i := 0; i < 10; i++ {
+			if teleport(v, start.Add(time.Duration(i)*suspicionHalfLife)).Kick {
+				t.Fatalf("kicked at violation %d", i+1)
+			}
+		}
+	})
+}
+
+func TestMovementValidatorSuspicions(t *testing.T) {
+	start := time.Unix(1792210000, 0)
+	v := NewMovementValidator(10, 0)
+	for _, p := range []struct {
+		id, username string
+		target       Position
+	}{
+		{"honest", "h", Position{X: 1}},
+		{"b", "bob", Position{X: 100}},
+		{"a", "alice", Position{X: 100}},
+		{"c", "carol", Position{X: 20}},
+	} {
+		v.Place(p.id, p.username, Position{}, start)
+		v.Validate(p.id, p.username, p.target, start.Add(time.Second))
+	}
+
+	suspicions := v.Suspicions(start.Add(time.Second))
+	var got []string
+	for _, s := range suspicions {
+		got = append(got, s.PlayerID)
+	}
+	// Most suspicious first, ties by player ID, and players without violations left out
+	want := []string{"a", "b", "c"}
+	if len(got) != len(want) {
+		t.Fatalf("players = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("players = %v, want %v", got, want)
+		}
+	}
+	if suspicions[0].Username != "alice" {
+		t.Errorf("username = %q, want %q", suspicions[0].Username, "alice")
+	}
+}
+
+func TestGameLoopPlacePlayer(t *testing.T) {
+	start := time.Unix(1792210000, 0)
+	store := memory.NewStore()
+	locations := memory.NewPlayerMovementRepositoryMemory(store)
+	if err := locations.SavePlayerLocation(&domain.Location{PlayerID: "stored", X: 100, Y: 0, Z: 100}); err != nil {
+		t.Fatal(err)
+	}
+	logger := util.NewLogger()
+	spawn := [2]float64{-50, 50}
+	s := &GameLoopService{
+		playerService:  NewPlayerService(locations, logger),
+		terrainService: NewTerrainService(memory.NewWorldRepositoryMemory(store), 1, 16, 1, &spawn, logger),
+		validator:      NewMovementValidator(10, 0),
+	}
+
+	// The first move of a session is measured from where the player is known to be
+	for _, tc := range []struct {
+		name     string
+		playerID string
+		target   Position
+		accepted bool
+	}{
+		{"stored player moving on", "stored", Position{X: 105, Z: 100}, true},
+		{"stored player teleporting", "stored", Position{X: -50, Z: 50}, false},
+		{"new player leaving the spawn point", "new", Position{X: -45, Z: 50}, true},
+		{"new player teleporting", "new", Position{X: 100, Z: 100}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s.validator = NewMovementValidator(10, 0)
+			s.PlacePlayer(tc.playerID, tc.playerID, start)
+			result := s.validator.Validate(tc.playerID, tc.playerID, tc.target, start.Add(time.Second))
+			if result.Accepted != tc.accepted {
+				t.Errorf("accepted = %v, want %v", result.Accepted, tc.accepted)
+			}
+		})
+	}
+
+	loc, err := s.playerService.GetPlayerLocation("new")
+	if err != nil || loc.X != -50 || loc.Z != 50 {
+		t.Errorf("location of the new player = %+v, %v; want the spawn point", loc, err)
+	}
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// Item actions a player can queue.
+const (
+	ItemActionPickup = "pickup"
+	ItemActionDrop   = "drop"
+)
+
+// Reasons an item action fails.
+const (
+	ItemActionNoTarget      = "no_target"      // Сущность не найдена или уже подобрана
+	ItemActionOutOfRange    = "out_of_range"   // Сущность дальше дальности подбора
+	ItemActionNotPickable   = "not_pickable"   // Сущность нельзя подобрать
+	ItemActionNotDroppable  = "not_droppable"  // Для предмета нет сущности, которой он станет в мире
+	ItemActionInventoryFull = "inventory_full" // Нет места в инвентаре
+	ItemActionEmptySlot     = "empty_slot"     // Слот инвентаря пуст
+	ItemActionDead          = "dead"           // Игрок мёртв
+	ItemActionNotContainer  = "not_container"  // Сущность нельзя открыть
+	ItemActionInUse         = "in_use"         // Контейнер открыт другим игроком
+	ItemActionNotOpen       = "not_open"       // Игрок не открыл этот контейнер
+	ItemActionInvalidMove   = "invalid_move"   // Предметы не помещаются в выбранный слот
+	ItemActionFailed        = "failed"         // Внутренняя ошибка
+)
+
+// pickupTemplatesRefresh is how often the item-to-entity mapping used by drops is rebuilt.
+const pickupTemplatesRefresh = time.Minute
+
+// errNotDroppable means that no pickable entity template corresponds to an item.
+var errNotDroppable = errors.New("item has no pickable entity template")
+
+// ItemAction is a pickup or drop requested by a player and resolved on the next tick.
+type ItemAction struct {
+	PlayerID string
+	Action   string // ItemActionPickup или ItemActionDrop
+	EntityID int    // Подбираемая сущность
+	Slot     int    // Слот выбрасываемого предмета
+}
+
+// ItemPickedUpMessage notifies clients near an entity that a player picked it up.
+type ItemPickedUpMessage struct {
+	Type       string `json:"type"` // "item_picked_up"
+	PlayerID   string `json:"player_id"`
+	EntityID   int    `json:"entity_id"`
+	ItemListID int    `json:"item_list_id"`
+}
+
+// ItemDroppedMessage notifies clients near a player that it dropped an item.
+type ItemDroppedMessage struct {
+	Type       string  `json:"type"` // "item_dropped"
+	PlayerID   string  `json:"player_id"`
+	EntityID   int     `json:"entity_id"`
+	ItemListID int     `json:"item_list_id"`
+	X          float64 `json:"x"`
+	Y          float64 `json:"y"`
+	Z          float64 `json:"z"`
+}
+
+// ItemActionFailedMessage tells a player why an item action was not carried out.
+type ItemActionFailedMessage struct {
+	Type     string `json:"type"` // "item_action_failed"
+	Action   string `json:"action"`
+	EntityID int    `json:"entity_id,omitempty"`
+	Slot     int    `json:"slot"`
+	Reason   string `json:"reason"`
+}
+
+// PickupService lets players pick up world entities whose template has IsPickUp
+// and drop items back into the world. An entity template and an item definition
+// correspond when their objects are of the same ObjectList. Actions are resolved
+// on the game loop tick, one at a time, so a contested entity goes to one player only.
+type PickupService struct {
+	inventoryService *InventoryService
+	aiSystem         *AISystem
+	spawner          *SpawnerService
+	combatService    *CombatService
+	terrainService   *TerrainService
+	websocketService *WebSocketService
+	itemListRepo     domain.ItemListRepository
+	entityListRepo   domain.EntityListRepository
+	pickupRange      float64
+	logger           *util.Logger
+
+	mu      sync.Mutex
+	actions map[string]ItemAction // Последнее действие каждого игрока с прошлого тика
+
+	templates        map[int]domain.EntityList // Подбираемые шаблоны по ID описания предмета
+	templatesBuiltAt time.Time
+}
+
+// NewPickupService creates a new PickupService.
+func NewPickupService(
+	inventoryService *InventoryService,
+	aiSystem *AISystem,
+	spawner *SpawnerService,
+	combatService *CombatService,
+	terrainService *TerrainService,
+	websocketService *WebSocketService,
+	itemListRepo domain.ItemListRepository,
+	entityListRepo domain.EntityListRepository,
+	pickupRange float64,
+	logger *util.Logger,
+) *PickupService {
+	return &PickupService{
+		inventoryService: inventoryService,
+		aiSystem:         aiSystem,
+		spawner:          spawner,
+		combatService:    combatService,
+		terrainService:   terrainService,
+		websocketService: websocketService,
+		itemListRepo:     itemListRepo,
+		entityListRepo:   entityListRepo,
+		pickupRange:      pickupRange,
+		logger:           logger,
+		actions:          make(map[string]ItemAction),
+	}
+}
+
+// QueueAction queues a player's pickup or drop for the next tick.
+// Only the most recent action per player is kept between ticks.
+func (s *PickupService) QueueAction(action ItemAction) {
+	s.mu.Lock()
+	s.actions[action.PlayerID] = action
+	s.mu.Unlock()
+}
+
+// drainActions takes all actions queued since the previous tick.
+func (s *PickupService) drainActions() []ItemAction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.actions) == 0 {
+		return nil
+	}
+	actions := make([]ItemAction, 0, len(s.actions))
+	for _, action := range s.actions {
+		actions = append(actions, action)
+	}
+	s.actions = make(map[string]ItemAction, len(actions))
+
+	// Resolve actions in a stable order so ticks are reproducible
+	sort.Slice(actions, func(i, j int) bool { return actions[i].PlayerID < actions[j].PlayerID })
+	return actions
+}
+
+// Resolve carries out the actions queued since the previous tick given the positions
+// of the living players. Dropped entities are handed to the AI; the IDs of the
+// entities picked up are returned so they can be removed from the world.
+func (s *PickupService) Resolve(players map[string]Position, now time.Time) []int {
+	var removed []int
+	for _, action := range s.drainActions() {
+		pos, alive := players[action.PlayerID]
+		if !alive || s.combatService.IsDead(action.PlayerID) {
+			s.fail(action, ItemActionDead)
+			continue
+		}
+		switch action.Action {
+		case ItemActionPickup:
+			if s.pickUp(action, pos, now) {
+				removed = append(removed, action.EntityID)
+			}
+		case ItemActionDrop:
+			s.drop(action, pos, now)
+		}
+	}
+	return removed
+}
+
+// pickUp moves an entity into the player's inventory. It reports whether the entity left the world.
+func (s *PickupService) pickUp(action ItemAction, pos Position, now time.Time) bool {
+	entity, ok := s.aiSystem.Entity(action.EntityID)
+	if !ok {
+		s.fail(action, ItemActionNoTarget)
+		return false
+	}
+	template, _ := s.aiSystem.Template(action.EntityID)
+	if !template.IsPickUp {
+		s.fail(action, ItemActionNotPickable)
+		return false
+	}
+	if distance2D(pos.X, pos.Z, entity.X, entity.Z) > s.pickupRange {
+		s.fail(action, ItemActionOutOfRange)
+		return false
+	}
+	itemList, err := s.itemListRepo.GetItemListByObjectListID(template.ObjectListID)
+	if err != nil {
+		if !errors.Is(err, util.ErrItemListNotFound) {
+			s.logger.Error("Failed to find item definition of entity template %d: %v", template.ID, err)
+		}
+		s.fail(action, ItemActionNotPickable)
+		return false
+	}
+
+	item, err := s.inventoryService.PickUp(action.PlayerID, action.EntityID, *itemList)
+	switch {
+	case errors.Is(err, util.ErrInventoryFull):
+		s.fail(action, ItemActionInventoryFull)
+		return false
+	case errors.Is(err, util.ErrEntityNotFound):
+		// Someone else got it first; the entity is gone from storage, so drop it from the world too
+		s.fail(action, ItemActionNoTarget)
+	case err != nil:
+		s.logger.Error("Failed to pick up entity %d for player %s: %v", action.EntityID, action.PlayerID, err)
+		s.fail(action, ItemActionFailed)
+		return false
+	default:
+		s.websocketService.SendNear(entity.X, entity.Z, ItemPickedUpMessage{
+			Type:       "item_picked_up",
+			PlayerID:   action.PlayerID,
+			EntityID:   action.EntityID,
+			ItemListID: item.ItemListID,
+		})
+	}
+
+	s.aiSystem.Remove(action.EntityID)
+	s.spawner.EntityRemoved(action.EntityID, entity.EntityListID, now)
+	return true
+}
+
+// drop turns one item of a slot of the player's inventory into an entity at the player's feet.
+func (s *PickupService) drop(action ItemAction, pos Position, now time.Time) {
+	var template domain.EntityList
+	var itemListID int
+	entity, err := s.inventoryService.DropItem(action.PlayerID, action.Slot, func(slot InventorySlot) (*domain.Entity, int, error) {
+		var ok bool
+		if template, ok = s.pickupTemplate(slot.ItemListID, now); !ok {
+			return nil, 0, errNotDroppable
+		}
+		itemListID = slot.ItemListID
+		return &domain.Entity{
+			EntityListID: template.ID,
+			Health:       template.MaxHealth,
+			X:            pos.X,
+			Y:            s.terrainService.ClampToGround(pos.X, pos.Y, pos.Z),
+			Z:            pos.Z,
+		}, template.ObjectListID, nil
+	})
+	switch {
+	case errors.Is(err, util.ErrInventoryEntryNotFound):
+		s.fail(action, ItemActionEmptySlot)
+		return
+	case errors.Is(err, errNotDroppable):
+		s.fail(action, ItemActionNotDroppable)
+		return
+	case err != nil:
+		s.logger.Error("Failed to drop slot %d of player %s: %v", action.Slot, action.PlayerID, err)
+		s.fail(action, ItemActionFailed)
+		return
+	}
+
+	s.aiSystem.Add(*entity, template)
+	s.websocketService.SendNear(entity.X, entity.Z, ItemDroppedMessage{
+		Type:       "item_dropped",
+		PlayerID:   action.PlayerID,
+		EntityID:   entity.ID,
+		ItemListID: itemListID,
+		X:          entity.X,
+		Y:          entity.Y,
+		Z:          entity.Z,
+	})
+}
+
+// ReloadTemplates makes the next drop rebuild the item-to-entity mapping.
+func (s *PickupService) ReloadTemplates() {
+	s.templates = nil
+}
+
+// pickupTemplate returns the pickable entity template an item of itemListID becomes when dropped.
+func (s *PickupService) pickupTemplate(itemListID int, now time.Time) (domain.EntityList, bool) {
+	if s.templates == nil || now.Sub(s.templatesBuiltAt) >= pickupTemplatesRefresh {
+		s.buildTemplates(now)
+	}
+	template, ok := s.templates[itemListID]
+	return template, ok
+}
+
+// buildTemplates maps item definitions to the pickable entity templates of the same ObjectList.
+func (s *PickupService) buildTemplates(now time.Time) {
+	templates, err := s.entityListRepo.GetAllEntityLists()
+	if err != nil {
+		s.logger.Error("Failed to load entity templates for drops: %v", err)
+		return
+	}
+	s.templates = make(map[int]domain.EntityList)
+	s.templatesBuiltAt = now
+	for _, template := range templates {
+		if !template.IsPickUp || template.ObjectListID == 0 {
+			continue
+		}
+		itemList, err := s.itemListRepo.GetItemListByObjectListID(template.ObjectListID)
+		if err != nil {
+			continue
+		}
+		// Templates are ordered by ID, so the first one of an item wins
+		if _, exists := s.templates[itemList.ID]; !exists {
+			s.templates[itemList.ID] = template
+		}
+	}
+}
+
+// fail tells a player that its action was rejected.
+func (s *PickupService) fail(action ItemAction, reason string) {
+	msg := ItemActionFailedMessage{Type: "item_action_failed", Action: action.Action, Slot: action.Slot, Reason: reason}
+	if action.Action == ItemActionPickup {
+		msg.EntityID = action.EntityID
+	}
+	s.websocketService.SendToPlayer(action.PlayerID, msg)
+}
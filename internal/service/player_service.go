@@ -4,12 +4,30 @@ import (
 	"anarchy-core/internal/domain"
 	"anarchy-core/internal/util"
 	"errors"
+	"sort"
+	"sync"
+	"time"
 )
 
+// playerPersistInterval is how often moved players are written back to storage.
+const playerPersistInterval = 10 * time.Second
+
+// trackedLocation is a player location held in memory.
+type trackedLocation struct {
+	location domain.Location
+	dirty    bool // Позиция не сохранена в хранилище
+}
+
 // PlayerService handles player-related business logic, especially movement.
+// Locations are kept in memory while players move and written back to storage
+// every playerPersistInterval, so the game loop never waits for the database.
 type PlayerService struct {
 	playerMovementRepo domain.PlayerMovementRepository
 	logger             *util.Logger
+
+	mu          sync.Mutex
+	locations   map[string]*trackedLocation // Последние известные позиции по ID игрока
+	lastPersist time.Time
 }
 
 // NewPlayerService creates a new PlayerService.
@@ -17,30 +35,30 @@ func NewPlayerService(playerMovementRepo domain.PlayerMovementRepository, logger
 	return &PlayerService{
 		playerMovementRepo: playerMovementRepo,
 		logger:             logger,
+		locations:          make(map[string]*trackedLocation),
+		lastPersist:        time.Now(),
 	}
 }
 
-// UpdatePlayerLocation updates a player's location in the database.
-func (s *PlayerService) UpdatePlayerLocation(playerID string, x, y, z float64) (*domain.Location, error) {
-	location := &domain.Location{
-		PlayerID: playerID,
-		X:        x,
-		Y:        y,
-		Z:        z,
-	}
+// UpdatePlayerLocation sets a player's location. It is written to storage by the next Persist.
+func (s *PlayerService) UpdatePlayerLocation(playerID string, x, y, z float64) *domain.Location {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	err := s.playerMovementRepo.SavePlayerLocation(location)
-	if err != nil {
-		s.logger.Error("Failed to save player location for player %s: %v", playerID, err)
-		return nil, util.ErrInternalServer
-	}
-
-	s.logger.Info("Player %s moved to (%.2f, %.2f, %.2f)", playerID, x, y, z)
-	return location, nil
+	location := domain.Location{PlayerID: playerID, X: x, Y: y, Z: z, UpdatedAt: time.Now()}
+	s.locations[playerID] = &trackedLocation{location: location, dirty: true}
+	return &location
 }
 
 // GetPlayerLocation retrieves a player's current location.
 func (s *PlayerService) GetPlayerLocation(playerID string) (*domain.Location, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if tracked, ok := s.locations[playerID]; ok {
+		location := tracked.location
+		return &location, nil
+	}
 	location, err := s.playerMovementRepo.GetPlayerLocation(playerID)
 	if err != nil {
 		if errors.Is(err, util.ErrPlayerLocationNotFound) {
@@ -49,15 +67,68 @@ func (s *PlayerService) GetPlayerLocation(playerID string) (*domain.Location, er
 		s.logger.Error("Failed to get player location for player %s: %v", playerID, err)
 		return nil, util.ErrInternalServer
 	}
+	s.locations[playerID] = &trackedLocation{location: *location}
 	return location, nil
 }
 
-// GetAllPlayerLocations retrieves all players' current locations.
+// GetAllPlayerLocations retrieves all players' current locations ordered by player ID.
 func (s *PlayerService) GetAllPlayerLocations() ([]domain.Location, error) {
-	locations, err := s.playerMovementRepo.GetAllPlayerLocations()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.playerMovementRepo.GetAllPlayerLocations()
 	if err != nil {
 		s.logger.Error("Failed to get all player locations: %v", err)
 		return nil, util.ErrInternalServer
 	}
+	locations := make([]domain.Location, 0, len(stored)+len(s.locations))
+	for _, location := range stored {
+		if _, ok := s.locations[location.PlayerID]; !ok {
+			locations = append(locations, location)
+		}
+	}
+	for _, tracked := range s.locations {
+		locations = append(locations, tracked.location)
+	}
+	sort.Slice(locations, func(i, j int) bool { return locations[i].PlayerID < locations[j].PlayerID })
 	return locations, nil
 }
+
+// PersistIfDue writes moved players back to storage every playerPersistInterval.
+func (s *PlayerService) PersistIfDue(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastPersist) < playerPersistInterval {
+		return
+	}
+	s.lastPersist = now
+	s.persistLocked()
+}
+
+// Persist writes the players that moved since the last call back to storage.
+func (s *PlayerService) Persist() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.persistLocked()
+}
+
+func (s *PlayerService) persistLocked() {
+	saved := 0
+	for playerID, tracked := range s.locations {
+		if !tracked.dirty {
+			continue
+		}
+		location := tracked.location
+		if err := s.playerMovementRepo.SavePlayerLocation(&location); err != nil {
+			s.logger.Error("Failed to persist location of player %s: %v", playerID, err)
+			continue
+		}
+		tracked.dirty = false
+		saved++
+	}
+	if saved > 0 {
+		s.logger.Info("Persisted %d player locations", saved)
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"errors"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// RoleService manages the roles of users. Roles are embedded in the tokens issued
+// at login, so a granted or revoked role takes effect with the user's next token.
+type RoleService struct {
+	userRepo  domain.UserRepository
+	roleRepo  domain.RoleRepository
+	bootstrap map[string]bool // Пользователи, которые всегда получают роль admin
+	logger    *util.Logger
+}
+
+// NewRoleService creates a new RoleService. The users named in bootstrapAdmins are
+// granted the admin role whenever they log in, so a fresh installation has an
+// administrator who can grant roles to others.
+func NewRoleService(userRepo domain.UserRepository, roleRepo domain.RoleRepository, bootstrapAdmins []string, logger *util.Logger) *RoleService {
+	bootstrap := make(map[string]bool, len(bootstrapAdmins))
+	for _, name := range bootstrapAdmins {
+		bootstrap[name] = true
+	}
+	return &RoleService{
+		userRepo:  userRepo,
+		roleRepo:  roleRepo,
+		bootstrap: bootstrap,
+		logger:    logger,
+	}
+}
+
+// RolesFor returns the roles to put in a token issued to user.
+func (s *RoleService) RolesFor(user *domain.User) ([]string, error) {
+	roles, err := s.roleRepo.GetUserRoles(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if s.bootstrap[user.Username] && !domain.HasAnyRole(roles, domain.RoleAdmin) {
+		if err := s.roleRepo.GrantRole(user.ID, domain.RoleAdmin); err != nil {
+			return nil, err
+		}
+		s.logger.Info("Granted role %s to bootstrap administrator %s", domain.RoleAdmin, user.Username)
+		return s.roleRepo.GetUserRoles(user.ID)
+	}
+	return roles, nil
+}
+
+// UserRoles returns the roles of the user with username.
+func (s *RoleService) UserRoles(username string) ([]string, error) {
+	user, err := s.userRepo.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	return s.roleRepo.GetUserRoles(user.ID)
+}
+
+// GrantRole gives the user with username a role and returns its roles.
+func (s *RoleService) GrantRole(actor Actor, username, role string) ([]string, error) {
+	return s.changeRole(actor, username, role, true)
+}
+
+// RevokeRole takes a role from the user with username and returns its remaining roles.
+func (s *RoleService) RevokeRole(actor Actor, username, role string) ([]string, error) {
+	return s.changeRole(actor, username, role, false)
+}
+
+// changeRole grants or revokes a role of the user with username.
+func (s *RoleService) changeRole(actor Actor, username, role string, grant bool) ([]string, error) {
+	if !domain.IsValidRole(role) {
+		return nil, util.ErrInvalidRole
+	}
+	user, err := s.userRepo.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if grant {
+		err = s.roleRepo.GrantRole(user.ID, role)
+	} else {
+		err = s.roleRepo.RevokeRole(user.ID, role)
+	}
+	if err != nil {
+		if errors.Is(err, util.ErrUserNotFound) {
+			return nil, err
+		}
+		s.logger.Error("Failed to change role %s of user %s: %v", role, username, err)
+		return nil, util.ErrInternalServer
+	}
+
+	if grant {
+		s.logger.Info("Role %s granted to %s by %s", role, username, actor.Username)
+	} else {
+		s.logger.Info("Role %s revoked from %s by %s", role, username, actor.Username)
+	}
+	return s.roleRepo.GetUserRoles(user.ID)
+}
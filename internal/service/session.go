@@ -0,0 +1,163 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"anarchy-core/internal/protocol"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxMissedMessages bounds the messages buffered for a detached session.
+// When it is exceeded the client gets a full snapshot on resume instead of a replay.
+const maxMissedMessages = 128
+
+// clientSendBuffer is the capacity of a connection's outgoing message channel.
+const clientSendBuffer = 256
+
+// SessionMessage tells a newly connected client how to resume its session.
+type SessionMessage struct {
+	Type         string `json:"type"` // "session"
+	ResumeToken  string `json:"resume_token"`
+	GraceSeconds int    `json:"grace_seconds"`
+}
+
+// SessionResumedMessage confirms a resumed session. It is followed by the replayed
+// messages the client missed, or by a full snapshot if too many were missed.
+type SessionResumedMessage struct {
+	Type     string `json:"type"` // "session_resumed"
+	Replayed int    `json:"replayed"`
+	Resync   bool   `json:"resync"`
+}
+
+// NewClient creates a new session for a freshly connected player with the roles of its token.
+// The roles stay fixed for the lifetime of the session, including when it is resumed.
+func NewClient(userID, username string, roles []string, conn *websocket.Conn, codec protocol.Codec) (*Client, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate resume token: %w", err)
+	}
+	return &Client{
+		UserID:      userID,
+		Username:    username,
+		Roles:       roles,
+		ResumeToken: base64.RawURLEncoding.EncodeToString(b[:]),
+		Conn:        conn,
+		Codec:       codec,
+		Send:        make(chan []byte, clientSendBuffer),
+		visible:     make(map[string]bool),
+		chunks:      make(map[ChunkKey]uint32),
+		snapshots:   newSnapshotHistory(),
+	}, nil
+}
+
+// SendSessionInfo sends the client its resume token.
+func (s *WebSocketService) SendSessionInfo(client *Client) {
+	s.SendToClient(client, SessionMessage{
+		Type:         "session",
+		ResumeToken:  client.ResumeToken,
+		GraceSeconds: int(s.gracePeriod / time.Second),
+	})
+}
+
+// DisconnectClient handles the loss of one of a client's connections. Resumable
+// disconnects keep the session alive for the grace period; others end it at once.
+// Calls for a connection the session has already moved away from are ignored.
+func (s *WebSocketService) DisconnectClient(client *Client, conn *websocket.Conn, resumable bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if client.closed || client.Conn != conn {
+		return
+	}
+	if resumable && s.gracePeriod > 0 {
+		if !client.detached {
+			s.detachLocked(client)
+		}
+		return
+	}
+	s.removeClientLocked(client, 0)
+	s.logger.Info("Client unregistered: %s (ID: %s)", client.Username, client.UserID)
+}
+
+// detachLocked closes the client's current connection channel and keeps its session
+// in the world until it is resumed or the grace period expires. s.mu must be held.
+func (s *WebSocketService) detachLocked(client *Client) {
+	close(client.Send)
+	client.detached = true
+	client.detachGen++
+
+	gen := client.detachGen
+	time.AfterFunc(s.gracePeriod, func() { s.expireSession(client, gen) })
+	s.logger.Info("Client detached: %s (ID: %s), session kept for %s", client.Username, client.UserID, s.gracePeriod)
+}
+
+// expireSession ends a detached session whose grace period elapsed without a resume.
+func (s *WebSocketService) expireSession(client *Client, gen uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if client.closed || !client.detached || client.detachGen != gen {
+		return
+	}
+	s.removeClientLocked(client, 0)
+	s.logger.Info("Session expired: %s (ID: %s)", client.Username, client.UserID)
+}
+
+// bufferMissedLocked keeps a message for a detached client. s.mu must be held.
+func (s *WebSocketService) bufferMissedLocked(client *Client, v interface{}) {
+	if client.overflow {
+		return
+	}
+	if len(client.missed) >= maxMissedMessages {
+		client.missed = nil
+		client.overflow = true
+		return
+	}
+	client.missed = append(client.missed, v)
+}
+
+// ResumeClient reattaches a new connection to the session identified by token.
+// It returns false if the token is unknown, expired or belongs to another user.
+// On success the missed messages are replayed and a snapshot delta is sent.
+func (s *WebSocketService) ResumeClient(token, userID string, conn *websocket.Conn, codec protocol.Codec) (*Client, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client, ok := s.sessions[token]
+	if !ok || client.closed || client.UserID != userID {
+		return nil, false
+	}
+	if !client.detached {
+		// The old connection has not noticed it is dead yet; take the session over
+		s.detachLocked(client)
+	}
+
+	client.Conn = conn
+	client.Codec = codec
+	client.Send = make(chan []byte, clientSendBuffer)
+	client.detached = false
+	client.detachGen++
+
+	missed, overflow := client.missed, client.overflow
+	client.missed, client.overflow = nil, false
+
+	s.sendLocked(client, SessionResumedMessage{Type: "session_resumed", Replayed: len(missed), Resync: overflow})
+	if overflow {
+		// Dropped messages may have included terrain chunks; stream them again
+		client.snapshots.reset()
+		client.chunks = make(map[ChunkKey]uint32)
+	} else {
+		for _, message := range missed {
+			s.sendLocked(client, message)
+		}
+		client.snapshots.rewind()
+	}
+	s.sendSnapshotLocked(client)
+
+	s.logger.Info("Session resumed: %s (ID: %s), replayed %d message(s)", client.Username, client.UserID, len(missed))
+	return client, true
+}
@@ -0,0 +1,223 @@
+package service
+
+import "sort"
+
+// snapshotHistorySize is how many sent snapshots are kept per client as delta baselines.
+// A client that falls further behind than this receives a full snapshot.
+const snapshotHistorySize = 32
+
+// PlayerDelta is the state of one player in a snapshot. In a delta snapshot only
+// the fields that differ from the baseline are set; new players are always sent in full.
+type PlayerDelta struct {
+	PlayerID  string   `json:"player_id"`
+	Username  string   `json:"username,omitempty"`
+	X         *float64 `json:"x,omitempty"`
+	Y         *float64 `json:"y,omitempty"`
+	Z         *float64 `json:"z,omitempty"`
+	Timestamp int64    `json:"timestamp,omitempty"` // Unix time in milliseconds
+}
+
+// EntityDelta is the state of one NPC or mob in a snapshot, delta-encoded like PlayerDelta.
+type EntityDelta struct {
+	EntityID     int      `json:"entity_id"`
+	EntityListID int      `json:"entity_list_id,omitempty"`
+	State        string   `json:"state,omitempty"`
+	Health       *float64 `json:"health,omitempty"`
+	X            *float64 `json:"x,omitempty"`
+	Y            *float64 `json:"y,omitempty"`
+	Z            *float64 `json:"z,omitempty"`
+}
+
+// SnapshotMessage carries the world state visible to a client, either in full
+// (Baseline == 0) or as a delta against a snapshot the client has acknowledged.
+type SnapshotMessage struct {
+	Type            string        `json:"type"` // "snapshot"
+	ID              uint32        `json:"id"`
+	Baseline        uint32        `json:"baseline"`
+	Tick            uint64        `json:"tick"`
+	Players         []PlayerDelta `json:"players,omitempty"`
+	Removed         []string      `json:"removed,omitempty"`
+	Entities        []EntityDelta `json:"entities,omitempty"`
+	RemovedEntities []int         `json:"removed_entities,omitempty"`
+}
+
+// snapshotState is the set of players and entities visible to a client.
+type snapshotState struct {
+	players  map[string]PlayerLocationUpdate // По ID игрока
+	entities map[int]EntityUpdate            // По ID сущности
+}
+
+// snapshotHistory tracks the snapshots sent to one client and the latest one it acknowledged.
+type snapshotHistory struct {
+	lastSent uint32
+	acked    uint32
+	states   map[uint32]snapshotState
+}
+
+func newSnapshotHistory() *snapshotHistory {
+	return &snapshotHistory{states: make(map[uint32]snapshotState)}
+}
+
+// next builds the snapshot for state, delta-encoded against the last acknowledged
+// snapshot when it is still in the history. It returns false if nothing changed
+// since the previously sent snapshot.
+func (h *snapshotHistory) next(state snapshotState, tick uint64) (SnapshotMessage, bool) {
+	if prev, ok := h.states[h.lastSent]; ok && statesEqual(prev, state) {
+		return SnapshotMessage{}, false
+	}
+
+	h.lastSent++
+	h.states[h.lastSent] = state
+	delete(h.states, h.lastSent-snapshotHistorySize)
+
+	msg := SnapshotMessage{Type: "snapshot", ID: h.lastSent, Tick: tick}
+	baseline, ok := h.states[h.acked]
+	if ok && h.acked != 0 {
+		msg.Baseline = h.acked
+	} else {
+		baseline = snapshotState{}
+	}
+
+	for _, cur := range state.players {
+		if delta, changed := diffPlayer(baseline.players, cur); changed {
+			msg.Players = append(msg.Players, delta)
+		}
+	}
+	for id := range baseline.players {
+		if _, ok := state.players[id]; !ok {
+			msg.Removed = append(msg.Removed, id)
+		}
+	}
+	for _, cur := range state.entities {
+		if delta, changed := diffEntity(baseline.entities, cur); changed {
+			msg.Entities = append(msg.Entities, delta)
+		}
+	}
+	for id := range baseline.entities {
+		if _, ok := state.entities[id]; !ok {
+			msg.RemovedEntities = append(msg.RemovedEntities, id)
+		}
+	}
+	sort.Slice(msg.Players, func(i, j int) bool { return msg.Players[i].PlayerID < msg.Players[j].PlayerID })
+	sort.Strings(msg.Removed)
+	sort.Slice(msg.Entities, func(i, j int) bool { return msg.Entities[i].EntityID < msg.Entities[j].EntityID })
+	sort.Ints(msg.RemovedEntities)
+	return msg, true
+}
+
+// ack records that the client has received snapshot id.
+// Unknown or outdated acknowledgements are ignored.
+func (h *snapshotHistory) ack(id uint32) bool {
+	if id <= h.acked || id > h.lastSent {
+		return false
+	}
+	if _, ok := h.states[id]; !ok {
+		return false
+	}
+	h.acked = id
+	return true
+}
+
+// rewind discards the snapshots the client has not acknowledged, which may have been
+// lost with a dropped connection, so the next snapshot is re-sent against the last ack.
+func (h *snapshotHistory) rewind() {
+	for id := range h.states {
+		if id > h.acked {
+			delete(h.states, id)
+		}
+	}
+}
+
+// reset forces the next snapshot to be sent in full.
+func (h *snapshotHistory) reset() {
+	h.acked = 0
+	h.states = make(map[uint32]snapshotState)
+}
+
+// diffPlayer returns the fields of cur that differ from its baseline entry.
+func diffPlayer(baseline map[string]PlayerLocationUpdate, cur PlayerLocationUpdate) (PlayerDelta, bool) {
+	delta := PlayerDelta{PlayerID: cur.PlayerID}
+	prev, known := baseline[cur.PlayerID]
+	if !known {
+		x, y, z := cur.X, cur.Y, cur.Z
+		delta.Username, delta.X, delta.Y, delta.Z, delta.Timestamp = cur.Username, &x, &y, &z, cur.Timestamp
+		return delta, true
+	}
+
+	changed := false
+	if cur.Username != prev.Username {
+		delta.Username, changed = cur.Username, true
+	}
+	if cur.X != prev.X {
+		x := cur.X
+		delta.X, changed = &x, true
+	}
+	if cur.Y != prev.Y {
+		y := cur.Y
+		delta.Y, changed = &y, true
+	}
+	if cur.Z != prev.Z {
+		z := cur.Z
+		delta.Z, changed = &z, true
+	}
+	if changed {
+		delta.Timestamp = cur.Timestamp
+	}
+	return delta, changed
+}
+
+// diffEntity returns the fields of cur that differ from its baseline entry.
+func diffEntity(baseline map[int]EntityUpdate, cur EntityUpdate) (EntityDelta, bool) {
+	delta := EntityDelta{EntityID: cur.EntityID}
+	prev, known := baseline[cur.EntityID]
+	if !known {
+		health, x, y, z := cur.Health, cur.X, cur.Y, cur.Z
+		delta.EntityListID, delta.State = cur.EntityListID, cur.State
+		delta.Health, delta.X, delta.Y, delta.Z = &health, &x, &y, &z
+		return delta, true
+	}
+
+	changed := false
+	if cur.EntityListID != prev.EntityListID {
+		delta.EntityListID, changed = cur.EntityListID, true
+	}
+	if cur.State != prev.State {
+		delta.State, changed = cur.State, true
+	}
+	if cur.Health != prev.Health {
+		health := cur.Health
+		delta.Health, changed = &health, true
+	}
+	if cur.X != prev.X {
+		x := cur.X
+		delta.X, changed = &x, true
+	}
+	if cur.Y != prev.Y {
+		y := cur.Y
+		delta.Y, changed = &y, true
+	}
+	if cur.Z != prev.Z {
+		z := cur.Z
+		delta.Z, changed = &z, true
+	}
+	return delta, changed
+}
+
+// statesEqual reports whether two snapshot states contain the same players and entities in the same state.
+func statesEqual(a, b snapshotState) bool {
+	if len(a.players) != len(b.players) || len(a.entities) != len(b.entities) {
+		return false
+	}
+	for id, pa := range a.players {
+		pb, ok := b.players[id]
+		if !ok || pa.X != pb.X || pa.Y != pb.Y || pa.Z != pb.Z || pa.Username != pb.Username {
+			return false
+		}
+	}
+	for id, ea := range a.entities {
+		if eb, ok := b.entities[id]; !ok || ea != eb {
+			return false
+		}
+	}
+	return true
+}
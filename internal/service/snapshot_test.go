@@ -0,0 +1,214 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+// players builds a snapshot state of players at the given X coordinates.
+func players(xs map[string]float64) snapshotState {
+	state := snapshotState{players: make(map[string]PlayerLocationUpdate), entities: make(map[int]EntityUpdate)}
+	for id, x := range xs {
+		state.players[id] = PlayerLocationUpdate{PlayerID: id, Username: "user-" + id, X: x, Timestamp: 1}
+	}
+	return state
+}
+
+func ptr(f float64) *float64 { return &f }
+
+func TestSnapshotHistoryNext(t *testing.T) {
+	// step sends state, or acknowledges ack if it is not zero, and checks the result.
+	type step struct {
+		state snapshotState
+		ack   uint32
+
+		wantAcked bool            // Результат ack
+		wantSent  bool            // Снимок отправлен
+		want      SnapshotMessage // Без Type и Tick
+	}
+	full := func(id uint32, deltas ...PlayerDelta) SnapshotMessage {
+		return SnapshotMessage{ID: id, Players: deltas}
+	}
+	entered := func(id string, x float64) PlayerDelta {
+		return PlayerDelta{PlayerID: id, Username: "user-" + id, X: ptr(x), Y: ptr(0), Z: ptr(0), Timestamp: 1}
+	}
+
+	for _, tc := range []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "first snapshot is full",
+			steps: []step{
+				{state: players(map[string]float64{"a": 1, "b": 2}), wantSent: true, want: full(1, entered("a", 1), entered("b", 2))},
+			},
+		},
+		{
+			name: "unchanged state is not sent",
+			steps: []step{
+				{state: players(map[string]float64{"a": 1}), wantSent: true, want: full(1, entered("a", 1))},
+				{state: players(map[string]float64{"a": 1})},
+			},
+		},
+		{
+			name: "unacknowledged snapshots are re-sent in full",
+			steps: []step{
+				{state: players(map[string]float64{"a": 1}), wantSent: true, want: full(1, entered("a", 1))},
+				{state: players(map[string]float64{"a": 2}), wantSent: true, want: full(2, entered("a", 2))},
+			},
+		},
+		{
+			name: "delta against the acknowledged snapshot",
+			steps: []step{
+				{state: players(map[string]float64{"a": 1, "b": 2}), wantSent: true, want: full(1, entered("a", 1), entered("b", 2))},
+				{ack: 1, wantAcked: true},
+				{
+					state:    players(map[string]float64{"a": 5, "c": 3}),
+					wantSent: true,
+					want: SnapshotMessage{
+						ID:       2,
+						Baseline: 1,
+						Players:  []PlayerDelta{{PlayerID: "a", X: ptr(5), Timestamp: 1}, entered("c", 3)},
+						Removed:  []string{"b"},
+					},
+				},
+			},
+		},
+		{
+			name: "delta stays against the last ack until the next one",
+			steps: []step{
+				{state: players(map[string]float64{"a": 1}), wantSent: true, want: full(1, entered("a", 1))},
+				{ack: 1, wantAcked: true},
+				{state: players(map[string]float64{"a": 2}), wantSent: true, want: SnapshotMessage{ID: 2, Baseline: 1, Players: []PlayerDelta{{PlayerID: "a", X: ptr(2), Timestamp: 1}}}},
+				// Back to the acknowledged state: nothing differs from the baseline, but the
+				// client must still learn that the unacknowledged change was reverted
+				{state: players(map[string]float64{"a": 1}), wantSent: true, want: SnapshotMessage{ID: 3, Baseline: 1}},
+				{state: players(map[string]float64{"a": 3}), wantSent: true, want: SnapshotMessage{ID: 4, Baseline: 1, Players: []PlayerDelta{{PlayerID: "a", X: ptr(3), Timestamp: 1}}}},
+			},
+		},
+		{
+			name: "outdated, future and repeated acks are ignored",
+			steps: []step{
+				{state: players(map[string]float64{"a": 1}), wantSent: true, want: full(1, entered("a", 1))},
+				{state: players(map[string]float64{"a": 2}), wantSent: true, want: full(2, entered("a", 2))},
+				{ack: 3},
+				{ack: 2, wantAcked: true},
+				{ack: 2},
+				{ack: 1},
+				{state: players(map[string]float64{"a": 4}), wantSent: true, want: SnapshotMessage{ID: 3, Baseline: 2, Players: []PlayerDelta{{PlayerID: "a", X: ptr(4), Timestamp: 1}}}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newSnapshotHistory()
+			for i, s := range tc.steps {
+				if s.ack != 0 {
+					if got := h.ack(s.ack); got != s.wantAcked {
+						t.Fatalf("step %d: ack(%d) = %v, want %v", i, s.ack, got, s.wantAcked)
+					}
+					continue
+				}
+				msg, sent := h.next(s.state, uint64(i))
+				if sent != s.wantSent {
+					t.Fatalf("step %d: sent = %v, want %v", i, sent, s.wantSent)
+				}
+				if !sent {
+					continue
+				}
+				s.want.Type, s.want.Tick = "snapshot", uint64(i)
+				if !reflect.DeepEqual(msg, s.want) {
+					t.Fatalf("step %d:\n got: %+v\nwant: %+v", i, msg, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSnapshotHistoryEvictsOldBaselines(t *testing.T) {
+	h := newSnapshotHistory()
+	h.next(players(map[string]float64{"a": 0}), 0)
+	if !h.ack(1) {
+		t.Fatal("ack(1) failed")
+	}
+	for i := 1; i < snapshotHistorySize; i++ {
+		h.next(players(map[string]float64{"a": float64(i)}), uint64(i))
+	}
+
+	// Snapshot 1 is still the oldest kept one
+	msg, _ := h.next(players(map[string]float64{"a": -1}), 100)
+	if msg.Baseline != 0 {
+		t.Fatalf("snapshot %d: baseline = %d after the acknowledged snapshot was evicted, want a full snapshot", msg.ID, msg.Baseline)
+	}
+	if h.ack(1) {
+		t.Error("ack of an evicted snapshot succeeded")
+	}
+	if !h.ack(msg.ID) {
+		t.Fatalf("ack(%d) failed", msg.ID)
+	}
+	msg, _ = h.next(players(map[string]float64{"a": -2}), 101)
+	if msg.Baseline != msg.ID-1 {
+		t.Errorf("baseline = %d, want %d", msg.Baseline, msg.ID-1)
+	}
+}
+
+func TestSnapshotHistoryRewindAndReset(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		restore      func(h *snapshotHistory)
+		wantBaseline uint32
+		wantAck      bool // Подтверждение снимка, отправленного после разрыва, принимается
+	}{
+		{"rewind keeps the acknowledged baseline", (*snapshotHistory).rewind, 1, true},
+		{"reset forces a full snapshot", (*snapshotHistory).reset, 0, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newSnapshotHistory()
+			h.next(players(map[string]float64{"a": 1}), 1)
+			h.ack(1)
+			h.next(players(map[string]float64{"a": 2}), 2)
+			h.next(players(map[string]float64{"a": 3}), 3)
+
+			tc.restore(h)
+			if h.ack(2) {
+				t.Error("ack of a snapshot lost with the connection succeeded")
+			}
+			// The state equals the last sent one, but that may never have arrived
+			msg, sent := h.next(players(map[string]float64{"a": 3}), 4)
+			if !sent {
+				t.Fatal("snapshot after the reconnect was not sent")
+			}
+			if msg.Baseline != tc.wantBaseline {
+				t.Errorf("baseline = %d, want %d", msg.Baseline, tc.wantBaseline)
+			}
+			if msg.ID != 4 {
+				t.Errorf("id = %d, want 4: snapshot IDs must not be reused", msg.ID)
+			}
+			if got := h.ack(msg.ID); got != tc.wantAck {
+				t.Errorf("ack(%d) = %v, want %v", msg.ID, got, tc.wantAck)
+			}
+		})
+	}
+}
+
+func TestSnapshotEntityDeltas(t *testing.T) {
+	base := EntityUpdate{EntityID: 7, EntityListID: 2, State: "idle", Health: 10, X: 1, Y: 2, Z: 3}
+	for _, tc := range []struct {
+		name    string
+		cur     EntityUpdate
+		want    EntityDelta
+		changed bool
+	}{
+		{"unchanged", base, EntityDelta{EntityID: 7}, false},
+		{"state", EntityUpdate{EntityID: 7, EntityListID: 2, State: "chase", Health: 10, X: 1, Y: 2, Z: 3}, EntityDelta{EntityID: 7, State: "chase"}, true},
+		{"health to zero", EntityUpdate{EntityID: 7, EntityListID: 2, State: "idle", Health: 0, X: 1, Y: 2, Z: 3}, EntityDelta{EntityID: 7, Health: ptr(0)}, true},
+		{"position", EntityUpdate{EntityID: 7, EntityListID: 2, State: "idle", Health: 10, X: 1, Y: 2.5, Z: 0}, EntityDelta{EntityID: 7, Y: ptr(2.5), Z: ptr(0)}, true},
+		{"new entity in full", EntityUpdate{EntityID: 8, EntityListID: 2, State: "idle"}, EntityDelta{EntityID: 8, EntityListID: 2, State: "idle", Health: ptr(0), X: ptr(0), Y: ptr(0), Z: ptr(0)}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, changed := diffEntity(map[int]EntityUpdate{7: base}, tc.cur)
+			if changed != tc.changed || !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("diffEntity = %+v, %v; want %+v, %v", got, changed, tc.want, tc.changed)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import "math"
+
+// gridCell identifies a cell of the spatial grid on the X/Z plane.
+type gridCell struct {
+	x, z int
+}
+
+// gridPoint is the last known X/Z position of an object in the grid.
+type gridPoint struct {
+	x, z float64
+}
+
+// SpatialGrid is a uniform grid index over the X/Z plane used to find objects
+// near a position without scanning every object. It is not safe for concurrent use.
+type SpatialGrid struct {
+	cellSize  float64
+	cells     map[gridCell]map[string]struct{}
+	positions map[string]gridPoint
+}
+
+// NewSpatialGrid creates a SpatialGrid with the given cell size.
+// Choosing a cell size close to the typical query radius keeps queries cheap.
+func NewSpatialGrid(cellSize float64) *SpatialGrid {
+	return &SpatialGrid{
+		cellSize:  cellSize,
+		cells:     make(map[gridCell]map[string]struct{}),
+		positions: make(map[string]gridPoint),
+	}
+}
+
+func (g *SpatialGrid) cellOf(x, z float64) gridCell {
+	return gridCell{x: int(math.Floor(x / g.cellSize)), z: int(math.Floor(z / g.cellSize))}
+}
+
+// Update inserts an object or moves it to a new position.
+func (g *SpatialGrid) Update(id string, x, z float64) {
+	newCell := g.cellOf(x, z)
+	if old, ok := g.positions[id]; ok {
+		oldCell := g.cellOf(old.x, old.z)
+		if oldCell != newCell {
+			g.removeFromCell(oldCell, id)
+		}
+	}
+	g.positions[id] = gridPoint{x: x, z: z}
+
+	cell, ok := g.cells[newCell]
+	if !ok {
+		cell = make(map[string]struct{})
+		g.cells[newCell] = cell
+	}
+	cell[id] = struct{}{}
+}
+
+// Remove deletes an object from the grid.
+func (g *SpatialGrid) Remove(id string) {
+	pos, ok := g.positions[id]
+	if !ok {
+		return
+	}
+	g.removeFromCell(g.cellOf(pos.x, pos.z), id)
+	delete(g.positions, id)
+}
+
+func (g *SpatialGrid) removeFromCell(c gridCell, id string) {
+	cell := g.cells[c]
+	delete(cell, id)
+	if len(cell) == 0 {
+		delete(g.cells, c)
+	}
+}
+
+// Position returns the last known position of an object.
+func (g *SpatialGrid) Position(id string) (x, z float64, ok bool) {
+	pos, ok := g.positions[id]
+	return pos.x, pos.z, ok
+}
+
+// QueryRadius returns the IDs of all objects within radius of (x, z).
+func (g *SpatialGrid) QueryRadius(x, z, radius float64) []string {
+	minCell := g.cellOf(x-radius, z-radius)
+	maxCell := g.cellOf(x+radius, z+radius)
+	radiusSq := radius * radius
+
+	var ids []string
+	for cx := minCell.x; cx <= maxCell.x; cx++ {
+		for cz := minCell.z; cz <= maxCell.z; cz++ {
+			for id := range g.cells[gridCell{x: cx, z: cz}] {
+				pos := g.positions[id]
+				dx, dz := pos.x-x, pos.z-z
+				if dx*dx+dz*dz <= radiusSq {
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultRespawn is the respawn interval of rules that do not set one.
+const defaultRespawn = time.Minute
+
+// SpawnRule is the parsed form of EntityList.Spawn. The format is a list of
+// key=value pairs separated by semicolons, for example
+//
+//	area=-50,-50,50,50; max=5; respawn=30s; min_height=0; max_height=40
+//
+// area (required) is the rectangle x1,z1,x2,z2 entities appear in; max (required)
+// is the population kept alive; respawn is the delay before a missing entity is
+// replaced (default 1m); min_height and max_height restrict spawn points to
+// terrain within those heights.
+type SpawnRule struct {
+	MinX, MinZ float64
+	MaxX, MaxZ float64
+	Max        int
+	Respawn    time.Duration
+	MinHeight  *float64
+	MaxHeight  *float64
+}
+
+// ParseSpawnRule parses a spawn rule string.
+func ParseSpawnRule(s string) (SpawnRule, error) {
+	rule := SpawnRule{Respawn: defaultRespawn}
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || value == "" {
+			return SpawnRule{}, fmt.Errorf("spawn rule: expected key=value, got %q", part)
+		}
+		if seen[key] {
+			return SpawnRule{}, fmt.Errorf("spawn rule: duplicate key %q", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "area":
+			err = rule.parseArea(value)
+		case "max":
+			rule.Max, err = strconv.Atoi(value)
+			if err == nil && rule.Max < 1 {
+				err = fmt.Errorf("must be at least 1")
+			}
+		case "respawn":
+			rule.Respawn, err = time.ParseDuration(value)
+			if err == nil && rule.Respawn < 0 {
+				err = fmt.Errorf("must not be negative")
+			}
+		case "min_height":
+			rule.MinHeight, err = parseHeight(value)
+		case "max_height":
+			rule.MaxHeight, err = parseHeight(value)
+		default:
+			return SpawnRule{}, fmt.Errorf("spawn rule: unknown key %q", key)
+		}
+		if err != nil {
+			return SpawnRule{}, fmt.Errorf("spawn rule: invalid %s %q: %v", key, value, err)
+		}
+	}
+
+	if !seen["area"] || !seen["max"] {
+		return SpawnRule{}, fmt.Errorf("spawn rule: area and max are required")
+	}
+	if rule.MinHeight != nil && rule.MaxHeight != nil && *rule.MinHeight > *rule.MaxHeight {
+		return SpawnRule{}, fmt.Errorf("spawn rule: min_height is above max_height")
+	}
+	return rule, nil
+}
+
+// parseArea parses the x1,z1,x2,z2 rectangle of a rule.
+func (r *SpawnRule) parseArea(value string) error {
+	fields := strings.Split(value, ",")
+	if len(fields) != 4 {
+		return fmt.Errorf("expected x1,z1,x2,z2")
+	}
+	var coords [4]float64
+	for i, field := range fields {
+		v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("coordinate %q is not a number", field)
+		}
+		coords[i] = v
+	}
+	r.MinX, r.MaxX = math.Min(coords[0], coords[2]), math.Max(coords[0], coords[2])
+	r.MinZ, r.MaxZ = math.Min(coords[1], coords[3]), math.Max(coords[1], coords[3])
+	return nil
+}
+
+// parseHeight parses a height bound of a rule.
+func parseHeight(value string) (*float64, error) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil, fmt.Errorf("not a number")
+	}
+	return &v, nil
+}
+
+// Contains reports whether (x, z) lies inside the rule's area.
+func (r SpawnRule) Contains(x, z float64) bool {
+	return x >= r.MinX && x <= r.MaxX && z >= r.MinZ && z <= r.MaxZ
+}
+
+// HasHeightLimits reports whether the rule restricts spawn points by terrain height.
+func (r SpawnRule) HasHeightLimits() bool {
+	return r.MinHeight != nil || r.MaxHeight != nil
+}
+
+// AllowsHeight reports whether terrain of the given height satisfies the rule.
+func (r SpawnRule) AllowsHeight(height float64) bool {
+	return (r.MinHeight == nil || height >= *r.MinHeight) && (r.MaxHeight == nil || height <= *r.MaxHeight)
+}
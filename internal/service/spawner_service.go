@@ -0,0 +1,286 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+const (
+	// spawnAttempts is how many random points are tried to place one entity.
+	spawnAttempts = 10
+	// spawnRulesRefresh is how often the spawner re-reads the entity templates.
+	spawnRulesRefresh = time.Minute
+)
+
+// SpawnedEntity is an entity created by the spawner together with its template.
+type SpawnedEntity struct {
+	Entity   domain.Entity
+	Template domain.EntityList
+}
+
+// spawnGroup is the population kept alive for one spawning template.
+type spawnGroup struct {
+	template  domain.EntityList
+	rule      SpawnRule
+	alive     map[int]bool // ID живых сущностей группы
+	nextSpawn time.Time
+	filled    bool // Начальное заполнение выполнено
+}
+
+// SpawnerService keeps the world populated with the entities of templates that
+// have IsSpawning set, following the SpawnRule in their Spawn field. Entities are
+// persisted as they spawn and despawned when their template's rule changes.
+// It is stepped by the game loop and is not safe for concurrent use.
+type SpawnerService struct {
+	entityRepo     domain.EntityRepository
+	entityListRepo domain.EntityListRepository
+	terrainService *TerrainService
+	logger         *util.Logger
+
+	rng         *rand.Rand
+	groups      map[int]*spawnGroup // По ID шаблона
+	invalid     map[int]string      // Шаблоны с ошибочным правилом, чтобы не повторять ошибку в логе
+	lastRefresh time.Time
+}
+
+// NewSpawnerService creates a new SpawnerService.
+func NewSpawnerService(
+	entityRepo domain.EntityRepository,
+	entityListRepo domain.EntityListRepository,
+	terrainService *TerrainService,
+	logger *util.Logger,
+) *SpawnerService {
+	return &SpawnerService{
+		entityRepo:     entityRepo,
+		entityListRepo: entityListRepo,
+		terrainService: terrainService,
+		logger:         logger,
+		rng:            rand.New(rand.NewSource(time.Now().UnixNano())),
+		groups:         make(map[int]*spawnGroup),
+		invalid:        make(map[int]string),
+	}
+}
+
+// Load reads the spawn rules and adopts the already persisted entities of spawning
+// templates. Dead ones, left behind when a despawn failed or the server stopped
+// before it, are despawned instead so that their group refills.
+func (s *SpawnerService) Load(now time.Time) error {
+	if _, err := s.refresh(now); err != nil {
+		return err
+	}
+	entities, err := s.entityRepo.GetAllEntities()
+	if err != nil {
+		return fmt.Errorf("failed to load entities: %w", err)
+	}
+	dead := 0
+	for _, entity := range entities {
+		group, ok := s.groups[entity.EntityListID]
+		if !ok {
+			continue
+		}
+		if entity.Health <= 0 {
+			s.despawn(entity.ID)
+			dead++
+			continue
+		}
+		group.alive[entity.ID] = true
+	}
+	s.logger.Info("Spawner loaded %d spawn rules, despawned %d dead entities", len(s.groups), dead)
+	return nil
+}
+
+// Step spawns the entities that are due and despawns those whose rule changed.
+// It returns the entities created and the IDs of the entities removed.
+func (s *SpawnerService) Step(now time.Time) ([]SpawnedEntity, []int) {
+	var despawned []int
+	if now.Sub(s.lastRefresh) >= spawnRulesRefresh {
+		removed, err := s.refresh(now)
+		if err != nil {
+			s.logger.Error("Failed to refresh spawn rules: %v", err)
+		}
+		despawned = append(despawned, removed...)
+	}
+
+	var spawned []SpawnedEntity
+	for _, id := range s.sortedGroupIDs() {
+		group := s.groups[id]
+		despawned = append(despawned, s.trim(group)...)
+
+		for len(group.alive) < group.rule.Max && !now.Before(group.nextSpawn) {
+			entity, ok := s.spawn(group)
+			if !ok {
+				group.nextSpawn = now.Add(group.rule.Respawn)
+				break
+			}
+			spawned = append(spawned, SpawnedEntity{Entity: entity, Template: group.template})
+			// The initial population appears at once, replacements one per respawn interval
+			if group.filled {
+				group.nextSpawn = now.Add(group.rule.Respawn)
+			}
+		}
+		group.filled = true
+	}
+	return spawned, despawned
+}
+
+// Reload re-reads the spawn rules at once instead of waiting for the periodic refresh.
+// It returns the IDs of the entities despawned because their rule changed.
+func (s *SpawnerService) Reload(now time.Time) []int {
+	despawned, err := s.refresh(now)
+	if err != nil {
+		s.logger.Error("Failed to reload spawn rules: %v", err)
+	}
+	return despawned
+}
+
+// EntityDied tells the spawner that an entity was killed. Entities of spawning
+// templates are deleted from storage and replaced after the rule's respawn interval.
+func (s *SpawnerService) EntityDied(entityID, entityListID int, now time.Time) {
+	if s.EntityRemoved(entityID, entityListID, now) {
+		s.despawn(entityID)
+	}
+}
+
+// EntityRemoved tells the spawner that an entity left the world, e.g. was picked up.
+// Entities of spawning templates are replaced after the rule's respawn interval.
+// It reports whether the entity belonged to a spawn group.
+func (s *SpawnerService) EntityRemoved(entityID, entityListID int, now time.Time) bool {
+	group, ok := s.groups[entityListID]
+	if !ok || !group.alive[entityID] {
+		return false
+	}
+	delete(group.alive, entityID)
+	if next := now.Add(group.rule.Respawn); group.nextSpawn.Before(next) {
+		group.nextSpawn = next
+	}
+	return true
+}
+
+// refresh re-reads the templates and rebuilds the spawn groups. Groups whose template
+// stopped spawning or whose rule changed are despawned; their IDs are returned.
+func (s *SpawnerService) refresh(now time.Time) ([]int, error) {
+	s.lastRefresh = now
+	templates, err := s.entityListRepo.GetAllEntityLists()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load entity templates: %w", err)
+	}
+
+	rules := make(map[int]SpawnRule)
+	byID := make(map[int]domain.EntityList)
+	for _, template := range templates {
+		if !template.IsSpawning || template.Spawn == "" {
+			continue
+		}
+		rule, err := ParseSpawnRule(template.Spawn)
+		if err != nil {
+			if s.invalid[template.ID] != template.Spawn {
+				s.logger.Error("Entity template %d has an invalid spawn rule: %v", template.ID, err)
+				s.invalid[template.ID] = template.Spawn
+			}
+			continue
+		}
+		delete(s.invalid, template.ID)
+		rules[template.ID] = rule
+		byID[template.ID] = template
+	}
+
+	var despawned []int
+	for id, group := range s.groups {
+		if template, ok := byID[id]; ok && template.Spawn == group.template.Spawn {
+			group.template = template
+			continue
+		}
+		s.logger.Info("Spawn rule of entity template %d changed, despawning %d entities", id, len(group.alive))
+		for entityID := range group.alive {
+			if s.despawn(entityID) {
+				despawned = append(despawned, entityID)
+			}
+		}
+		delete(s.groups, id)
+	}
+	for id, rule := range rules {
+		if _, ok := s.groups[id]; !ok {
+			s.groups[id] = &spawnGroup{template: byID[id], rule: rule, alive: make(map[int]bool)}
+		}
+	}
+	sort.Ints(despawned)
+	return despawned, nil
+}
+
+// trim despawns the newest entities of a group that exceed its maximum population.
+func (s *SpawnerService) trim(group *spawnGroup) []int {
+	excess := len(group.alive) - group.rule.Max
+	if excess <= 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(group.alive))
+	for id := range group.alive {
+		ids = append(ids, id)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(ids)))
+
+	var despawned []int
+	for _, id := range ids[:excess] {
+		if s.despawn(id) {
+			delete(group.alive, id)
+			despawned = append(despawned, id)
+		}
+	}
+	return despawned
+}
+
+// spawn creates and persists one entity of a group at a random point that
+// satisfies its rule.
+func (s *SpawnerService) spawn(group *spawnGroup) (domain.Entity, bool) {
+	rule := group.rule
+	for attempt := 0; attempt < spawnAttempts; attempt++ {
+		x := rule.MinX + s.rng.Float64()*(rule.MaxX-rule.MinX)
+		z := rule.MinZ + s.rng.Float64()*(rule.MaxZ-rule.MinZ)
+		height, known := s.terrainService.HeightAt(x, z)
+		if rule.HasHeightLimits() && (!known || !rule.AllowsHeight(height)) {
+			continue
+		}
+
+		entity := domain.Entity{
+			EntityListID: group.template.ID,
+			Health:       group.template.MaxHealth,
+			X:            x,
+			Y:            height,
+			Z:            z,
+		}
+		if err := s.entityRepo.SpawnEntity(&entity, group.template.ObjectListID); err != nil {
+			s.logger.Error("Failed to spawn entity of template %d: %v", group.template.ID, err)
+			return domain.Entity{}, false
+		}
+		group.alive[entity.ID] = true
+		return entity, true
+	}
+	s.logger.Error("No valid spawn point for entity template %d after %d attempts", group.template.ID, spawnAttempts)
+	return domain.Entity{}, false
+}
+
+// despawn deletes a spawned entity from storage. An entity that is already gone,
+// e.g. deleted together with its template, counts as despawned.
+func (s *SpawnerService) despawn(entityID int) bool {
+	if err := s.entityRepo.DespawnEntity(entityID); err != nil && !errors.Is(err, util.ErrEntityNotFound) {
+		s.logger.Error("Failed to despawn entity %d: %v", entityID, err)
+		return false
+	}
+	return true
+}
+
+// sortedGroupIDs returns the template IDs of all groups in ascending order.
+func (s *SpawnerService) sortedGroupIDs() []int {
+	ids := make([]int, 0, len(s.groups))
+	for id := range s.groups {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"math"
+)
+
+// chunkNoSample marks a grid point without a height sample in a chunk's quantized heights.
+const chunkNoSample = math.MaxUint16
+
+// MaxChunkRequest bounds the number of chunks a client may ask for in one chunk_request.
+const MaxChunkRequest = 64
+
+// ChunkKey identifies a terrain chunk by its chunk coordinates on the X/Z plane.
+type ChunkKey struct {
+	X int `json:"x"`
+	Z int `json:"z"`
+}
+
+// ChunkMessage carries one terrain chunk. Heights holds Size×Size little-endian uint16
+// samples in row-major order (Z rows, X columns) starting at grid point
+// (X*Size, Z*Size); a sample decodes to MinHeight + q*Scale, and 0xFFFF means no sample.
+// Version changes whenever the chunk contents change and is 0 for chunks without terrain.
+// NotModified replies to a chunk_request whose cached version is still current.
+type ChunkMessage struct {
+	Type        string  `json:"type"` // "chunk"
+	X           int     `json:"x"`
+	Z           int     `json:"z"`
+	Version     uint32  `json:"version"`
+	Size        int     `json:"size,omitempty"`
+	CellSize    float64 `json:"cell_size,omitempty"`
+	MinHeight   float64 `json:"min_height,omitempty"`
+	Scale       float64 `json:"scale,omitempty"`
+	Heights     []byte  `json:"heights,omitempty"`
+	NotModified bool    `json:"not_modified,omitempty"`
+}
+
+// buildChunks groups the heightmap into chunks of chunkSize×chunkSize samples
+// and encodes each of them.
+func (s *TerrainService) buildChunks(heights map[terrainKey]float64) map[ChunkKey]*ChunkMessage {
+	grouped := make(map[ChunkKey][]terrainKey)
+	for key := range heights {
+		chunk := ChunkKey{X: floorDiv(key.x, s.chunkSize), Z: floorDiv(key.z, s.chunkSize)}
+		grouped[chunk] = append(grouped[chunk], key)
+	}
+
+	chunks := make(map[ChunkKey]*ChunkMessage, len(grouped))
+	for chunk, keys := range grouped {
+		chunks[chunk] = s.encodeChunk(chunk, keys, heights)
+	}
+	return chunks
+}
+
+// encodeChunk quantizes the samples of one chunk to 16 bits between their minimum and maximum.
+func (s *TerrainService) encodeChunk(chunk ChunkKey, keys []terrainKey, heights map[terrainKey]float64) *ChunkMessage {
+	minHeight, maxHeight := math.Inf(1), math.Inf(-1)
+	for _, key := range keys {
+		minHeight = math.Min(minHeight, heights[key])
+		maxHeight = math.Max(maxHeight, heights[key])
+	}
+	scale := (maxHeight - minHeight) / (chunkNoSample - 1)
+
+	size := s.chunkSize
+	quantized := make([]uint16, size*size)
+	for i := range quantized {
+		quantized[i] = chunkNoSample
+	}
+	for _, key := range keys {
+		q := 0.0
+		if scale > 0 {
+			q = math.Round((heights[key] - minHeight) / scale)
+		}
+		quantized[(key.z-chunk.Z*size)*size+(key.x-chunk.X*size)] = uint16(q)
+	}
+
+	data := make([]byte, 0, 2*len(quantized))
+	for _, q := range quantized {
+		data = binary.LittleEndian.AppendUint16(data, q)
+	}
+
+	// The version is derived from the encoded contents, so it survives restarts
+	// and reloads as long as the terrain does not change
+	crc := crc32.NewIEEE()
+	crc.Write(data)
+	binary.Write(crc, binary.LittleEndian, [2]float64{minHeight, scale})
+	version := crc.Sum32()
+	if version == 0 {
+		version = 1 // 0 is reserved for chunks without terrain
+	}
+
+	return &ChunkMessage{
+		Type:      "chunk",
+		X:         chunk.X,
+		Z:         chunk.Z,
+		Version:   version,
+		Size:      size,
+		CellSize:  s.cellSize,
+		MinHeight: minHeight,
+		Scale:     scale,
+		Heights:   data,
+	}
+}
+
+// ChunkAt returns the key of the chunk containing world coordinates (x, z).
+func (s *TerrainService) ChunkAt(x, z float64) ChunkKey {
+	gx, gz := int(math.Floor(x/s.cellSize)), int(math.Floor(z/s.cellSize))
+	return ChunkKey{X: floorDiv(gx, s.chunkSize), Z: floorDiv(gz, s.chunkSize)}
+}
+
+// Chunk returns the chunk with the given key. Chunks without terrain have version 0 and no heights.
+func (s *TerrainService) Chunk(key ChunkKey) *ChunkMessage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if chunk, ok := s.chunks[key]; ok {
+		return chunk
+	}
+	return &ChunkMessage{Type: "chunk", X: key.X, Z: key.Z}
+}
+
+// ChunksNear returns the chunks with terrain within the streaming radius of world coordinates (x, z).
+func (s *TerrainService) ChunksNear(x, z float64) []*ChunkMessage {
+	center := s.ChunkAt(x, z)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var chunks []*ChunkMessage
+	for dz := -s.chunkRadius; dz <= s.chunkRadius; dz++ {
+		for dx := -s.chunkRadius; dx <= s.chunkRadius; dx++ {
+			if chunk, ok := s.chunks[ChunkKey{X: center.X + dx, Z: center.Z + dz}]; ok {
+				chunks = append(chunks, chunk)
+			}
+		}
+	}
+	return chunks
+}
+
+// PushChunks sends a player the given chunks unless it already has their current version.
+func (s *WebSocketService) PushChunks(playerID string, chunks []*ChunkMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client, ok := s.clients[playerID]
+	if !ok {
+		return
+	}
+	for _, chunk := range chunks {
+		if client.chunks[ChunkKey{X: chunk.X, Z: chunk.Z}] != chunk.Version {
+			s.sendChunkLocked(client, chunk)
+		}
+	}
+}
+
+// SendChunk answers a client's request for a chunk it has cached at cachedVersion,
+// sending the chunk only if it changed since.
+func (s *WebSocketService) SendChunk(client *Client, chunk *ChunkMessage, cachedVersion uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if chunk.Version != 0 && chunk.Version == cachedVersion {
+		client.chunks[ChunkKey{X: chunk.X, Z: chunk.Z}] = chunk.Version
+		s.sendLocked(client, ChunkMessage{Type: "chunk", X: chunk.X, Z: chunk.Z, Version: chunk.Version, NotModified: true})
+		return
+	}
+	s.sendChunkLocked(client, chunk)
+}
+
+// sendChunkLocked sends a chunk and remembers its version. s.mu must be held.
+func (s *WebSocketService) sendChunkLocked(client *Client, chunk *ChunkMessage) {
+	client.chunks[ChunkKey{X: chunk.X, Z: chunk.Z}] = chunk.Version
+	s.sendLocked(client, chunk)
+}
+
+// floorDiv divides rounding towards negative infinity.
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
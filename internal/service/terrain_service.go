@@ -0,0 +1,145 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"anarchy-core/internal/domain"
+	"anarchy-core/internal/util"
+)
+
+// terrainKey addresses a heightmap sample by its grid coordinates.
+type terrainKey struct {
+	x, z int
+}
+
+// TerrainService keeps the world heightmap in memory and samples it at arbitrary points.
+// A domain.World point (X, Y) is the height sample at world coordinates
+// (X*cellSize, Y*cellSize) on the X/Z plane. For streaming to clients the heightmap
+// is split into chunks of chunkSize×chunkSize samples.
+type TerrainService struct {
+	worldRepo   domain.WorldRepository
+	cellSize    float64
+	chunkSize   int
+	chunkRadius int         // Радиус в чанках, которые отправляются вокруг игрока
+	spawnPoint  *[2]float64 // Заданная точка появления игроков по X/Z, nil — центр карты высот
+	logger      *util.Logger
+
+	mu      sync.RWMutex
+	heights map[terrainKey]float64
+	chunks  map[ChunkKey]*ChunkMessage
+	center  [2]float64 // Центр карты высот по X/Z в единицах мира
+}
+
+// NewTerrainService creates a new TerrainService with samples spaced cellSize world units apart.
+// Players are streamed the chunks up to chunkRadius chunks away from their own. They
+// appear at spawnPoint, or at the centre of the heightmap if spawnPoint is nil.
+func NewTerrainService(worldRepo domain.WorldRepository, cellSize float64, chunkSize, chunkRadius int, spawnPoint *[2]float64, logger *util.Logger) *TerrainService {
+	return &TerrainService{
+		worldRepo:   worldRepo,
+		cellSize:    cellSize,
+		chunkSize:   chunkSize,
+		chunkRadius: chunkRadius,
+		spawnPoint:  spawnPoint,
+		logger:      logger,
+		heights:     make(map[terrainKey]float64),
+		chunks:      make(map[ChunkKey]*ChunkMessage),
+	}
+}
+
+// Load (re)reads the heightmap from the repository.
+func (s *TerrainService) Load() error {
+	points, err := s.worldRepo.GetAllWorldPoints()
+	if err != nil {
+		return fmt.Errorf("failed to load terrain: %w", err)
+	}
+	heights := make(map[terrainKey]float64, len(points))
+	minX, minZ, maxX, maxZ := math.MaxInt, math.MaxInt, math.MinInt, math.MinInt
+	for _, point := range points {
+		heights[terrainKey{x: point.X, z: point.Y}] = point.Value
+		minX, maxX = min(minX, point.X), max(maxX, point.X)
+		minZ, maxZ = min(minZ, point.Y), max(maxZ, point.Y)
+	}
+	chunks := s.buildChunks(heights)
+	var center [2]float64
+	if len(points) > 0 {
+		center = [2]float64{
+			math.Round(float64(minX+maxX)/2) * s.cellSize,
+			math.Round(float64(minZ+maxZ)/2) * s.cellSize,
+		}
+	}
+
+	s.mu.Lock()
+	s.heights = heights
+	s.chunks = chunks
+	s.center = center
+	s.mu.Unlock()
+
+	s.logger.Info("Terrain loaded: %d height samples in %d chunks", len(points), len(chunks))
+	return nil
+}
+
+// HeightAt returns the terrain height at world coordinates (x, z), bilinearly
+// interpolated between the surrounding samples. It returns false if the point
+// lies outside the known heightmap.
+func (s *TerrainService) HeightAt(x, z float64) (float64, bool) {
+	gx, gz := x/s.cellSize, z/s.cellSize
+	x0, z0 := math.Floor(gx), math.Floor(gz)
+	tx, tz := gx-x0, gz-z0
+	ix, iz := int(x0), int(z0)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	height := 0.0
+	corners := [4]struct {
+		dx, dz int
+		weight float64
+	}{
+		{0, 0, (1 - tx) * (1 - tz)},
+		{1, 0, tx * (1 - tz)},
+		{0, 1, (1 - tx) * tz},
+		{1, 1, tx * tz},
+	}
+	for _, c := range corners {
+		// Samples that do not contribute may be missing, e.g. exactly on the heightmap edge
+		if c.weight == 0 {
+			continue
+		}
+		h, ok := s.heights[terrainKey{x: ix + c.dx, z: iz + c.dz}]
+		if !ok {
+			return 0, false
+		}
+		height += h * c.weight
+	}
+	return height, true
+}
+
+// IsAboveGround reports whether a position is on or above the terrain.
+// Positions outside the known heightmap are not constrained and count as above ground.
+func (s *TerrainService) IsAboveGround(x, y, z float64) bool {
+	height, ok := s.HeightAt(x, z)
+	return !ok || y >= height
+}
+
+// SpawnPoint returns where players appear in the world: the configured spawn point,
+// or else the centre of the heightmap, on the ground.
+func (s *TerrainService) SpawnPoint() Position {
+	s.mu.RLock()
+	x, z := s.center[0], s.center[1]
+	s.mu.RUnlock()
+	if s.spawnPoint != nil {
+		x, z = s.spawnPoint[0], s.spawnPoint[1]
+	}
+	y, _ := s.HeightAt(x, z)
+	return Position{X: x, Y: y, Z: z}
+}
+
+// ClampToGround returns y raised to the terrain height at (x, z) if it lies below it.
+func (s *TerrainService) ClampToGround(x, y, z float64) float64 {
+	if height, ok := s.HeightAt(x, z); ok && y < height {
+		return height
+	}
+	return y
+}
@@ -1,41 +1,101 @@
 package service
 
 import (
-	"encoding/json"
+	"strconv"
 	"sync"
+	"time"
 
 	"anarchy-core/internal/domain"
+	"anarchy-core/internal/protocol"
 	"anarchy-core/internal/util"
 
 	"github.com/gorilla/websocket"
 )
 
-// Client represents a connected WebSocket client.
+// Client represents a player session. A session outlives its WebSocket connection
+// for a grace period, during which the player can reconnect with ResumeToken.
 type Client struct {
-	UserID   string
-	Username string
-	Conn     *websocket.Conn
-	Send     chan []byte // Канал для отправки сообщений клиенту
+	UserID      string
+	Username    string
+	Roles       []string // Роли из токена, с которым открыта сессия
+	ResumeToken string
+	Conn        *websocket.Conn // Текущее соединение, меняется при возобновлении сессии
+	Codec       protocol.Codec  // Формат сообщений, согласованный при подключении
+	Send        chan []byte     // Канал для отправки сообщений клиенту
+
+	visible   map[string]bool     // Игроки в зоне интереса клиента
+	chunks    map[ChunkKey]uint32 // Версии чанков рельефа, уже отправленных клиенту
+	snapshots *snapshotHistory    // Отправленные снимки и последний подтвержденный
+	detached  bool                // Соединение потеряно, сессия ждет возобновления
+	detachGen uint64              // Увеличивается при каждом отключении и возобновлении
+	missed    []interface{}       // Сообщения, накопленные за время отключения
+	overflow  bool                // missed переполнен, при возобновлении нужен полный снимок
+	closed    bool                // Сессия завершена, сообщения больше не принимаются
+	closeCode int                 // Код закрытия для последнего соединения, 0 — обычное закрытие
+}
+
+// HasRole reports whether the session holds at least one of roles.
+func (c *Client) HasRole(roles ...string) bool {
+	return domain.HasAnyRole(c.Roles, roles...)
 }
 
+// CloseMessage returns the close frame to send once the client's send channel is closed.
+func (c *Client) CloseMessage() []byte {
+	if c.closeCode == 0 {
+		return []byte{}
+	}
+	return websocket.FormatCloseMessage(c.closeCode, protocol.CloseReason(c.closeCode))
+}
+
+// SessionPolicy decides what happens when an account that already has an active
+// session connects again.
+type SessionPolicy string
+
+const (
+	// SessionPolicyKick closes the old session with a session_replaced close frame.
+	SessionPolicyKick SessionPolicy = "kick"
+	// SessionPolicyReject refuses the new connection while the old one is alive.
+	SessionPolicyReject SessionPolicy = "reject"
+)
+
 // WebSocketService manages WebSocket connections and broadcasts.
+// Each account has at most one session. Player positions are tracked in a spatial
+// grid so that each client only receives updates about players inside its area of interest.
 type WebSocketService struct {
-	clients    map[*Client]bool
-	broadcast  chan []byte
-	register   chan *Client
-	unregister chan *Client
-	logger     *util.Logger
-	mu         sync.Mutex
+	clients      map[string]*Client              // Сессии по ID пользователя
+	players      map[string]PlayerLocationUpdate // Последнее состояние подключенных игроков
+	entities     map[int]EntityUpdate            // Последнее состояние NPC и мобов
+	grid         *SpatialGrid
+	entityGrid   *SpatialGrid // Позиции сущностей, ключ — ID сущности строкой
+	viewDistance float64
+	tick         uint64             // Последний тик, полученный от игрового цикла
+	sessions     map[string]*Client // Сессии по токену возобновления
+	gracePeriod  time.Duration
+	policy       SessionPolicy
+	broadcast    chan interface{}
+	unregister   chan *Client
+	logger       *util.Logger
+	mu           sync.Mutex
 }
 
 // NewWebSocketService creates a new WebSocketService.
-func NewWebSocketService(logger *util.Logger) *WebSocketService {
+// viewDistance is the radius of each client's area of interest on the X/Z plane;
+// gracePeriod is how long a session survives a dropped connection; policy decides
+// between a new login and an account's existing session.
+func NewWebSocketService(viewDistance float64, gracePeriod time.Duration, policy SessionPolicy, logger *util.Logger) *WebSocketService {
 	return &WebSocketService{
-		clients:    make(map[*Client]bool),
-		broadcast:  make(chan []byte),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		logger:     logger,
+		clients:      make(map[string]*Client),
+		players:      make(map[string]PlayerLocationUpdate),
+		entities:     make(map[int]EntityUpdate),
+		grid:         NewSpatialGrid(viewDistance),
+		entityGrid:   NewSpatialGrid(viewDistance),
+		viewDistance: viewDistance,
+		sessions:     make(map[string]*Client),
+		gracePeriod:  gracePeriod,
+		policy:       policy,
+		broadcast:    make(chan interface{}),
+		unregister:   make(chan *Client),
+		logger:       logger,
 	}
 }
 
@@ -43,40 +103,161 @@ func NewWebSocketService(logger *util.Logger) *WebSocketService {
 func (s *WebSocketService) Run() {
 	for {
 		select {
-		case client := <-s.register:
-			s.mu.Lock()
-			s.clients[client] = true
-			s.mu.Unlock()
-			s.logger.Info("Client registered: %s (ID: %s)", client.Username, client.UserID)
-			// Optionally send current game state to new client
-			// s.SendAllPlayerLocations(client)
 		case client := <-s.unregister:
 			s.mu.Lock()
-			if _, ok := s.clients[client]; ok {
-				delete(s.clients, client)
-				close(client.Send)
+			if !client.closed {
+				s.removeClientLocked(client, 0)
 				s.logger.Info("Client unregistered: %s (ID: %s)", client.Username, client.UserID)
 			}
 			s.mu.Unlock()
 		case message := <-s.broadcast:
 			s.mu.Lock()
-			for client := range s.clients {
-				select {
-				case client.Send <- message:
-				default:
-					close(client.Send)
-					delete(s.clients, client)
-					s.logger.Error("Failed to send message to client %s, unregistering.", client.Username)
+			// Encode once per wire format rather than once per client
+			encoded := make(map[protocol.Codec][]byte)
+			for _, client := range s.clients {
+				if client.detached {
+					s.sendLocked(client, message)
+					continue
 				}
+				data, ok := encoded[client.Codec]
+				if !ok {
+					var err error
+					if data, err = client.Codec.Marshal(message); err != nil {
+						s.logger.Error("Failed to encode broadcast message as %s: %v", client.Codec.Name(), err)
+						continue
+					}
+					encoded[client.Codec] = data
+				}
+				s.queueLocked(client, data)
 			}
 			s.mu.Unlock()
 		}
 	}
 }
 
-// RegisterClient registers a new WebSocket client.
-func (s *WebSocketService) RegisterClient(client *Client) {
-	s.register <- client
+// closeSessionLocked ends a client's session and closes its connection with
+// closeCode (0 for a normal close). s.mu must be held.
+func (s *WebSocketService) closeSessionLocked(client *Client, closeCode int) {
+	delete(s.sessions, client.ResumeToken)
+	if !client.detached {
+		client.closeCode = closeCode
+		close(client.Send)
+	}
+	client.closed = true
+	client.missed = nil
+}
+
+// removeClientLocked ends a client's session and removes the player from the
+// world view of everyone else. s.mu must be held.
+func (s *WebSocketService) removeClientLocked(client *Client, closeCode int) {
+	s.closeSessionLocked(client, closeCode)
+	if s.clients[client.UserID] != client {
+		return
+	}
+	delete(s.clients, client.UserID)
+	delete(s.players, client.UserID)
+	s.grid.Remove(client.UserID)
+
+	for _, other := range s.clients {
+		if other.visible[client.UserID] {
+			delete(other.visible, client.UserID)
+			s.sendLocked(other, PlayerLeftViewMessage{Type: "player_left_view", PlayerID: client.UserID})
+		}
+	}
+}
+
+// queueLocked queues an encoded message for a client without blocking.
+// Clients whose buffer is full are detached, as if their connection dropped. s.mu must be held.
+func (s *WebSocketService) queueLocked(client *Client, message []byte) {
+	if client.closed || client.detached {
+		return
+	}
+	select {
+	case client.Send <- message:
+	default:
+		if s.clients[client.UserID] == client {
+			s.logger.Error("Failed to send message to client %s, detaching.", client.Username)
+			s.detachLocked(client)
+		}
+	}
+}
+
+// sendLocked encodes v with the client's codec and queues it. Messages for detached
+// clients are kept until the session is resumed. s.mu must be held.
+func (s *WebSocketService) sendLocked(client *Client, v interface{}) {
+	if client.closed {
+		return
+	}
+	if client.detached {
+		s.bufferMissedLocked(client, v)
+		return
+	}
+	message, err := client.Codec.Marshal(v)
+	if err != nil {
+		s.logger.Error("Failed to encode message for client %s: %v", client.Username, err)
+		return
+	}
+	s.queueLocked(client, message)
+}
+
+// PlayerPositions returns the positions of all players currently in the world.
+func (s *WebSocketService) PlayerPositions() map[string]Position {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	positions := make(map[string]Position, len(s.players))
+	for id, player := range s.players {
+		positions[id] = Position{X: player.X, Y: player.Y, Z: player.Z}
+	}
+	return positions
+}
+
+// PlayerName returns the username of a connected player, or "" if it is not connected.
+func (s *WebSocketService) PlayerName(playerID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if client, ok := s.clients[playerID]; ok {
+		return client.Username
+	}
+	return ""
+}
+
+// SendNear encodes v and queues it for every client whose player is within
+// the view distance of (x, z).
+func (s *WebSocketService) SendNear(x, z float64, v interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range s.grid.QueryRadius(x, z, s.viewDistance) {
+		if client, ok := s.clients[id]; ok {
+			s.sendLocked(client, v)
+		}
+	}
+}
+
+// RegisterClient registers a new session for the client's account. If the account
+// already has a live session, the session policy either closes the old one with a
+// session_replaced frame or refuses the new one with util.ErrSessionActive.
+// Detached sessions are always replaced, since their player is evidently back.
+func (s *WebSocketService) RegisterClient(client *Client) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if old, ok := s.clients[client.UserID]; ok {
+		if !old.detached && s.policy == SessionPolicyReject {
+			s.logger.Info("Rejected second session for %s (ID: %s)", client.Username, client.UserID)
+			return util.ErrSessionActive
+		}
+		// The player stays in the world, so other clients see no leave/enter flicker
+		s.closeSessionLocked(old, protocol.CloseSessionReplaced)
+		s.logger.Info("Session replaced: %s (ID: %s)", old.Username, old.UserID)
+	}
+
+	s.clients[client.UserID] = client
+	s.sessions[client.ResumeToken] = client
+	s.logger.Info("Client registered: %s (ID: %s)", client.Username, client.UserID)
+	return nil
 }
 
 // UnregisterClient unregisters a WebSocket client.
@@ -84,8 +265,44 @@ func (s *WebSocketService) UnregisterClient(client *Client) {
 	s.unregister <- client
 }
 
+// SendToClient encodes v and queues it for a single client.
+func (s *WebSocketService) SendToClient(client *Client, v interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sendLocked(client, v)
+}
+
+// SendToPlayer encodes v and queues it for the session of a player.
+// It returns false if the player is not connected.
+func (s *WebSocketService) SendToPlayer(playerID string, v interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client, ok := s.clients[playerID]
+	if !ok {
+		return false
+	}
+	s.sendLocked(client, v)
+	return true
+}
+
+// KickPlayer ends the session of a player, closing its connection with closeCode.
+// It returns false if the player is not connected.
+func (s *WebSocketService) KickPlayer(playerID string, closeCode int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client, ok := s.clients[playerID]
+	if !ok {
+		return false
+	}
+	s.removeClientLocked(client, closeCode)
+	s.logger.Info("Client kicked: %s (ID: %s)", client.Username, client.UserID)
+	return true
+}
+
 // BroadcastMessage sends a message to all connected clients.
-func (s *WebSocketService) BroadcastMessage(message []byte) {
+func (s *WebSocketService) BroadcastMessage(message interface{}) {
 	s.broadcast <- message
 }
 
@@ -97,67 +314,154 @@ type PlayerLocationUpdate struct {
 	X         float64 `json:"x"`
 	Y         float64 `json:"y"`
 	Z         float64 `json:"z"`
-	Timestamp string  `json:"timestamp"`
+	Timestamp int64   `json:"timestamp"` // Unix time in milliseconds
 }
 
-// NotifyPlayerLocationChange sends a player's location update to all clients.
-func (s *WebSocketService) NotifyPlayerLocationChange(playerID, username string, loc *domain.Location) {
-	update := PlayerLocationUpdate{
+// NewPlayerLocationUpdate builds a location update message for a player.
+func NewPlayerLocationUpdate(username string, loc *domain.Location) PlayerLocationUpdate {
+	return PlayerLocationUpdate{
 		Type:      "player_location_update",
-		PlayerID:  playerID,
+		PlayerID:  loc.PlayerID,
 		Username:  username,
 		X:         loc.X,
 		Y:         loc.Y,
 		Z:         loc.Z,
-		Timestamp: loc.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"), // ISO 8601 format
+		Timestamp: loc.UpdatedAt.UnixMilli(),
 	}
+}
 
-	message, err := json.Marshal(update)
-	if err != nil {
-		s.logger.Error("Failed to marshal player location update: %v", err)
-		return
-	}
-	s.BroadcastMessage(message)
+// PlayerLeftViewMessage notifies a client that a player left its area of interest.
+type PlayerLeftViewMessage struct {
+	Type     string `json:"type"`
+	PlayerID string `json:"player_id"`
 }
 
-// InitialStateMessage represents the initial state of the game.
-type InitialStateMessage struct {
-	Type      string                 `json:"type"`
-	Locations []PlayerLocationUpdate `json:"locations"`
+// refreshInterestLocked recomputes the set of players visible to a client and
+// emits player_entered_view / player_left_view events for the difference. s.mu must be held.
+func (s *WebSocketService) refreshInterestLocked(client *Client) {
+	inView := make(map[string]bool)
+	if x, z, ok := s.grid.Position(client.UserID); ok {
+		for _, id := range s.grid.QueryRadius(x, z, s.viewDistance) {
+			if id != client.UserID {
+				inView[id] = true
+			}
+		}
+	}
+
+	for id := range inView {
+		if !client.visible[id] {
+			entered := s.players[id]
+			entered.Type = "player_entered_view"
+			s.sendLocked(client, entered)
+		}
+	}
+	for id := range client.visible {
+		if !inView[id] {
+			s.sendLocked(client, PlayerLeftViewMessage{Type: "player_left_view", PlayerID: id})
+		}
+	}
+	client.visible = inView
 }
 
-// SendAllPlayerLocations sends the current locations of all players to a specific client.
-func (s *WebSocketService) SendAllPlayerLocations(client *Client, locations []domain.Location) {
-	updates := make([]PlayerLocationUpdate, len(locations))
-	for i, loc := range locations {
-		// In a real app, you might need to fetch username here if not readily available
-		// For simplicity, assuming username can be derived or passed
-		updates[i] = PlayerLocationUpdate{
-			Type:      "player_location_update",
-			PlayerID:  loc.PlayerID,
-			Username:  "unknown", // Placeholder: you'll need to fetch username from user service
-			X:         loc.X,
-			Y:         loc.Y,
-			Z:         loc.Z,
-			Timestamp: loc.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+// BroadcastStateUpdate applies the state changes of a tick to the interest index and
+// sends each client the updates of the players and entities inside its area of interest.
+// removedEntities lists entities that left the world during the tick.
+func (s *WebSocketService) BroadcastStateUpdate(tick uint64, updates []PlayerLocationUpdate, entities []EntityUpdate, removedEntities []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tick = tick
+	for _, update := range updates {
+		// Ignore late updates of players that have already disconnected
+		if _, ok := s.clients[update.PlayerID]; !ok {
+			continue
 		}
+		s.players[update.PlayerID] = update
+		s.grid.Update(update.PlayerID, update.X, update.Z)
+	}
+	for _, entity := range entities {
+		s.entities[entity.EntityID] = entity
+		s.entityGrid.Update(strconv.Itoa(entity.EntityID), entity.X, entity.Z)
+	}
+	for _, id := range removedEntities {
+		delete(s.entities, id)
+		s.entityGrid.Remove(strconv.Itoa(id))
 	}
 
-	initialState := InitialStateMessage{
-		Type:      "initial_state",
-		Locations: updates,
+	for _, client := range s.clients {
+		s.refreshInterestLocked(client)
+		// Detached clients catch up with a delta against their last ack when they resume
+		if !client.detached {
+			s.sendSnapshotLocked(client)
+		}
 	}
+}
 
-	message, err := json.Marshal(initialState)
-	if err != nil {
-		s.logger.Error("Failed to marshal initial state message: %v", err)
-		return
+// sendSnapshotLocked sends the client a snapshot of the players and entities it can see,
+// delta-encoded against its last acknowledged snapshot. s.mu must be held.
+func (s *WebSocketService) sendSnapshotLocked(client *Client) {
+	state := snapshotState{
+		players:  make(map[string]PlayerLocationUpdate, len(client.visible)+1),
+		entities: make(map[int]EntityUpdate),
+	}
+	if self, ok := s.players[client.UserID]; ok {
+		state.players[client.UserID] = self
 	}
+	for id := range client.visible {
+		state.players[id] = s.players[id]
+	}
+	if x, z, ok := s.grid.Position(client.UserID); ok {
+		for _, key := range s.entityGrid.QueryRadius(x, z, s.viewDistance) {
+			id, _ := strconv.Atoi(key)
+			state.entities[id] = s.entities[id]
+		}
+	}
+	if msg, changed := client.snapshots.next(state, s.tick); changed {
+		s.sendLocked(client, msg)
+	}
+}
 
-	select {
-	case client.Send <- message:
-	default:
-		s.logger.Error("Failed to send initial state to client %s, client channel is full.", client.Username)
-		s.UnregisterClient(client) // Unregister if cannot send
+// AckSnapshot records that a client has received a snapshot, making it the
+// baseline for subsequent deltas.
+func (s *WebSocketService) AckSnapshot(client *Client, id uint32) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return client.snapshots.ack(id)
+}
+
+// ResyncSnapshot immediately sends the client a full snapshot, e.g. after it detected a gap.
+func (s *WebSocketService) ResyncSnapshot(client *Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	client.snapshots.reset()
+	s.sendSnapshotLocked(client)
+}
+
+// SendAllPlayerLocations places the client's player into the world using its stored
+// location and sends it a full snapshot of the connected players inside its area of interest.
+func (s *WebSocketService) SendAllPlayerLocations(client *Client, locations []domain.Location) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range locations {
+		if locations[i].PlayerID == client.UserID {
+			update := NewPlayerLocationUpdate(client.Username, &locations[i])
+			s.players[client.UserID] = update
+			s.grid.Update(client.UserID, update.X, update.Z)
+			break
+		}
+	}
+
+	visible := make(map[string]bool)
+	if x, z, ok := s.grid.Position(client.UserID); ok {
+		for _, id := range s.grid.QueryRadius(x, z, s.viewDistance) {
+			if id != client.UserID {
+				visible[id] = true
+			}
+		}
 	}
+	client.visible = visible
+
+	client.snapshots.reset()
+	s.sendSnapshotLocked(client)
 }
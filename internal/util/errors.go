@@ -8,7 +8,26 @@ var (
 	ErrUserAlreadyExists      = errors.New("user with this username already exists")
 	ErrInvalidCredentials     = errors.New("invalid username or password")
 	ErrInvalidToken           = errors.New("invalid or expired token")
+	ErrRefreshTokenReused     = errors.New("refresh token reused")
 	ErrUnauthorized           = errors.New("unauthorized access")
+	ErrInvalidRole            = errors.New("invalid role")
 	ErrPlayerLocationNotFound = errors.New("player location not found")
+	ErrPlayerStatsNotFound    = errors.New("player stats not found")
+	ErrObjectListNotFound     = errors.New("object definition not found")
+	ErrObjectNotFound         = errors.New("object not found")
+	ErrEntityNotFound         = errors.New("entity not found")
+	ErrEntityListNotFound     = errors.New("entity template not found")
+	ErrItemNotFound           = errors.New("item not found")
+	ErrItemListNotFound       = errors.New("item definition not found")
+	ErrInventoryEntryNotFound = errors.New("inventory entry not found")
+	ErrInventoryFull          = errors.New("inventory is full")
+	ErrInventorySlotTaken     = errors.New("inventory slot is already taken")
+	ErrInvalidInventoryMove   = errors.New("invalid inventory move")
+	ErrInvalidQuantity        = errors.New("invalid item quantity")
+	ErrInvalidDefinition      = errors.New("invalid content definition")
+	ErrDefinitionInUse        = errors.New("content definition is in use")
+	ErrContentChanged         = errors.New("content definition changed concurrently")
+	ErrWorldPointNotFound     = errors.New("world point not found")
+	ErrSessionActive          = errors.New("account already has an active session")
 	ErrInternalServer         = errors.New("internal server error")
 )
@@ -0,0 +1,138 @@
+package worldgen
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"anarchy-core/internal/domain"
+)
+
+// Map file format identifiers.
+const (
+	FileFormat  = "anarchy-world"
+	FileVersion = 1
+)
+
+// MapFile is the exchange format for heightmaps. Heights are stored row by row
+// (Y rows, X columns) for the rectangle starting at (OriginX, OriginY); null marks
+// grid points without a sample. Files whose name ends in .gz are gzip-compressed.
+type MapFile struct {
+	Format    string     `json:"format"`
+	Version   int        `json:"version"`
+	Generator *Params    `json:"generator,omitempty"` // Параметры генерации, если карта сгенерирована
+	OriginX   int        `json:"origin_x"`
+	OriginY   int        `json:"origin_y"`
+	Width     int        `json:"width"`
+	Height    int        `json:"height"`
+	Heights   []*float64 `json:"heights"`
+}
+
+// NewMapFile packs points into a MapFile covering their bounding rectangle.
+func NewMapFile(points []domain.World, generator *Params) *MapFile {
+	f := &MapFile{Format: FileFormat, Version: FileVersion, Generator: generator}
+	if len(points) == 0 {
+		f.Heights = []*float64{}
+		return f
+	}
+
+	minX, minY, maxX, maxY := points[0].X, points[0].Y, points[0].X, points[0].Y
+	for _, p := range points {
+		minX, maxX = min(minX, p.X), max(maxX, p.X)
+		minY, maxY = min(minY, p.Y), max(maxY, p.Y)
+	}
+	f.OriginX, f.OriginY = minX, minY
+	f.Width, f.Height = maxX-minX+1, maxY-minY+1
+
+	f.Heights = make([]*float64, f.Width*f.Height)
+	for _, p := range points {
+		value := p.Value
+		f.Heights[(p.Y-minY)*f.Width+(p.X-minX)] = &value
+	}
+	return f
+}
+
+// Points unpacks the map into domain.World points, skipping grid points without a sample.
+func (f *MapFile) Points() []domain.World {
+	points := make([]domain.World, 0, len(f.Heights))
+	for i, h := range f.Heights {
+		if h != nil {
+			points = append(points, domain.World{X: f.OriginX + i%f.Width, Y: f.OriginY + i/f.Width, Value: *h})
+		}
+	}
+	return points
+}
+
+// validate checks the header and the size of a decoded map.
+func (f *MapFile) validate() error {
+	if f.Format != FileFormat {
+		return fmt.Errorf("not a world map file: format %q", f.Format)
+	}
+	if f.Version != FileVersion {
+		return fmt.Errorf("unsupported world map version %d, expected %d", f.Version, FileVersion)
+	}
+	if f.Width < 0 || f.Height < 0 || len(f.Heights) != f.Width*f.Height {
+		return fmt.Errorf("world map has %d heights, expected %dx%d", len(f.Heights), f.Width, f.Height)
+	}
+	return nil
+}
+
+// WriteMapFile writes f to path, gzip-compressed if path ends in .gz.
+func WriteMapFile(path string, f *MapFile) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create map file: %w", err)
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("failed to close map file: %w", cerr)
+		}
+	}()
+
+	var w io.Writer = file
+	if strings.HasSuffix(path, ".gz") {
+		gz := gzip.NewWriter(file)
+		defer func() {
+			if cerr := gz.Close(); err == nil && cerr != nil {
+				err = fmt.Errorf("failed to compress map file: %w", cerr)
+			}
+		}()
+		w = gz
+	}
+
+	if err := json.NewEncoder(w).Encode(f); err != nil {
+		return fmt.Errorf("failed to write map file: %w", err)
+	}
+	return nil
+}
+
+// ReadMapFile reads and validates a map written by WriteMapFile.
+func ReadMapFile(path string) (*MapFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open map file: %w", err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress map file: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var f MapFile
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("failed to parse map file: %w", err)
+	}
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
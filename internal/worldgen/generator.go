@@ -0,0 +1,72 @@
+package worldgen
+
+import (
+	"fmt"
+	"math"
+
+	"anarchy-core/internal/domain"
+)
+
+// Params configures the terrain generator. Generation is deterministic:
+// the same parameters always produce the same heightmap.
+type Params struct {
+	Seed        int64   `json:"seed"`
+	OriginX     int     `json:"origin_x"`    // Координата X первой точки сетки
+	OriginY     int     `json:"origin_y"`    // Координата Y первой точки сетки
+	Width       int     `json:"width"`       // Количество точек по X
+	Height      int     `json:"height"`      // Количество точек по Y
+	Octaves     int     `json:"octaves"`     // Количество слоев шума
+	Scale       float64 `json:"scale"`       // Размер самых крупных форм рельефа в точках сетки
+	Persistence float64 `json:"persistence"` // Множитель амплитуды каждого следующего слоя
+	Lacunarity  float64 `json:"lacunarity"`  // Множитель частоты каждого следующего слоя
+	Amplitude   float64 `json:"amplitude"`   // Максимальное отклонение высоты от нуля
+	SeaLevel    float64 `json:"sea_level"`   // Высоты ниже уровня моря выравниваются по нему
+}
+
+// DefaultParams returns the generator defaults for a width×height map.
+func DefaultParams(width, height int) Params {
+	return Params{
+		Width:       width,
+		Height:      height,
+		Octaves:     5,
+		Scale:       64,
+		Persistence: 0.5,
+		Lacunarity:  2,
+		Amplitude:   40,
+	}
+}
+
+// Validate checks that the parameters describe a map that can be generated.
+func (p Params) Validate() error {
+	switch {
+	case p.Width <= 0 || p.Height <= 0:
+		return fmt.Errorf("map size must be positive, got %dx%d", p.Width, p.Height)
+	case p.Octaves < 1 || p.Octaves > 16:
+		return fmt.Errorf("octaves must be between 1 and 16, got %d", p.Octaves)
+	case p.Scale <= 0:
+		return fmt.Errorf("scale must be positive, got %g", p.Scale)
+	case p.Persistence <= 0 || p.Lacunarity <= 0:
+		return fmt.Errorf("persistence and lacunarity must be positive")
+	}
+	return nil
+}
+
+// Generate creates the heightmap described by p, one domain.World point per grid cell.
+func Generate(p Params) ([]domain.World, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	noise := newPerlin(p.Seed)
+	points := make([]domain.World, 0, p.Width*p.Height)
+	for y := 0; y < p.Height; y++ {
+		for x := 0; x < p.Width; x++ {
+			gx, gy := p.OriginX+x, p.OriginY+y
+			height := p.Amplitude * noise.fbm(float64(gx)/p.Scale, float64(gy)/p.Scale, p.Octaves, p.Persistence, p.Lacunarity)
+			// Centimetre precision keeps exported maps compact
+			height = math.Round(math.Max(height, p.SeaLevel)*100) / 100
+			points = append(points, domain.World{X: gx, Y: gy, Value: height})
+		}
+	}
+	return points, nil
+}
@@ -0,0 +1,84 @@
+package worldgen
+
+import (
+	"math"
+	"math/rand"
+)
+
+// perlin is a seeded 2D gradient noise source. The same seed always yields the same noise.
+type perlin struct {
+	perm [512]uint8
+}
+
+// newPerlin creates a noise source whose permutation table is shuffled by seed.
+func newPerlin(seed int64) *perlin {
+	p := &perlin{}
+	rng := rand.New(rand.NewSource(seed))
+	for i, v := range rng.Perm(256) {
+		p.perm[i] = uint8(v)
+		p.perm[i+256] = uint8(v)
+	}
+	return p
+}
+
+// noise returns the gradient noise at (x, y), roughly in [-1, 1].
+func (p *perlin) noise(x, y float64) float64 {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := x-x0, y-y0
+	xi, yi := int(x0)&255, int(y0)&255
+
+	aa := p.perm[int(p.perm[xi])+yi]
+	ab := p.perm[int(p.perm[xi])+yi+1]
+	ba := p.perm[int(p.perm[xi+1])+yi]
+	bb := p.perm[int(p.perm[xi+1])+yi+1]
+
+	u, v := fade(fx), fade(fy)
+	return lerp(v,
+		lerp(u, grad(aa, fx, fy), grad(ba, fx-1, fy)),
+		lerp(u, grad(ab, fx, fy-1), grad(bb, fx-1, fy-1)),
+	)
+}
+
+// fbm sums octaves of noise with rising frequency and falling amplitude,
+// normalized back to roughly [-1, 1].
+func (p *perlin) fbm(x, y float64, octaves int, persistence, lacunarity float64) float64 {
+	sum, amplitude, frequency, norm := 0.0, 1.0, 1.0, 0.0
+	for i := 0; i < octaves; i++ {
+		sum += amplitude * p.noise(x*frequency, y*frequency)
+		norm += amplitude
+		amplitude *= persistence
+		frequency *= lacunarity
+	}
+	return sum / norm
+}
+
+// fade is the quintic smoothstep of improved Perlin noise.
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+func lerp(t, a, b float64) float64 {
+	return a + t*(b-a)
+}
+
+// grad returns the dot product of one of eight gradient directions with (x, y).
+func grad(hash uint8, x, y float64) float64 {
+	switch hash & 7 {
+	case 0:
+		return x + y
+	case 1:
+		return -x + y
+	case 2:
+		return x - y
+	case 3:
+		return -x - y
+	case 4:
+		return x
+	case 5:
+		return -x
+	case 6:
+		return y
+	default:
+		return -y
+	}
+}
@@ -0,0 +1,11 @@
+-- gen_random_uuid() является встроенной функцией начиная с PostgreSQL 13,
+-- для более старых версий её предоставляет pgcrypto.
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+-- Таблица пользователей
+CREATE TABLE users (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    username      VARCHAR(20) NOT NULL UNIQUE,
+    password_hash TEXT        NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,8 @@
+-- Последние известные координаты игроков
+CREATE TABLE player_locations (
+    player_id  UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    x          DOUBLE PRECISION NOT NULL,
+    y          DOUBLE PRECISION NOT NULL,
+    z          DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS world;
+DROP TABLE IF EXISTS player;
+DROP TABLE IF EXISTS inventory;
+DROP TABLE IF EXISTS entity;
+DROP TABLE IF EXISTS entity_list;
+DROP TABLE IF EXISTS item;
+DROP TABLE IF EXISTS item_list;
+DROP TABLE IF EXISTS object;
+DROP TABLE IF EXISTS object_list;
@@ -0,0 +1,83 @@
+-- Таблица ObjectList
+CREATE TABLE object_list (
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(255) NOT NULL DEFAULT '',
+    image       VARCHAR(255) NOT NULL DEFAULT '',
+    description TEXT         NOT NULL DEFAULT ''
+);
+
+-- Таблица Object
+CREATE TABLE object (
+    id             SERIAL PRIMARY KEY,
+    object_list_id INT REFERENCES object_list (id) ON DELETE CASCADE
+);
+
+-- Таблица ItemList
+CREATE TABLE item_list (
+    id           SERIAL PRIMARY KEY,
+    object_id    INT REFERENCES object (id) ON DELETE CASCADE,
+    rarity       INT     NOT NULL DEFAULT 0,
+    is_stackable BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+-- Таблица Item
+CREATE TABLE item (
+    id           SERIAL PRIMARY KEY,
+    object_id    INT REFERENCES object (id) ON DELETE CASCADE,
+    item_list_id INT REFERENCES item_list (id) ON DELETE CASCADE
+);
+
+-- Таблица EntityList
+CREATE TABLE entity_list (
+    id             SERIAL PRIMARY KEY,
+    object_list_id INT REFERENCES object_list (id) ON DELETE CASCADE,
+    damage         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    speed          DOUBLE PRECISION NOT NULL DEFAULT 0,
+    cooldown       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    damage_radius  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    is_angry       BOOLEAN          NOT NULL DEFAULT FALSE,
+    visual_radius  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    max_health     DOUBLE PRECISION NOT NULL DEFAULT 0,
+    model          VARCHAR(255)     NOT NULL DEFAULT '',
+    spawn          VARCHAR(255)     NOT NULL DEFAULT '',
+    is_open        BOOLEAN          NOT NULL DEFAULT FALSE,
+    is_spawning    BOOLEAN          NOT NULL DEFAULT FALSE,
+    is_pick_up     BOOLEAN          NOT NULL DEFAULT FALSE
+);
+
+-- Таблица Entity
+CREATE TABLE entity (
+    id             SERIAL PRIMARY KEY,
+    object_id      INT REFERENCES object (id) ON DELETE CASCADE,
+    entity_list_id INT REFERENCES entity_list (id) ON DELETE CASCADE,
+    health         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    x              DOUBLE PRECISION NOT NULL DEFAULT 0,
+    y              DOUBLE PRECISION NOT NULL DEFAULT 0,
+    z              DOUBLE PRECISION NOT NULL DEFAULT 0
+);
+
+-- Таблица Inventory
+CREATE TABLE inventory (
+    id        VARCHAR(255) PRIMARY KEY,
+    entity_id VARCHAR(255),
+    item_id   INT REFERENCES item (id) ON DELETE CASCADE
+);
+
+-- Таблица Player
+CREATE TABLE player (
+    id      SERIAL PRIMARY KEY,
+    user_id INT,
+    x       DOUBLE PRECISION,
+    y       DOUBLE PRECISION,
+    z       DOUBLE PRECISION,
+    name    VARCHAR(255)
+);
+
+-- Таблица World
+CREATE TABLE world (
+    id    VARCHAR(255) PRIMARY KEY,
+    x     INT,
+    y     INT,
+    value DOUBLE PRECISION,
+    UNIQUE (x, y)
+);
@@ -0,0 +1,8 @@
+-- Боевые характеристики игроков
+CREATE TABLE player_stats (
+    player_id  UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    health     DOUBLE PRECISION NOT NULL,
+    max_health DOUBLE PRECISION NOT NULL,
+    deaths     INT              NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
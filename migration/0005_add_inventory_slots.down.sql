@@ -0,0 +1,5 @@
+ALTER TABLE inventory
+    DROP CONSTRAINT IF EXISTS inventory_entity_slot_key,
+    ALTER COLUMN entity_id DROP NOT NULL,
+    DROP COLUMN IF EXISTS quantity,
+    DROP COLUMN IF EXISTS slot;
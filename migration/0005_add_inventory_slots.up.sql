@@ -0,0 +1,15 @@
+-- Слоты и количество предметов в инвентаре
+ALTER TABLE inventory
+    ADD COLUMN slot     INT,
+    ADD COLUMN quantity INT NOT NULL DEFAULT 1 CHECK (quantity > 0);
+
+-- Существующие записи раскладываются по слотам в порядке их ID
+UPDATE inventory i
+SET slot = n.slot
+FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY entity_id ORDER BY id) - 1 AS slot FROM inventory) n
+WHERE i.id = n.id;
+
+ALTER TABLE inventory
+    ALTER COLUMN entity_id SET NOT NULL,
+    ALTER COLUMN slot SET NOT NULL,
+    ADD CONSTRAINT inventory_entity_slot_key UNIQUE (entity_id, slot) DEFERRABLE INITIALLY DEFERRED;
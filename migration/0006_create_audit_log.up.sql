@@ -0,0 +1,14 @@
+-- Журнал изменений игрового контента через админский API
+CREATE TABLE audit_log (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    UUID REFERENCES users (id) ON DELETE SET NULL,
+    username   VARCHAR(20) NOT NULL,
+    action     VARCHAR(16) NOT NULL,
+    table_name VARCHAR(64) NOT NULL,
+    record_id  INT         NOT NULL,
+    before     JSONB,
+    after      JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX audit_log_created_at_idx ON audit_log (created_at DESC);
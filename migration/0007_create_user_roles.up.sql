@@ -0,0 +1,7 @@
+-- Роли пользователей, дающие доступ к привилегированным эндпоинтам и сообщениям
+CREATE TABLE user_roles (
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role       VARCHAR(20) NOT NULL CHECK (role IN ('admin', 'moderator')),
+    granted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, role)
+);
@@ -0,0 +1,15 @@
+-- Refresh-токены. Хранится только SHA-256 хеш токена. Токены, выданные друг за другом
+-- при обновлении, образуют семейство, которое отзывается целиком при повторном
+-- использовании уже обменянного токена.
+CREATE TABLE refresh_tokens (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    family_id  UUID        NOT NULL,
+    token_hash CHAR(64)    NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    rotated_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX refresh_tokens_family_id_idx ON refresh_tokens (family_id);
@@ -0,0 +1,12 @@
+// Package migration embeds the ordered PostgreSQL schema migrations.
+//
+// Files are named <version>_<name>.up.sql / <version>_<name>.down.sql and are
+// applied in ascending version order by database.Migrator.
+package migration
+
+import "embed"
+
+// FS contains all migration files shipped with the binary.
+//
+//go:embed *.sql
+var FS embed.FS